@@ -7,6 +7,7 @@ import (
 	"github.com/knadh/koanf/v2"
 	log "github.com/sirupsen/logrus"
 	"outdoorsy-api/utils"
+	"time"
 )
 
 type configurations struct {
@@ -17,8 +18,26 @@ type configurations struct {
 	DBPassword string `json:"db_password" koanf:"DB_PASSWORD" valid:"required"`
 	DBPort     string `json:"db_port" koanf:"DB_PORT" valid:"required"`
 	DBName     string `json:"db_name" koanf:"DB_NAME" valid:"required"`
+
+	ExchangeRatesPath string `json:"exchange_rates_path" koanf:"EXCHANGE_RATES_PATH" valid:"optional"`
+	AdminToken        string `json:"-" koanf:"ADMIN_TOKEN" valid:"optional"`
+
+	RoadFactor float64 `json:"road_factor" koanf:"ROAD_FACTOR" valid:"optional"`
+	FuelPrice  int     `json:"fuel_price" koanf:"FUEL_PRICE" valid:"optional"`
+
+	ViewsFlushSeconds int `json:"views_flush_seconds" koanf:"VIEWS_FLUSH_SECONDS" valid:"optional"`
+	ViewsBatchSize    int `json:"views_batch_size" koanf:"VIEWS_BATCH_SIZE" valid:"optional"`
+
+	WebhooksPollSeconds int `json:"webhooks_poll_seconds" koanf:"WEBHOOKS_POLL_SECONDS" valid:"optional"`
+
+	GRPCPort string `json:"grpc_port" koanf:"GRPC_PORT" valid:"optional,port"`
+
+	UnversionedSunset string `json:"unversioned_sunset" koanf:"UNVERSIONED_SUNSET" valid:"optional"`
 }
 
+// defaultUnversionedSunset gives the clients of the unversioned routes six months to move to /v1.
+const defaultUnversionedSunset = "2027-04-19"
+
 func Init() (configurations, error) {
 	var (
 		parser = koanf.New(".")
@@ -35,7 +54,20 @@ func Init() (configurations, error) {
 		return configurations{}, err
 	}
 
+	if config.ExchangeRatesPath == "" {
+		config.ExchangeRatesPath = "exchange_rates.json"
+	}
+	if config.GRPCPort == "" {
+		config.GRPCPort = "9090"
+	}
+	if config.UnversionedSunset == "" {
+		config.UnversionedSunset = defaultUnversionedSunset
+	}
+
 	_, err = validator.ValidateStruct(config)
+	if err == nil {
+		_, err = time.Parse(utils.DateFormat, config.UnversionedSunset)
+	}
 	if err != nil {
 		utils.GetLogger().WithFields(log.Fields{"error": err.Error()}).Error("Error on config validation")
 		return configurations{}, err
@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"github.com/jmoiron/sqlx"
 	log "github.com/sirupsen/logrus"
 	"outdoorsy-api/utils"
 	"time"
@@ -33,8 +34,74 @@ func GetSingleRecordNamedQuery(destination interface{}, query string, args inter
 	return namedStatement.Unsafe().GetContext(ctx, destination, args)
 }
 
-func GetMultipleRecords(destination interface{}, query string) error {
+// ExecNamedQuery runs a statement which doesn't return rows and returns the number of rows it affected.
+func ExecNamedQuery(query string, args interface{}) (int64, error) {
 	var ctx, cancel = context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
-	return instance.DB.Unsafe().SelectContext(ctx, destination, query)
+	result, err := instance.DB.NamedExecContext(ctx, query, args)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// Exec runs a statement with positional arguments which doesn't return rows.
+func Exec(query string, args ...interface{}) error {
+	var ctx, cancel = context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	_, err := instance.DB.ExecContext(ctx, query, args...)
+	return err
+}
+
+func GetMultipleRecords(destination interface{}, query string, args ...interface{}) error {
+	var ctx, cancel = context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	return instance.DB.Unsafe().SelectContext(ctx, destination, query, args...)
+}
+
+// ForEachRecord runs the query and scans the rows one by one into the destination, calling the handler after every
+// row. Rows are never held in memory together, so it suits large exports. The first error of the handler stops the
+// iteration and is returned, and cancelling the context stops the query.
+func ForEachRecord(ctx context.Context, destination interface{}, handler func() error, query string, args ...interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	rows, err := instance.DB.Unsafe().QueryxContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err = rows.StructScan(destination); err != nil {
+			return err
+		}
+		if err = handler(); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// TransactionTimeout is the longest a transaction of WithTransaction can run before it is cancelled.
+const TransactionTimeout = 10 * time.Second
+
+// WithTransaction runs the handler inside a single database transaction. The transaction is committed if the
+// handler succeeds and rolled back if it returns an error.
+func WithTransaction(handler func(transaction *sqlx.Tx) error) error {
+	var ctx, cancel = context.WithTimeout(context.Background(), TransactionTimeout)
+	defer cancel()
+
+	transaction, err := instance.DB.Unsafe().BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err = handler(transaction); err != nil {
+		if rollbackErr := transaction.Rollback(); rollbackErr != nil {
+			utils.GetLogger().WithFields(log.Fields{"error": rollbackErr.Error()}).Error("Error on transaction rollback")
+		}
+		return err
+	}
+	return transaction.Commit()
 }
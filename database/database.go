@@ -3,7 +3,7 @@ package database
 import (
 	"fmt"
 	"github.com/jmoiron/sqlx"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 	log "github.com/sirupsen/logrus"
 	"outdoorsy-api/utils"
 	"sync"
@@ -40,9 +40,12 @@ func Init(hosts string, user string, password string, port string, database stri
 	}
 }
 
+func connectionString() string {
+	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable", instance.host, instance.port, instance.user, instance.password, instance.database)
+}
+
 func connect() {
-	psqlInfo := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable", instance.host, instance.port, instance.user, instance.password, instance.database)
-	sqlxConnection, err := sqlx.Open("postgres", psqlInfo)
+	sqlxConnection, err := sqlx.Open("postgres", connectionString())
 	if err == nil {
 		instance.DB = sqlxConnection
 		instance.DB.SetMaxOpenConns(10)
@@ -64,3 +67,20 @@ func connectionFailureListener() {
 		}
 	}
 }
+
+// Listen listens to the notifications of the channel on a connection of its own, which is reconnected and listens
+// again by itself. It blocks until the connection is established. A nil notification is sent after every
+// reconnection, as notifications may have been missed meanwhile.
+func Listen(channel string) (*pq.Listener, error) {
+	listener := pq.NewListener(connectionString(), time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			utils.GetLogger().WithFields(log.Fields{"error": err.Error(), "channel": channel}).Error("Database listener connection failed")
+		}
+	})
+
+	if err := listener.Listen(channel); err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
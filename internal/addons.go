@@ -0,0 +1,178 @@
+package internal
+
+import (
+	"database/sql"
+	"fmt"
+	"outdoorsy-api/database"
+	"outdoorsy-api/utils"
+	"strconv"
+	"strings"
+)
+
+const (
+	PricingPerDay  = "per_day"
+	PricingPerTrip = "per_trip"
+	PricingPerUnit = "per_unit"
+)
+
+// CreateAddon adds an add-on to the owner's rental. Add-ons are available unless created otherwise. If the input
+// is not valid - failedValidation will be set to true and descriptive validation error will be returned.
+func CreateAddon(ownerId int, rentalId int, input AddonInput) (addon Addon, failedValidation bool, err error) {
+	var addonId int
+
+	if err = validateAddonInput(input); err != nil {
+		failedValidation = true
+		return
+	}
+
+	err = database.GetSingleRecordNamedQuery(&addonId, insertAddonQuery, addonArguments(ownerId, rentalId, 0, input))
+	if err != nil {
+		return
+	}
+
+	addon, err = getAddon(addonId)
+	return
+}
+
+// UpdateAddon replaces the add-on of the owner's rental. Omitting available makes the add-on available.
+func UpdateAddon(ownerId int, rentalId int, addonId int, input AddonInput) (addon Addon, failedValidation bool, err error) {
+	var updatedId int
+
+	if err = validateAddonInput(input); err != nil {
+		failedValidation = true
+		return
+	}
+
+	err = database.GetSingleRecordNamedQuery(&updatedId, updateAddonQuery, addonArguments(ownerId, rentalId, addonId, input))
+	if err != nil {
+		return
+	}
+
+	addon, err = getAddon(addonId)
+	return
+}
+
+// DeleteAddon removes the add-on of the owner's rental. Bookings keep the name and the amount of their add-ons.
+func DeleteAddon(ownerId int, rentalId int, addonId int) error {
+	deleted, err := database.ExecNamedQuery(deleteAddonQuery, map[string]interface{}{
+		"owner_id":  ownerId,
+		"rental_id": rentalId,
+		"addon_id":  addonId,
+	})
+	if err == nil && deleted == 0 {
+		err = sql.ErrNoRows
+	}
+	return err
+}
+
+func getAddon(id int) (addon Addon, err error) {
+	err = database.GetSingleRecordNamedQuery(&addon, selectAddonQuery, map[string]interface{}{"id": id})
+	return
+}
+
+// attachAddons loads the add-ons of the rental. Rentals without add-ons are left with an empty list.
+func attachAddons(rental *Rental) error {
+	rental.Addons = make([]Addon, 0)
+	return database.GetMultipleRecords(&rental.Addons, selectRentalAddonsQuery, rental.IdRental)
+}
+
+// parseAddonSelections parses the add-ons parameter of a quote - comma separated add-on ids, each optionally
+// followed by a colon and the quantity. The syntax is already validated by utils.ValidateQuoteParameters.
+func parseAddonSelections(param string) (selections []AddonSelection) {
+	if param == "" {
+		return
+	}
+
+	for _, value := range strings.Split(param, ",") {
+		idAsString, quantityAsString, hasQuantity := strings.Cut(value, ":")
+		selection := AddonSelection{Quantity: 1}
+		selection.AddonId, _ = strconv.Atoi(idAsString)
+		if hasQuantity {
+			selection.Quantity, _ = strconv.Atoi(quantityAsString)
+		}
+		selections = append(selections, selection)
+	}
+	return
+}
+
+// addAddons adds a line for every selected add-on of the rental to the quote. Per-day add-ons are charged for
+// every night and per-trip add-ons once, both can be selected only once. Per-unit add-ons are charged by the
+// quantity, up to the add-on maximum. An add-on which is not offered, not available or selected in an invalid
+// quantity returns the localized reason.
+func (quote *Quote) addAddons(rental Rental, selections []AddonSelection, rate ExchangeRate) error {
+	var (
+		addons   = make(map[int]Addon, len(rental.Addons))
+		selected = make(map[int]bool, len(selections))
+	)
+
+	for _, addon := range rental.Addons {
+		addons[addon.Id] = addon
+	}
+
+	for _, selection := range selections {
+		addon, found := addons[selection.AddonId]
+		switch {
+		case !found:
+			return utils.NewLocalizedError("addon_unknown", selection.AddonId)
+		case selected[addon.Id]:
+			return utils.NewLocalizedError("addon_duplicate", addon.Name)
+		case !addon.Available:
+			return utils.NewLocalizedError("addon_unavailable", addon.Name)
+		case addon.PricingMode != PricingPerUnit && selection.Quantity != 1:
+			return utils.NewLocalizedError("addon_quantity_invalid", addon.Name, 1)
+		case addon.PricingMode == PricingPerUnit && (selection.Quantity < 1 || selection.Quantity > *addon.MaxQuantity):
+			return utils.NewLocalizedError("addon_quantity_invalid", addon.Name, *addon.MaxQuantity)
+		}
+		selected[addon.Id] = true
+
+		quantity := selection.Quantity
+		if addon.PricingMode == PricingPerDay {
+			quantity = quote.Nights
+		}
+
+		unitAmount := rate.Convert(addon.Price)
+		quote.addLine(QuoteLine{
+			Type:        "addon",
+			Description: fmt.Sprintf("%s (%s)", addon.Name, strings.ReplaceAll(addon.PricingMode, "_", " ")),
+			Quantity:    quantity,
+			UnitAmount:  unitAmount,
+			Amount:      quantity * unitAmount,
+			addonId:     addon.Id,
+			addonName:   addon.Name,
+		})
+	}
+	return nil
+}
+
+func addonArguments(ownerId int, rentalId int, addonId int, input AddonInput) map[string]interface{} {
+	available := input.Available == nil || *input.Available
+	return map[string]interface{}{
+		"owner_id":     ownerId,
+		"rental_id":    rentalId,
+		"addon_id":     addonId,
+		"name":         strings.TrimSpace(input.Name),
+		"description":  strings.TrimSpace(input.Description),
+		"pricing_mode": input.PricingMode,
+		"price":        input.Price,
+		"max_quantity": input.MaxQuantity,
+		"available":    available,
+	}
+}
+
+func validateAddonInput(input AddonInput) error {
+	switch {
+	case strings.TrimSpace(input.Name) == "":
+		return utils.NewLocalizedError("field_required", "name")
+	case input.PricingMode != PricingPerDay && input.PricingMode != PricingPerTrip && input.PricingMode != PricingPerUnit:
+		return utils.NewLocalizedError("field_invalid", "pricing_mode")
+	case input.Price <= 0:
+		return utils.NewLocalizedError("field_invalid", "price")
+	case input.PricingMode == PricingPerUnit && input.MaxQuantity == nil:
+		return utils.NewLocalizedError("field_required", "max_quantity")
+	case input.PricingMode == PricingPerUnit && *input.MaxQuantity <= 0:
+		return utils.NewLocalizedError("field_invalid", "max_quantity")
+	case input.PricingMode != PricingPerUnit && input.MaxQuantity != nil:
+		return utils.NewLocalizedError("field_invalid", "max_quantity")
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package internal
+
+import (
+	"github.com/stretchr/testify/assert"
+	"outdoorsy-api/database"
+	"testing"
+	"time"
+)
+
+func TestAddAddonsShouldPriceEveryPricingMode(test *testing.T) {
+	var (
+		maxQuantity = 50
+		rental      = Rental{IdRental: 1, Price: Price{Day: 10000}, Addons: []Addon{
+			{Id: 1, Name: "Linens", PricingMode: PricingPerTrip, Price: 4500, Available: true},
+			{Id: 2, Name: "Bike rack", PricingMode: PricingPerDay, Price: 1000, Available: true},
+			{Id: 3, Name: "Generator hours", PricingMode: PricingPerUnit, Price: 300, MaxQuantity: &maxQuantity, Available: true},
+			{Id: 4, Name: "Pet fee", PricingMode: PricingPerTrip, Price: 7500, Available: false},
+		}}
+		dollar = newExchangeRate(BaseCurrency, 1)
+		from   = time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+	)
+
+	quote := newQuote(rental, from, from.AddDate(0, 0, 3), dollar)
+	if err := quote.addAddons(rental, parseAddonSelections("1,2,3:10"), dollar); err != nil {
+		test.Fatalf("Error on adding add-ons - %s", err.Error())
+	}
+	assert.Len(test, quote.Lines, 4, "Expected a line for every add-on")
+	assert.Equal(test, 4500, quote.Lines[1].Amount, "Expected a per-trip add-on to be charged once")
+	assert.Equal(test, 3, quote.Lines[2].Quantity, "Expected a per-day add-on to be charged for every night")
+	assert.Equal(test, 3000, quote.Lines[2].Amount, "Expected a per-day add-on to be charged for every night")
+	assert.Equal(test, 3000, quote.Lines[3].Amount, "Expected a per-unit add-on to be charged by the quantity")
+	assert.Equal(test, 30000+4500+3000+3000, quote.Total, "Expected the add-ons in the total")
+
+	testCases := map[string]string{
+		"5":     "add-on 5 is not offered by the rental",
+		"1,1":   "add-on Linens is selected more than once",
+		"4":     "add-on Pet fee is not available",
+		"1:2":   "add-on Linens can be selected in a quantity from 1 to 1",
+		"3:51":  "add-on Generator hours can be selected in a quantity from 1 to 50",
+		"2,3:0": "add-on Generator hours can be selected in a quantity from 1 to 50",
+	}
+	for selections, message := range testCases {
+		quote = newQuote(rental, from, from.AddDate(0, 0, 3), dollar)
+		err := quote.addAddons(rental, parseAddonSelections(selections), dollar)
+		assert.Error(test, err, "Invalid add-ons should return error for "+selections)
+		assert.Equal(test, message, err.Error(), "Correct error message is expected for "+selections)
+	}
+}
+
+func TestBookingsShouldKeepTheirAddonsWhenTheOwnerRemovesThem(test *testing.T) {
+	defer setupTest(test)()
+
+	maxQuantity := 10
+	addon, failedValidation, err := CreateAddon(1, 1, AddonInput{Name: "Firewood", PricingMode: PricingPerUnit, Price: 800, MaxQuantity: &maxQuantity})
+	if err != nil || failedValidation {
+		test.Fatalf("Error on creating add-on - %v", err)
+	}
+	assert.True(test, addon.Available, "Expected a new add-on to be available")
+
+	_, _, err = CreateAddon(2, 1, AddonInput{Name: "Firewood", PricingMode: PricingPerTrip, Price: 800})
+	assert.Error(test, err, "Expected only the owner to add add-ons to the rental")
+
+	from := time.Now().UTC().AddDate(1, 1, 0)
+	booking, failedValidation, err := CreateBooking(1, BookingInput{
+		UserId: 2,
+		From:   from.Format("2006-01-02"),
+		To:     from.AddDate(0, 0, 2).Format("2006-01-02"),
+		Addons: []AddonSelection{{AddonId: addon.Id, Quantity: 3}},
+	})
+	if err != nil || failedValidation {
+		test.Fatalf("Error on creating booking - %v", err)
+	}
+	test.Cleanup(func() {
+		_ = database.Exec("DELETE FROM bookings WHERE bookings.id = $1;", booking.Id)
+	})
+	assert.Equal(test, 2*16900+3*800, booking.Total, "Expected the add-on in the booking total")
+
+	var name string
+	err = database.GetSingleRecordNamedQuery(&name, "SELECT booking_addons.name FROM booking_addons WHERE booking_addons.booking_id = :id;", map[string]interface{}{"id": booking.Id})
+	if err != nil {
+		test.Fatalf("Error on getting booking add-on - %s", err.Error())
+	}
+	assert.Equal(test, "Firewood", name, "Expected the name of the add-on to be kept")
+
+	if err = DeleteAddon(1, 1, addon.Id); err != nil {
+		test.Fatalf("Error on deleting add-on - %s", err.Error())
+	}
+	assert.Error(test, DeleteAddon(1, 1, addon.Id), "Expected a removed add-on not to be found")
+
+	booking, err = GetBooking(booking.Id)
+	if err != nil {
+		test.Fatalf("Error on getting booking - %s", err.Error())
+	}
+	assert.Equal(test, 2*16900+3*800, booking.Total, "Expected the booking to keep its add-on")
+}
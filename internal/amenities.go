@@ -0,0 +1,86 @@
+package internal
+
+import (
+	"github.com/lib/pq"
+	"outdoorsy-api/database"
+	"outdoorsy-api/utils"
+	"strings"
+)
+
+// GetAmenities retrieves the whole amenity catalog from the database.
+func GetAmenities() (amenities []Amenity, err error) {
+	err = database.GetMultipleRecords(&amenities, selectAllAmenitiesQuery)
+	return
+}
+
+// attachAmenities loads the amenities of all provided rentals with a single query and assigns them to the
+// matching rental. Rentals without amenities are left with an empty (non nil) list so the JSON response is
+// always an array.
+func attachAmenities(rentals []Rental) error {
+	if len(rentals) == 0 {
+		return nil
+	}
+
+	var (
+		ids         = make([]int64, 0, len(rentals))
+		positions   = make(map[int]int, len(rentals))
+		assignments []rentalAmenity
+	)
+
+	for position := range rentals {
+		rentals[position].Amenities = make([]Amenity, 0)
+		ids = append(ids, int64(rentals[position].IdRental))
+		positions[rentals[position].IdRental] = position
+	}
+
+	err := database.GetMultipleRecords(&assignments, selectRentalsAmenitiesQuery, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+
+	for _, assignment := range assignments {
+		position := positions[assignment.RentalId]
+		rentals[position].Amenities = append(rentals[position].Amenities, assignment.Amenity)
+	}
+	return nil
+}
+
+// validateAmenities checks that every requested amenity key exists in the catalog. The syntax of the
+// parameter is already validated by utils.ValidateParameters. failedValidation is false if the catalog
+// itself could not be retrieved.
+func validateAmenities(amenitiesParam string) (failedValidation bool, err error) {
+	if amenitiesParam == "" {
+		return
+	}
+
+	catalog, err := GetAmenities()
+	if err != nil {
+		return
+	}
+
+	known := make(map[string]struct{}, len(catalog))
+	for _, amenity := range catalog {
+		known[amenity.Key] = struct{}{}
+	}
+
+	for _, key := range parseAmenityKeys(amenitiesParam) {
+		if _, found := known[key]; !found {
+			return true, utils.NewLocalizedError("amenity_unknown", key)
+		}
+	}
+	return
+}
+
+// parseAmenityKeys splits the amenities parameter and removes duplicated keys, so the match-all count
+// in the query stays correct.
+func parseAmenityKeys(amenitiesParam string) (keys []string) {
+	seen := make(map[string]struct{})
+	for _, key := range strings.Split(amenitiesParam, ",") {
+		if _, duplicated := seen[key]; duplicated {
+			continue
+		}
+		seen[key] = struct{}{}
+		keys = append(keys, key)
+	}
+	return
+}
@@ -0,0 +1,95 @@
+package internal
+
+import (
+	"database/sql"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"outdoorsy-api/database"
+	"outdoorsy-api/utils"
+	"strings"
+	"time"
+)
+
+const defaultBlackoutReason = "Maintenance"
+
+// CreateRentalBlackout blocks the owner's rental between the input dates, e.g. for maintenance. Blackouts are
+// blocked ranges, so quotes, bookings, the date search and the calendar feed treat them alike. A blackout can not
+// overlap a confirmed booking. If the input is not valid - failedValidation will be set to true and descriptive
+// validation error will be returned.
+func CreateRentalBlackout(ownerId int, rentalId int, input BlackoutInput) (blackout BlockedRange, failedValidation bool, err error) {
+	if err = validateBlackoutInput(input); err != nil {
+		failedValidation = true
+		return
+	}
+
+	err = database.WithTransaction(func(transaction *sqlx.Tx) error {
+		var rentals []ownedRental
+
+		if err := transaction.Select(&rentals, selectOwnerRentalsForUpdateQuery, pq.Array([]int{rentalId}), ownerId); err != nil {
+			return err
+		}
+		if len(rentals) == 0 {
+			return sql.ErrNoRows
+		}
+
+		blackout, err = addBlackout(transaction, rentalId, input)
+		return err
+	})
+	return
+}
+
+// DeleteRentalBlackout removes the blackout of the owner's rental. Ranges imported from calendars are not removed.
+func DeleteRentalBlackout(ownerId int, rentalId int, blackoutId int) error {
+	deleted, err := database.ExecNamedQuery(deleteBlackoutQuery, map[string]interface{}{
+		"owner_id":    ownerId,
+		"rental_id":   rentalId,
+		"blackout_id": blackoutId,
+	})
+	if err == nil && deleted == 0 {
+		err = sql.ErrNoRows
+	}
+	return err
+}
+
+// addBlackout inserts the blackout of the rental, which has to be locked by the transaction already so no booking
+// can be confirmed between the conflict check and the insert.
+func addBlackout(transaction *sqlx.Tx, rentalId int, input BlackoutInput) (blackout BlockedRange, err error) {
+	var conflict bool
+
+	from, _ := time.Parse(utils.DateFormat, input.From)
+	to, _ := time.Parse(utils.DateFormat, input.To)
+
+	if err = transaction.Get(&conflict, selectRentalBookingConflictQuery, rentalId, from, to); err != nil {
+		return
+	}
+	if conflict {
+		err = utils.NewLocalizedError("blackout_conflicts_booking", input.From, input.To)
+		return
+	}
+
+	reason := strings.TrimSpace(input.Reason)
+	if reason == "" {
+		reason = defaultBlackoutReason
+	}
+
+	insertStatement, err := transaction.PrepareNamed(insertBlackoutQuery)
+	if err != nil {
+		return
+	}
+	defer insertStatement.Close()
+
+	err = insertStatement.Get(&blackout, map[string]interface{}{
+		"rental_id": rentalId,
+		"summary":   reason,
+		"starts_on": from,
+		"ends_on":   to,
+	})
+	return
+}
+
+func validateBlackoutInput(input BlackoutInput) error {
+	if input.From == "" || input.To == "" {
+		return utils.NewLocalizedError("dates_required")
+	}
+	return utils.ValidateDateRange(input.From, input.To)
+}
@@ -0,0 +1,131 @@
+package internal
+
+import (
+	"fmt"
+	"github.com/lib/pq"
+	"outdoorsy-api/database"
+	"outdoorsy-api/utils"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	maximumNoticeHours   = 24 * 365
+	maximumAdvanceMonths = 24
+)
+
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// UpdateRentalBookingRules replaces the booking rules of the owner's rental. Omitted rules don't restrict the
+// trips, an empty list of check-in days allows check-in on any day. If the rules are not valid - failedValidation
+// will be set to true and descriptive validation error will be returned.
+func UpdateRentalBookingRules(ownerId int, rentalId int, rules BookingRules) (rental Rental, failedValidation bool, err error) {
+	var updatedId int
+
+	if err = validateBookingRules(rules); err != nil {
+		failedValidation = true
+		return
+	}
+
+	err = database.GetSingleRecordNamedQuery(&updatedId, updateRentalBookingRulesQuery, map[string]interface{}{
+		"rental_id":          rentalId,
+		"owner_id":           ownerId,
+		"min_nights":         rules.MinNights,
+		"max_nights":         rules.MaxNights,
+		"check_in_days":      pq.StringArray(normalizeCheckInDays(rules.CheckInDays)),
+		"notice_hours":       rules.NoticeHours,
+		"max_advance_months": rules.MaxAdvanceMonths,
+	})
+	if err != nil {
+		return
+	}
+
+	rental, err = GetASingleRental(rentalId)
+	return
+}
+
+// checkBookingRules returns the localized reason the trip between the dates violates the rules of the rental, nil
+// if it doesn't. Check-in is at the start of the from date in UTC.
+func checkBookingRules(rules BookingRules, from time.Time, to time.Time, now time.Time) error {
+	nights := int(to.Sub(from).Hours() / 24)
+	checkInDay := strings.ToLower(from.Weekday().String())
+
+	switch {
+	case rules.NoticeHours > 0 && from.Before(now.Add(time.Duration(rules.NoticeHours)*time.Hour)):
+		return utils.NewLocalizedError("booking_notice_required", rules.NoticeHours)
+	case rules.MaxAdvanceMonths != nil && from.After(truncateToDate(now).AddDate(0, *rules.MaxAdvanceMonths, 0)):
+		return utils.NewLocalizedError("booking_too_far_ahead", *rules.MaxAdvanceMonths)
+	case len(rules.CheckInDays) > 0 && !containsFold(rules.CheckInDays, checkInDay):
+		return utils.NewLocalizedError("booking_check_in_day", checkInDay, strings.Join(rules.CheckInDays, ", "))
+	case rules.MinNights != nil && nights < *rules.MinNights:
+		return utils.NewLocalizedError("booking_min_nights", *rules.MinNights)
+	case rules.MaxNights != nil && nights > *rules.MaxNights:
+		return utils.NewLocalizedError("booking_max_nights", *rules.MaxNights)
+	}
+	return nil
+}
+
+// sqlBookingRulesClause returns the SQL condition matching the rentals which are available between the dates - not
+// booked, not blocked and with booking rules allowing the trip. The dates are already validated by
+// utils.ValidateParameters.
+func sqlBookingRulesClause(fromParam string, toParam string) string {
+	from, _ := time.Parse(utils.DateFormat, fromParam)
+	to, _ := time.Parse(utils.DateFormat, toParam)
+	nights := int(to.Sub(from).Hours() / 24)
+	checkIn := fmt.Sprintf("TIMESTAMP WITH TIME ZONE '%s 00:00:00+00'", from.Format(utils.DateFormat))
+
+	return fmt.Sprintf(` (rentals.min_nights IS NULL OR rentals.min_nights <= %[1]d)
+		AND (rentals.max_nights IS NULL OR rentals.max_nights >= %[1]d)
+		AND (cardinality(rentals.check_in_days) = 0 OR '%[2]s' = ANY(rentals.check_in_days))
+		AND (rentals.notice_hours = 0 OR %[3]s >= now() + rentals.notice_hours * interval '1 hour')
+		AND (rentals.max_advance_months IS NULL OR DATE '%[4]s' <= (now() AT TIME ZONE 'UTC')::date + rentals.max_advance_months * interval '1 month')
+		AND NOT EXISTS(SELECT 1 FROM bookings WHERE bookings.rental_id = rentals.id AND bookings.status = 'confirmed' AND bookings.starts_on < DATE '%[5]s' AND bookings.ends_on > DATE '%[4]s')
+		AND NOT EXISTS(SELECT 1 FROM rental_blocked_ranges WHERE rental_blocked_ranges.rental_id = rentals.id AND rental_blocked_ranges.starts_on < DATE '%[5]s' AND rental_blocked_ranges.ends_on > DATE '%[4]s')`,
+		nights, strings.ToLower(from.Weekday().String()), checkIn, from.Format(utils.DateFormat), to.Format(utils.DateFormat))
+}
+
+// normalizeCheckInDays lowercases the check-in days and orders them from Sunday, without duplicates.
+func normalizeCheckInDays(days []string) []string {
+	normalized := make([]string, 0, len(days))
+	for _, day := range days {
+		day = strings.ToLower(strings.TrimSpace(day))
+		if !containsFold(normalized, day) {
+			normalized = append(normalized, day)
+		}
+	}
+	sort.Slice(normalized, func(i, j int) bool {
+		return weekdays[normalized[i]] < weekdays[normalized[j]]
+	})
+	return normalized
+}
+
+func validateBookingRules(rules BookingRules) error {
+	switch {
+	case rules.MinNights != nil && *rules.MinNights <= 0:
+		return utils.NewLocalizedError("field_invalid", "min_nights")
+	case rules.MaxNights != nil && *rules.MaxNights <= 0:
+		return utils.NewLocalizedError("field_invalid", "max_nights")
+	case rules.MinNights != nil && rules.MaxNights != nil && *rules.MaxNights < *rules.MinNights:
+		return utils.NewLocalizedError("field_invalid", "max_nights")
+	case rules.NoticeHours < 0 || rules.NoticeHours > maximumNoticeHours:
+		return utils.NewLocalizedError("field_invalid", "notice_hours")
+	case rules.MaxAdvanceMonths != nil && (*rules.MaxAdvanceMonths <= 0 || *rules.MaxAdvanceMonths > maximumAdvanceMonths):
+		return utils.NewLocalizedError("field_invalid", "max_advance_months")
+	}
+
+	for _, day := range rules.CheckInDays {
+		if _, found := weekdays[strings.ToLower(strings.TrimSpace(day))]; !found {
+			return utils.NewLocalizedError("field_invalid", "check_in_days")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package internal
+
+import (
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestCheckBookingRulesShouldReturnTheBrokenRule(test *testing.T) {
+	var (
+		minNights        = 3
+		maxNights        = 14
+		maxAdvanceMonths = 9
+		rules            = BookingRules{
+			MinNights:        &minNights,
+			MaxNights:        &maxNights,
+			CheckInDays:      pq.StringArray{"monday", "friday"},
+			NoticeHours:      48,
+			MaxAdvanceMonths: &maxAdvanceMonths,
+		}
+		now    = time.Date(2026, 6, 1, 10, 0, 0, 0, time.UTC) // Monday
+		monday = time.Date(2026, 6, 8, 0, 0, 0, 0, time.UTC)
+	)
+
+	assert.Nil(test, checkBookingRules(rules, monday, monday.AddDate(0, 0, 4), now), "Expected a trip within the rules to be allowed")
+	assert.Nil(test, checkBookingRules(BookingRules{}, now, now.AddDate(0, 0, 1), now), "Expected a rental without rules to allow any trip")
+
+	testCases := map[string][2]time.Time{
+		"the rental requires 48 hours notice before check-in":                             {now.AddDate(0, 0, 2).Truncate(24 * time.Hour), now.AddDate(0, 0, 6)},
+		"the rental can be booked at most 9 months ahead":                                 {time.Date(2027, 3, 5, 0, 0, 0, 0, time.UTC), time.Date(2027, 3, 9, 0, 0, 0, 0, time.UTC)},
+		"check-in is not allowed on sunday, the rental allows check-in on monday, friday": {monday.AddDate(0, 0, -1), monday.AddDate(0, 0, 3)},
+		"the rental requires a minimum stay of 3 nights":                                  {monday, monday.AddDate(0, 0, 2)},
+		"the rental allows a maximum stay of 14 nights":                                   {monday, monday.AddDate(0, 0, 15)},
+	}
+	for message, dates := range testCases {
+		err := checkBookingRules(rules, dates[0], dates[1], now)
+		assert.Error(test, err, "Trips breaking a rule should return error")
+		assert.Equal(test, message, err.Error(), "Correct error message is expected")
+	}
+}
+
+func TestGetMultipleRentalsWithDatesShouldExcludeRentalsWhoseRulesTheTripBreaks(test *testing.T) {
+	defer setupTest(test)()
+
+	from := truncateToDate(time.Now().UTC()).AddDate(0, 1, 0)
+	for from.Weekday() != time.Wednesday {
+		from = from.AddDate(0, 0, 1)
+	}
+
+	rentals, _, err := GetMultipleRentals(url.Values{
+		"from":   {from.Format("2006-01-02")},
+		"to":     {from.AddDate(0, 0, 2).Format("2006-01-02")},
+		"status": {StatusPublished},
+	})
+	if err != nil {
+		test.Fatalf("Error on getting rentals - %s", err.Error())
+	}
+
+	assert.NotEmpty(test, rentals, "Expected rentals available for two nights")
+	for _, rental := range rentals {
+		assert.Nil(test, checkBookingRules(rental.BookingRules, from, from.AddDate(0, 0, 2), time.Now()), "Expected only rentals allowing the trip")
+	}
+
+	_, failedValidation, err := GetMultipleRentals(url.Values{"from": {from.Format("2006-01-02")}})
+	assert.True(test, failedValidation, "Expected the search to require both dates")
+	assert.Equal(test, "from and to dates are required", err.Error(), "Correct error message is expected")
+}
@@ -0,0 +1,286 @@
+package internal
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/jmoiron/sqlx"
+	"net/url"
+	"outdoorsy-api/database"
+	"outdoorsy-api/utils"
+	"strconv"
+	"time"
+)
+
+// CreateBooking books the rental for the user between the input dates. Amounts are stored in the base currency, the
+// quote of the booking is returned in the requested one. A requested promo code is redeemed together with the
+// booking, so concurrent bookings can not exceed its caps. If the input is not valid, the trip breaks a booking rule
+// of the rental or the promo code can not be applied - failedValidation will be set to true and descriptive
+// validation error will be returned.
+func CreateBooking(rentalId int, input BookingInput) (booking Booking, failedValidation bool, err error) {
+	var (
+		user   User
+		params = url.Values{"from": {input.From}, "to": {input.To}, "currency": {input.Currency}, "units": {input.Units}, "delivery_to": {input.DeliveryTo}}
+	)
+
+	if input.UserId <= 0 {
+		return booking, true, utils.NewLocalizedError("field_required", "user_id")
+	}
+
+	if err = utils.ValidateQuoteParameters(params); err != nil {
+		failedValidation = true
+		return
+	}
+
+	rate, err := GetExchangeRate(input.Currency)
+	if err != nil {
+		failedValidation = true
+		return
+	}
+
+	from, _ := time.Parse(utils.DateFormat, input.From)
+	to, _ := time.Parse(utils.DateFormat, input.To)
+	if from.Before(truncateToDate(time.Now().UTC())) {
+		return booking, true, utils.NewLocalizedError("date_in_past")
+	}
+
+	err = database.GetSingleRecordNamedQuery(&user, selectUserQuery, map[string]interface{}{"id": input.UserId})
+	if errors.Is(err, sql.ErrNoRows) {
+		return booking, false, utils.NewLocalizedError("user_not_found")
+	}
+	if err != nil {
+		return
+	}
+
+	rental, err := GetASingleRental(rentalId)
+	if err != nil {
+		return
+	}
+
+	if err = checkBookingRules(rental.BookingRules, from, to, time.Now()); err != nil {
+		failedValidation = true
+		return
+	}
+
+	var (
+		bookingId int
+		quote     = newQuote(rental, from, to, rate)
+		baseQuote = newQuote(rental, from, to, baseExchangeRate())
+	)
+
+	for index := range input.Addons {
+		if input.Addons[index].Quantity == 0 {
+			input.Addons[index].Quantity = 1
+		}
+	}
+	if err = quote.addAddons(rental, input.Addons, rate); err != nil {
+		failedValidation = true
+		return
+	}
+	if err = baseQuote.addAddons(rental, input.Addons, baseExchangeRate()); err != nil {
+		failedValidation = true
+		return
+	}
+
+	if input.DeliveryTo != "" {
+		if err = quote.addDelivery(rental, input.DeliveryTo, rate, getUnitSystem(input.Units)); err != nil {
+			failedValidation = true
+			return
+		}
+		if err = baseQuote.addDelivery(rental, input.DeliveryTo, baseExchangeRate(), getUnitSystem(input.Units)); err != nil {
+			failedValidation = true
+			return
+		}
+	}
+
+	err = database.WithTransaction(func(transaction *sqlx.Tx) error {
+		var (
+			status      string
+			unavailable bool
+			promoCodeId *int
+		)
+
+		// Locking the rental serializes the bookings of the rental, so two overlapping bookings can't both pass
+		// the availability check.
+		if err := transaction.Get(&status, selectRentalForBookingQuery, rentalId); err != nil {
+			return err
+		}
+		if status != StatusPublished {
+			return utils.NewLocalizedError("rental_not_bookable")
+		}
+
+		if err := transaction.Get(&unavailable, selectRentalAvailabilityConflictQuery, rentalId, from, to); err != nil {
+			return err
+		}
+		if unavailable {
+			return utils.NewLocalizedError("rental_unavailable", input.From, input.To)
+		}
+
+		if input.PromoCode != "" {
+			promoCode, rejection, err := redeemablePromoCode(transaction, input.PromoCode, rental, input.UserId, &quote, &baseQuote, rate)
+			if err != nil {
+				return err
+			}
+			if rejection != nil {
+				failedValidation = true
+				return rejection
+			}
+			promoCodeId = &promoCode.Id
+		}
+
+		insertStatement, err := transaction.PrepareNamed(insertBookingQuery)
+		if err != nil {
+			return err
+		}
+		defer insertStatement.Close()
+
+		discount := -baseQuote.linesAmount("discount")
+		err = insertStatement.Get(&bookingId, map[string]interface{}{
+			"rental_id":     rentalId,
+			"user_id":       input.UserId,
+			"starts_on":     from,
+			"ends_on":       to,
+			"nightly_price": rental.Price.Day,
+			"discount":      discount,
+			"delivery_fee":  baseQuote.linesAmount("delivery"),
+			"total":         baseQuote.Total,
+			"promo_code_id": promoCodeId,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, line := range baseQuote.Lines {
+			if line.Type != "addon" {
+				continue
+			}
+			_, err = transaction.NamedExec(insertBookingAddonQuery, map[string]interface{}{
+				"booking_id": bookingId,
+				"addon_id":   line.addonId,
+				"name":       line.addonName,
+				"quantity":   line.Quantity,
+				"amount":     line.Amount,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if promoCodeId == nil {
+			return nil
+		}
+		_, err = transaction.NamedExec(insertPromoCodeRedemptionQuery, map[string]interface{}{
+			"promo_code_id": *promoCodeId,
+			"booking_id":    bookingId,
+			"user_id":       input.UserId,
+			"discount":      discount,
+		})
+		return err
+	})
+	if err != nil {
+		return
+	}
+
+	booking, err = GetBooking(bookingId)
+	booking.Quote = &quote
+	return
+}
+
+// redeemablePromoCode locks the promo code and applies it to both quotes of the booking. The lock is held until
+// the booking transaction ends, so the redemptions counted here can't change before the new one is recorded.
+func redeemablePromoCode(transaction *sqlx.Tx, code string, rental Rental, userId int, quote *Quote, baseQuote *Quote, rate ExchangeRate) (promoCode PromoCode, rejection error, err error) {
+	var usage promoCodeUsage
+
+	err = transaction.Get(&promoCode, selectPromoCodeForUpdateQuery, normalizePromoCode(code))
+	if errors.Is(err, sql.ErrNoRows) {
+		return promoCode, utils.NewLocalizedError("promo_code_unknown", normalizePromoCode(code)), nil
+	}
+	if err != nil {
+		return
+	}
+
+	usageStatement, err := transaction.PrepareNamed(selectPromoCodeUsageQuery)
+	if err != nil {
+		return
+	}
+	defer usageStatement.Close()
+
+	err = usageStatement.Get(&usage, map[string]interface{}{"promo_code_id": promoCode.Id, "user_id": userId})
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+	if rejection = quote.applyPromoCode(promoCode, rental, userId, usage, rate, now); rejection != nil {
+		return
+	}
+	rejection = baseQuote.applyPromoCode(promoCode, rental, userId, usage, baseExchangeRate(), now)
+	return
+}
+
+// GetBooking retrieves the booking with its amounts in the base currency.
+func GetBooking(id int) (booking Booking, err error) {
+	err = database.GetSingleRecordNamedQuery(&booking, selectBookingQuery, map[string]interface{}{"id": id})
+	if err != nil {
+		return
+	}
+
+	booking.From = booking.StartsOn.Format(utils.DateFormat)
+	booking.To = booking.EndsOn.Format(utils.DateFormat)
+	booking.Currency = BaseCurrency
+	return
+}
+
+// CancelBooking cancels the confirmed booking of the user, which frees its dates and releases its promo code
+// redemption. A booking which is already cancelled returns a booking_not_cancellable error.
+func CancelBooking(userId int, bookingId int) (booking Booking, err error) {
+	err = database.WithTransaction(func(transaction *sqlx.Tx) error {
+		var (
+			cancelledId int
+			current     struct {
+				Id     int    `db:"id"`
+				UserId int    `db:"user_id"`
+				Status string `db:"status"`
+			}
+		)
+
+		cancelStatement, err := transaction.PrepareNamed(cancelBookingQuery)
+		if err != nil {
+			return err
+		}
+		defer cancelStatement.Close()
+
+		err = cancelStatement.Get(&cancelledId, map[string]interface{}{"id": bookingId, "user_id": userId})
+		if errors.Is(err, sql.ErrNoRows) {
+			selectStatement, err := transaction.PrepareNamed(selectBookingOwnershipQuery)
+			if err != nil {
+				return err
+			}
+			defer selectStatement.Close()
+
+			if err = selectStatement.Get(&current, map[string]interface{}{"id": bookingId}); err != nil {
+				return err
+			}
+			if current.UserId != userId {
+				return sql.ErrNoRows
+			}
+			return utils.NewLocalizedError("booking_not_cancellable", current.Status)
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = transaction.NamedExec(deletePromoCodeRedemptionQuery, map[string]interface{}{"id": bookingId})
+		return err
+	})
+	if err != nil {
+		return
+	}
+
+	booking, err = GetBooking(bookingId)
+	return
+}
+
+// bookingUID is the calendar UID of the booking, unique across the calendars of all rentals.
+func bookingUID(id int) string {
+	return "booking-" + strconv.Itoa(id) + exportedUIDSuffix
+}
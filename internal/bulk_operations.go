@@ -0,0 +1,238 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"math"
+	"outdoorsy-api/database"
+	"outdoorsy-api/utils"
+)
+
+const (
+	BulkSetPrice     = "set_price"
+	BulkAdjustPrice  = "adjust_price"
+	BulkAddBlackout  = "add_blackout"
+	BulkChangeStatus = "change_status"
+
+	BulkResultApplied    = "applied"
+	BulkResultFailed     = "failed"
+	BulkResultRolledBack = "rolled_back"
+
+	maximumBulkRentals    = 100
+	maximumBulkOperations = 10
+	maximumPriceIncrease  = 1000
+)
+
+var errBulkRolledBack = errors.New("bulk operations rolled back")
+
+// ApplyBulkOperations applies the operations, in order, to every listed rental of the owner in a single
+// transaction. Either every rental is changed or none is - a rental which is not the owner's or an operation which
+// fails on any rental rolls all of them back, and the report tells which rental and operation failed and why. If
+// the request is not valid - failedValidation will be set to true and descriptive validation error will be
+// returned.
+func ApplyBulkOperations(ownerId int, request BulkRequest) (report BulkReport, failedValidation bool, err error) {
+	if err = validateBulkRequest(request); err != nil {
+		failedValidation = true
+		return
+	}
+
+	err = database.WithTransaction(func(transaction *sqlx.Tx) error {
+		var (
+			rentals []ownedRental
+			owned   = make(map[int]ownedRental, len(request.RentalIds))
+			failed  bool
+		)
+
+		// Locking the rentals in id order keeps concurrent bulk operations and bookings from deadlocking.
+		if err := transaction.Select(&rentals, selectOwnerRentalsForUpdateQuery, pq.Array(request.RentalIds), ownerId); err != nil {
+			return err
+		}
+		for _, rental := range rentals {
+			owned[rental.Id] = rental
+		}
+
+		report.Rentals = make([]BulkRentalResult, 0, len(request.RentalIds))
+		for _, rentalId := range request.RentalIds {
+			result, err := applyRentalBulkOperations(transaction, ownerId, rentalId, owned, request.Operations)
+			if err != nil {
+				return err
+			}
+			failed = failed || result.err != nil
+			report.Rentals = append(report.Rentals, result)
+		}
+
+		if failed {
+			return errBulkRolledBack
+		}
+		return nil
+	})
+	if errors.Is(err, errBulkRolledBack) {
+		for index := range report.Rentals {
+			if report.Rentals[index].err == nil {
+				report.Rentals[index].Result = BulkResultRolledBack
+			}
+		}
+		return report, false, nil
+	}
+	report.Applied = err == nil
+	return
+}
+
+// Failure returns the localized reason the operations failed on the rental, nil if they were applied or rolled
+// back because of another rental.
+func (result BulkRentalResult) Failure() error {
+	return result.err
+}
+
+// applyRentalBulkOperations applies the operations to the rental until one fails. Localized errors fail the rental
+// only, any other error fails the whole transaction.
+func applyRentalBulkOperations(transaction *sqlx.Tx, ownerId int, rentalId int, owned map[int]ownedRental, operations []BulkOperation) (result BulkRentalResult, err error) {
+	result = BulkRentalResult{RentalId: rentalId, Result: BulkResultApplied}
+
+	rental, found := owned[rentalId]
+	if !found {
+		result.Result = BulkResultFailed
+		result.err = utils.NewLocalizedError("rental_not_found")
+		return
+	}
+
+	for index, operation := range operations {
+		err = applyBulkOperation(transaction, ownerId, &rental, operation, &result)
+
+		var localized utils.LocalizedError
+		if errors.As(err, &localized) {
+			failedOperation := index
+			result.Result = BulkResultFailed
+			result.FailedOperation = &failedOperation
+			result.err = err
+			return result, nil
+		}
+		if err != nil {
+			return
+		}
+	}
+
+	result.Price = rental.Price
+	result.Status = rental.Status
+	return
+}
+
+func applyBulkOperation(transaction *sqlx.Tx, ownerId int, rental *ownedRental, operation BulkOperation, result *BulkRentalResult) error {
+	switch operation.Type {
+	case BulkSetPrice:
+		rental.Price = *operation.Price
+	case BulkAdjustPrice:
+		price := int(math.Round(float64(rental.Price) * (1 + *operation.Percent/100)))
+		if price <= 0 {
+			return utils.NewLocalizedError("bulk_price_not_positive", price)
+		}
+		rental.Price = price
+	case BulkAddBlackout:
+		blackout, err := addBlackout(transaction, rental.Id, operation.BlackoutInput)
+		if err != nil {
+			return err
+		}
+		result.Blackouts = append(result.Blackouts, blackout)
+		return nil
+	case BulkChangeStatus:
+		return changeRentalStatus(transaction, ownerId, rental, operation.Status)
+	}
+
+	_, err := transaction.Exec(updateRentalPriceQuery, rental.Id, rental.Price)
+	return err
+}
+
+// changeRentalStatus moves the rental to the status through the owner action of the moderation workflow which
+// leads there. A rental already in the status is left as it is.
+func changeRentalStatus(transaction *sqlx.Tx, ownerId int, rental *ownedRental, status string) error {
+	if rental.Status == status {
+		return nil
+	}
+
+	for action, rule := range statusTransitionRules {
+		if rule.actor != ActorOwner || rule.from != rental.Status || rule.to != status {
+			continue
+		}
+
+		_, err := transaction.NamedExec(updateRentalStatusQuery, map[string]interface{}{
+			"rental_id":   rental.Id,
+			"from_status": rule.from,
+			"to_status":   rule.to,
+			"owner_id":    ownerId,
+		})
+		if err != nil {
+			return fmt.Errorf("%s rental %d: %w", action, rental.Id, err)
+		}
+		if err = recordStatusTransition(transaction, rental.Id, &rule.from, rule.to, ActorOwner, &ownerId, ""); err != nil {
+			return err
+		}
+		rental.Status = status
+		return nil
+	}
+	return utils.NewLocalizedError("rental_status_change_invalid", rental.Status, status)
+}
+
+func validateBulkRequest(request BulkRequest) error {
+	var seen = make(map[int]bool, len(request.RentalIds))
+
+	switch {
+	case len(request.RentalIds) == 0:
+		return utils.NewLocalizedError("field_required", "rental_ids")
+	case len(request.RentalIds) > maximumBulkRentals:
+		return utils.NewLocalizedError("bulk_too_many", "rental_ids", maximumBulkRentals)
+	case len(request.Operations) == 0:
+		return utils.NewLocalizedError("field_required", "operations")
+	case len(request.Operations) > maximumBulkOperations:
+		return utils.NewLocalizedError("bulk_too_many", "operations", maximumBulkOperations)
+	}
+
+	for _, rentalId := range request.RentalIds {
+		if rentalId <= 0 || seen[rentalId] {
+			return utils.NewLocalizedError("field_invalid", "rental_ids")
+		}
+		seen[rentalId] = true
+	}
+
+	for index, operation := range request.Operations {
+		if err := validateBulkOperation(index, operation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateBulkOperation(index int, operation BulkOperation) error {
+	field := func(name string) string {
+		return fmt.Sprintf("operations[%d].%s", index, name)
+	}
+
+	switch operation.Type {
+	case BulkSetPrice:
+		if operation.Price == nil {
+			return utils.NewLocalizedError("field_required", field("price"))
+		}
+		if *operation.Price <= 0 {
+			return utils.NewLocalizedError("field_invalid", field("price"))
+		}
+	case BulkAdjustPrice:
+		if operation.Percent == nil {
+			return utils.NewLocalizedError("field_required", field("percent"))
+		}
+		if *operation.Percent <= -100 || *operation.Percent > maximumPriceIncrease || math.IsNaN(*operation.Percent) {
+			return utils.NewLocalizedError("field_invalid", field("percent"))
+		}
+	case BulkAddBlackout:
+		if err := validateBlackoutInput(operation.BlackoutInput); err != nil {
+			return err
+		}
+	case BulkChangeStatus:
+		if operation.Status != StatusPendingReview && operation.Status != StatusPublished && operation.Status != StatusUnlisted {
+			return utils.NewLocalizedError("field_invalid", field("status"))
+		}
+	default:
+		return utils.NewLocalizedError("field_invalid", field("type"))
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package internal
+
+import (
+	"github.com/stretchr/testify/assert"
+	"outdoorsy-api/database"
+	"testing"
+	"time"
+)
+
+func TestValidateBulkRequestShouldReturnTheInvalidField(test *testing.T) {
+	var (
+		price   = 15000
+		percent = -100.0
+	)
+
+	testCases := map[string]BulkRequest{
+		"rental_ids is required":                  {Operations: []BulkOperation{{Type: BulkSetPrice, Price: &price}}},
+		"operations is required":                  {RentalIds: []int{1}},
+		"rental_ids is invalid":                   {RentalIds: []int{1, 1}, Operations: []BulkOperation{{Type: BulkSetPrice, Price: &price}}},
+		"operations[0].type is invalid":           {RentalIds: []int{1}, Operations: []BulkOperation{{Type: "delete"}}},
+		"operations[1].percent is required":       {RentalIds: []int{1}, Operations: []BulkOperation{{Type: BulkSetPrice, Price: &price}, {Type: BulkAdjustPrice}}},
+		"operations[0].percent is invalid":        {RentalIds: []int{1}, Operations: []BulkOperation{{Type: BulkAdjustPrice, Percent: &percent}}},
+		"operations[0].status is invalid":         {RentalIds: []int{1}, Operations: []BulkOperation{{Type: BulkChangeStatus, Status: StatusDraft}}},
+		"from and to dates are required":          {RentalIds: []int{1}, Operations: []BulkOperation{{Type: BulkAddBlackout}}},
+		"rental_ids can have at most 100 entries": {RentalIds: make([]int, 101), Operations: []BulkOperation{{Type: BulkSetPrice, Price: &price}}},
+	}
+	for message, request := range testCases {
+		err := validateBulkRequest(request)
+		assert.Error(test, err, "Invalid bulk requests should return error")
+		assert.Equal(test, message, err.Error(), "Correct error message is expected")
+	}
+}
+
+func TestApplyBulkOperationsShouldRollBackEveryRentalIfOneFails(test *testing.T) {
+	defer setupTest(test)()
+
+	var (
+		price   = 12345
+		from    = "2031-03-02"
+		started = time.Now()
+	)
+
+	before, err := GetASingleRental(6)
+	if err != nil {
+		test.Fatalf("Error on getting rental - %s", err.Error())
+	}
+
+	report, failedValidation, err := ApplyBulkOperations(1, BulkRequest{
+		RentalIds:  []int{6, 2},
+		Operations: []BulkOperation{{Type: BulkSetPrice, Price: &price}},
+	})
+	if err != nil || failedValidation {
+		test.Fatalf("Error on applying bulk operations - %v", err)
+	}
+	assert.False(test, report.Applied, "Expected a rental of another owner to roll back the bulk operations")
+	assert.Equal(test, BulkResultRolledBack, report.Rentals[0].Result, "Expected the owner's rental to be rolled back")
+	assert.Equal(test, BulkResultFailed, report.Rentals[1].Result, "Expected the rental of another owner to fail")
+	assert.Equal(test, "rental not found", report.Rentals[1].Failure().Error(), "Correct failure reason is expected")
+
+	after, err := GetASingleRental(6)
+	if err != nil {
+		test.Fatalf("Error on getting rental - %s", err.Error())
+	}
+	assert.Equal(test, before.Price.Day, after.Price.Day, "Expected the price of the rolled back rental not to change")
+
+	// the statuses, blackouts and transitions of the rentals are restored
+	for _, id := range []int{11, 16} {
+		rental, err := GetASingleRental(id)
+		if err != nil {
+			test.Fatalf("Error on getting rental - %s", err.Error())
+		}
+		test.Cleanup(func() {
+			_ = database.Exec("UPDATE rentals SET status = $2 WHERE rentals.id = $1;", rental.IdRental, rental.Status)
+			_ = database.Exec("DELETE FROM rental_blocked_ranges WHERE rental_id = $1 AND source = 'blackout' AND starts_on = $2;", rental.IdRental, from)
+			_ = database.Exec("DELETE FROM rental_status_transitions WHERE rental_id = $1 AND created >= $2;", rental.IdRental, started)
+		})
+	}
+
+	report, failedValidation, err = ApplyBulkOperations(1, BulkRequest{
+		RentalIds: []int{11, 16},
+		Operations: []BulkOperation{
+			{Type: BulkAddBlackout, BlackoutInput: BlackoutInput{From: from, To: "2031-03-05"}},
+			{Type: BulkChangeStatus, Status: StatusPublished},
+		},
+	})
+	if err != nil || failedValidation {
+		test.Fatalf("Error on applying bulk operations - %v", err)
+	}
+	assert.True(test, report.Applied, "Expected the bulk operations to be applied")
+	for _, result := range report.Rentals {
+		assert.Equal(test, BulkResultApplied, result.Result, "Expected every rental to be applied")
+		assert.Len(test, result.Blackouts, 1, "Expected a blackout for every rental")
+		assert.Equal(test, defaultBlackoutReason, result.Blackouts[0].Summary, "Expected the default blackout reason")
+		assert.Nil(test, DeleteRentalBlackout(1, result.RentalId, result.Blackouts[0].Id), "Expected the owner to remove the blackout")
+	}
+}
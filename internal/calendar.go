@@ -0,0 +1,155 @@
+package internal
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"io"
+	"os"
+	"outdoorsy-api/database"
+	"outdoorsy-api/utils"
+	"strings"
+)
+
+const calendarImportSource = "ics"
+
+// exportedUIDSuffix ends the UIDs of the events the API exports, which are not imported back from a feed.
+const exportedUIDSuffix = "@outdoorsy-api"
+
+// ExportRentalCalendar builds an RFC 5545 feed with every range in which the rental is not available - the
+// blocked ranges and the confirmed bookings.
+func ExportRentalCalendar(id int) (calendar []byte, err error) {
+	var (
+		rental   Rental
+		ranges   []BlockedRange
+		bookings []Booking
+		buffer   bytes.Buffer
+	)
+
+	err = database.GetSingleRecordNamedQuery(&rental, selectRentalNameQuery, map[string]interface{}{"id": id})
+	if err != nil {
+		return
+	}
+
+	err = database.GetMultipleRecords(&ranges, selectRentalBlockedRangesQuery, id)
+	if err != nil {
+		return
+	}
+
+	err = database.GetMultipleRecords(&bookings, selectRentalBookingsQuery, id)
+	if err != nil {
+		return
+	}
+
+	events := make([]calendarEvent, 0, len(ranges)+len(bookings))
+	for _, blockedRange := range ranges {
+		events = append(events, calendarEvent{
+			UID:      blockedRange.UID,
+			Summary:  blockedRange.Summary,
+			StartsOn: blockedRange.StartsOn,
+			EndsOn:   blockedRange.EndsOn,
+			Stamp:    blockedRange.Updated,
+		})
+	}
+	for _, booking := range bookings {
+		events = append(events, calendarEvent{
+			UID:      bookingUID(booking.Id),
+			Summary:  "Booked",
+			StartsOn: booking.StartsOn,
+			EndsOn:   booking.EndsOn,
+			Stamp:    booking.Updated,
+		})
+	}
+
+	err = writeICS(&buffer, rental.Name, events)
+	calendar = buffer.Bytes()
+	return
+}
+
+// ImportRentalCalendar ingests an ICS feed as blocked ranges of the owner's rental. Events are keyed by their UID, so
+// importing the same feed again only updates ranges whose dates or summary changed, and cancelled events remove
+// the range imported for them earlier. Ranges which weren't imported, like blackouts, are never changed by a feed.
+// The rental is locked while importing, so no booking can be confirmed over a range being imported. If the feed
+// can not be parsed failedValidation will be set to true.
+func ImportRentalCalendar(ownerId int, rentalId int, reader io.Reader) (result CalendarImportResult, failedValidation bool, err error) {
+	events, err := parseICS(reader)
+	if err != nil {
+		failedValidation = true
+		err = utils.NewLocalizedError("calendar_invalid", err.Error())
+		return
+	}
+
+	err = database.WithTransaction(func(transaction *sqlx.Tx) error {
+		var rentals []ownedRental
+
+		if err := transaction.Select(&rentals, selectOwnerRentalsForUpdateQuery, pq.Array([]int{rentalId}), ownerId); err != nil {
+			return err
+		}
+		if len(rentals) == 0 {
+			return sql.ErrNoRows
+		}
+
+		upsertStatement, err := transaction.PrepareNamed(upsertBlockedRangeQuery)
+		if err != nil {
+			return err
+		}
+		defer upsertStatement.Close()
+
+		for _, event := range events {
+			// the feed exported by the API, or a calendar which synced it, would otherwise turn the blackouts
+			// and bookings of the rental into imported ranges
+			if strings.HasSuffix(event.UID, exportedUIDSuffix) {
+				result.Skipped++
+				continue
+			}
+
+			arguments := map[string]interface{}{
+				"rental_id": rentalId,
+				"uid":       event.UID,
+				"summary":   event.Summary,
+				"starts_on": event.StartsOn,
+				"ends_on":   event.EndsOn,
+				"source":    calendarImportSource,
+			}
+
+			if event.Cancelled {
+				deleted, err := transaction.NamedExec(deleteBlockedRangeQuery, arguments)
+				if err != nil {
+					return err
+				}
+				removed, _ := deleted.RowsAffected()
+				result.Removed += int(removed)
+				continue
+			}
+
+			var inserted bool
+			err = upsertStatement.Get(&inserted, arguments)
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				result.Unchanged++
+			case err != nil:
+				return fmt.Errorf("importing calendar event %s: %w", event.UID, err)
+			case inserted:
+				result.Created++
+			default:
+				result.Updated++
+			}
+		}
+		return nil
+	})
+	return
+}
+
+// ImportRentalCalendarFromFile is ImportRentalCalendar for an ICS file on the local file system.
+func ImportRentalCalendarFromFile(ownerId int, rentalId int, path string) (result CalendarImportResult, failedValidation bool, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	return ImportRentalCalendar(ownerId, rentalId, file)
+}
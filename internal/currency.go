@@ -0,0 +1,181 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"outdoorsy-api/utils"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BaseCurrency is the currency in which rental prices are stored in the database.
+const BaseCurrency = "USD"
+
+// currencyMinorUnits lists the ISO 4217 currencies which don't use two decimal places.
+var currencyMinorUnits = map[string]int{
+	"BHD": 3, "CLP": 0, "ISK": 0, "JOD": 3, "JPY": 0, "KRW": 0,
+	"KWD": 3, "OMR": 3, "PYG": 0, "TND": 3, "UGX": 0, "VND": 0,
+}
+
+var exchangeRates = struct {
+	sync.RWMutex
+	path     string
+	loadedAt time.Time
+	rates    map[string]ExchangeRate
+}{
+	rates: map[string]ExchangeRate{BaseCurrency: newExchangeRate(BaseCurrency, 1)},
+}
+
+// LoadExchangeRates replaces the exchange-rate table with the rates from the JSON file on the specified path.
+// The path is remembered, so the table can be reloaded later with RefreshExchangeRates.
+func LoadExchangeRates(path string) error {
+	var file ExchangeRatesTable
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	if err = json.Unmarshal(content, &file); err != nil {
+		return err
+	}
+
+	if file.Base != BaseCurrency {
+		return fmt.Errorf("exchange rates should be based on %s, got %s", BaseCurrency, file.Base)
+	}
+
+	rates := map[string]ExchangeRate{BaseCurrency: newExchangeRate(BaseCurrency, 1)}
+	for currency, rate := range file.Rates {
+		if rate <= 0 {
+			return fmt.Errorf("exchange rate for %s must be a positive number", currency)
+		}
+		rates[strings.ToUpper(currency)] = newExchangeRate(strings.ToUpper(currency), rate)
+	}
+
+	exchangeRates.Lock()
+	defer exchangeRates.Unlock()
+	exchangeRates.path = path
+	exchangeRates.loadedAt = time.Now().UTC()
+	exchangeRates.rates = rates
+	return nil
+}
+
+// RefreshExchangeRates reloads the exchange-rate table from the file it was last loaded from.
+func RefreshExchangeRates() (ExchangeRatesTable, error) {
+	exchangeRates.RLock()
+	path := exchangeRates.path
+	exchangeRates.RUnlock()
+
+	if path == "" {
+		return GetExchangeRates(), fmt.Errorf("exchange rates were never loaded from a file")
+	}
+
+	if err := LoadExchangeRates(path); err != nil {
+		return GetExchangeRates(), err
+	}
+	return GetExchangeRates(), nil
+}
+
+// GetExchangeRates returns the currently used exchange-rate table.
+func GetExchangeRates() (table ExchangeRatesTable) {
+	exchangeRates.RLock()
+	defer exchangeRates.RUnlock()
+
+	table.Base = BaseCurrency
+	table.LoadedAt = exchangeRates.loadedAt
+	table.Rates = make(map[string]float64, len(exchangeRates.rates))
+	for currency, rate := range exchangeRates.rates {
+		table.Rates[currency] = rate.Rate
+	}
+	return
+}
+
+// GetExchangeRate returns the rate for converting base currency amounts to the specified currency. An empty
+// currency means the base currency. The syntax of the currency is already validated by utils.ValidateParameters.
+func GetExchangeRate(currency string) (rate ExchangeRate, err error) {
+	if currency == "" {
+		currency = BaseCurrency
+	}
+
+	exchangeRates.RLock()
+	defer exchangeRates.RUnlock()
+
+	rate, found := exchangeRates.rates[strings.ToUpper(currency)]
+	if !found {
+		err = utils.NewLocalizedError("currency_unsupported", strings.ToUpper(currency))
+	}
+	return
+}
+
+func baseExchangeRate() ExchangeRate {
+	rate, _ := GetExchangeRate(BaseCurrency)
+	return rate
+}
+
+func newExchangeRate(currency string, rate float64) ExchangeRate {
+	minorUnits, found := currencyMinorUnits[currency]
+	if !found {
+		minorUnits = 2
+	}
+
+	// the factor converts base currency cents to minor units of the currency, it's kept as an exact decimal so
+	// amounts converted here and in the database are rounded the same way
+	factor, _ := new(big.Rat).SetString(strconv.FormatFloat(rate, 'f', -1, 64))
+	factor.Mul(factor, new(big.Rat).SetFrac(
+		new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(minorUnits)), nil),
+		big.NewInt(100),
+	))
+
+	return ExchangeRate{Currency: currency, Rate: rate, MinorUnits: minorUnits, factor: factor}
+}
+
+// Convert converts an amount in base currency cents to minor units of the rate's currency, rounding half away
+// from zero.
+func (rate ExchangeRate) Convert(amount int) int {
+	product := new(big.Rat).Mul(big.NewRat(int64(amount), 1), rate.factor)
+
+	quotient, remainder := new(big.Int).QuoRem(product.Num(), product.Denom(), new(big.Int))
+	if new(big.Int).Mul(new(big.Int).Abs(remainder), big.NewInt(2)).Cmp(product.Denom()) >= 0 {
+		quotient.Add(quotient, big.NewInt(int64(product.Num().Sign())))
+	}
+	return int(quotient.Int64())
+}
+
+// ConvertPrice returns the price with its amounts converted to the rate's currency.
+func (rate ExchangeRate) ConvertPrice(price Price) Price {
+	return Price{Day: rate.Convert(price.Day), Currency: rate.Currency}
+}
+
+// sqlPriceExpression returns the SQL expression of the rental day price in the rate's currency, so price filters
+// can be applied to the same amounts that are returned.
+func (rate ExchangeRate) sqlPriceExpression() string {
+	if rate.Currency == BaseCurrency || rate.factor == nil {
+		return "price_per_day"
+	}
+	return fmt.Sprintf("ROUND(price_per_day * %s)", rate.factor.FloatString(20))
+}
+
+func convertRentalPrices(rentals []Rental, rate ExchangeRate) {
+	for position := range rentals {
+		rental := &rentals[position]
+		rental.Price = rate.ConvertPrice(rental.Price)
+		rental.Delivery.FeePerMile = rate.Convert(rental.Delivery.FeePerMile)
+		rental.Delivery.MinimumFee = rate.Convert(rental.Delivery.MinimumFee)
+		if rental.Delivery.Fee != nil {
+			fee := rate.Convert(*rental.Delivery.Fee)
+			rental.Delivery.Fee = &fee
+		}
+		if rental.Addons != nil {
+			addons := make([]Addon, len(rental.Addons))
+			for index, addon := range rental.Addons {
+				addon.Price = rate.Convert(addon.Price)
+				addons[index] = addon
+			}
+			rental.Addons = addons
+		}
+	}
+}
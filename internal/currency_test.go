@@ -0,0 +1,45 @@
+package internal
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestExchangeRateConvertShouldRoundToTheMinorUnitsOfTheCurrency(test *testing.T) {
+	var (
+		euro   = newExchangeRate("EUR", 0.92)
+		yen    = newExchangeRate("JPY", 151.2)
+		dinar  = newExchangeRate("KWD", 0.307)
+		dollar = newExchangeRate(BaseCurrency, 1)
+	)
+
+	assert.Equal(test, 15548, euro.Convert(16900), "Expected 169.00 USD to be 155.48 EUR")
+	assert.Equal(test, 1, euro.Convert(1), "Expected 0.0092 EUR to be rounded half up to one cent")
+	assert.Equal(test, 25553, yen.Convert(16900), "Expected 169.00 USD to be 25553 JPY without minor units")
+	assert.Equal(test, 51883, dinar.Convert(16900), "Expected 169.00 USD to be 51.883 KWD with three minor units")
+	assert.Equal(test, 16900, dollar.Convert(16900), "Expected the base currency to be left untouched")
+	assert.Equal(test, "ROUND(price_per_day * 0.92000000000000000000)", euro.sqlPriceExpression(), "Expected the exact factor to be used in SQL")
+}
+
+func TestLoadExchangeRatesShouldReplaceTheTable(test *testing.T) {
+	err := LoadExchangeRates("../exchange_rates.json")
+	if err != nil {
+		test.Fatalf("Error on loading exchange rates - %s", err.Error())
+	}
+
+	rate, err := GetExchangeRate("eur")
+	if err != nil {
+		test.Fatalf("Error on getting EUR exchange rate - %s", err.Error())
+	}
+	assert.Equal(test, "EUR", rate.Currency, "Expected currency codes to be case insensitive")
+
+	_, err = GetExchangeRate("XXX")
+	assert.Error(test, err, "Unknown currency should return error")
+	assert.Equal(test, "unsupported currency: XXX", err.Error(), "Correct error message is expected for unknown currency")
+
+	table, err := RefreshExchangeRates()
+	if err != nil {
+		test.Fatalf("Error on refreshing exchange rates - %s", err.Error())
+	}
+	assert.Equal(test, 0.92, table.Rates["EUR"], "Expected refreshed table to contain the EUR rate")
+}
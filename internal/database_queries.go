@@ -18,6 +18,17 @@ var selectSingleRentalQuery = `
 					   rentals.home_country,
 					   rentals.lat,
 					   rentals.lng,
+					   rentals.status,
+					   rentals.created,
+					   rentals.updated,
+					   rentals.delivery_radius,
+					   rentals.delivery_fee_per_mile,
+					   rentals.delivery_minimum_fee,
+					   rentals.min_nights,
+					   rentals.max_nights,
+					   rentals.check_in_days,
+					   rentals.notice_hours,
+					   rentals.max_advance_months,
 					   users.id AS user_id,
 					   users.first_name,
 					   users.last_name
@@ -43,8 +54,602 @@ var selectAllRentalsQuery = `
 					   rentals.home_country,
 					   rentals.lat,
 					   rentals.lng,
+					   rentals.status,
+					   rentals.created,
+					   rentals.updated,
+					   rentals.delivery_radius,
+					   rentals.delivery_fee_per_mile,
+					   rentals.delivery_minimum_fee,
+					   rentals.min_nights,
+					   rentals.max_nights,
+					   rentals.check_in_days,
+					   rentals.notice_hours,
+					   rentals.max_advance_months,
 					   users.id AS user_id,
 					   users.first_name,
 					   users.last_name
 				FROM rentals
 						LEFT JOIN users ON users.id = rentals.user_id`
+
+var publishedRentalsClause = `
+				WHERE rentals.status = 'published'`
+
+var selectAllAmenitiesQuery = `
+				SELECT amenities.id,
+					   amenities.key,
+					   amenities.name
+				FROM amenities
+				ORDER BY amenities.id;`
+
+var selectRentalsAmenitiesQuery = `
+				SELECT rental_amenities.rental_id,
+					   amenities.id,
+					   amenities.key,
+					   amenities.name
+				FROM rental_amenities
+						JOIN amenities ON amenities.id = rental_amenities.amenity_id
+				WHERE rental_amenities.rental_id = ANY($1)
+				ORDER BY rental_amenities.rental_id, amenities.id;`
+
+var selectRentalNameQuery = `
+				SELECT rentals.id,
+					   rentals.name
+				FROM rentals
+				WHERE rentals.id = :id;`
+
+var selectRentalBlockedRangesQuery = `
+				SELECT rental_blocked_ranges.id,
+					   rental_blocked_ranges.rental_id,
+					   rental_blocked_ranges.uid,
+					   rental_blocked_ranges.summary,
+					   rental_blocked_ranges.starts_on,
+					   rental_blocked_ranges.ends_on,
+					   rental_blocked_ranges.source,
+					   rental_blocked_ranges.updated
+				FROM rental_blocked_ranges
+				WHERE rental_blocked_ranges.rental_id = $1
+				ORDER BY rental_blocked_ranges.starts_on, rental_blocked_ranges.id;`
+
+var upsertBlockedRangeQuery = `
+				INSERT INTO rental_blocked_ranges (rental_id, uid, summary, starts_on, ends_on, source)
+				VALUES (:rental_id, :uid, :summary, :starts_on, :ends_on, :source)
+				ON CONFLICT (rental_id, uid) DO UPDATE
+					SET summary   = EXCLUDED.summary,
+						starts_on = EXCLUDED.starts_on,
+						ends_on   = EXCLUDED.ends_on,
+						source    = EXCLUDED.source,
+						updated   = now()
+				WHERE rental_blocked_ranges.source = 'ics'
+				  AND (rental_blocked_ranges.summary, rental_blocked_ranges.starts_on, rental_blocked_ranges.ends_on)
+						  IS DISTINCT FROM (EXCLUDED.summary, EXCLUDED.starts_on, EXCLUDED.ends_on)
+				RETURNING (xmax = 0) AS inserted;`
+
+var deleteBlockedRangeQuery = `
+				DELETE FROM rental_blocked_ranges
+				WHERE rental_blocked_ranges.rental_id = :rental_id
+				  AND rental_blocked_ranges.uid = :uid
+				  AND rental_blocked_ranges.source = 'ics';`
+
+var insertBlackoutQuery = `
+				INSERT INTO rental_blocked_ranges (rental_id, uid, summary, starts_on, ends_on, source)
+				VALUES (:rental_id, 'blackout-' || md5(random()::text || clock_timestamp()::text) || '@outdoorsy-api',
+						:summary, :starts_on, :ends_on, 'blackout')
+				RETURNING id, rental_id, uid, summary, starts_on, ends_on, source, updated;`
+
+var deleteBlackoutQuery = `
+				DELETE
+				FROM rental_blocked_ranges USING rentals
+				WHERE rental_blocked_ranges.id = :blackout_id
+				  AND rental_blocked_ranges.rental_id = :rental_id
+				  AND rental_blocked_ranges.source = 'blackout'
+				  AND rentals.id = rental_blocked_ranges.rental_id
+				  AND rentals.user_id = :owner_id;`
+
+var selectRentalBookingConflictQuery = `
+				SELECT EXISTS(SELECT 1
+							  FROM bookings
+							  WHERE bookings.rental_id = $1
+								AND bookings.status = 'confirmed'
+								AND bookings.starts_on < $3
+								AND bookings.ends_on > $2);`
+
+var selectOwnerRentalsForUpdateQuery = `
+				SELECT rentals.id,
+					   rentals.status,
+					   rentals.price_per_day,
+					   rentals.user_id
+				FROM rentals
+				WHERE rentals.id = ANY ($1)
+				  AND rentals.user_id = $2
+				ORDER BY rentals.id
+				FOR UPDATE;`
+
+var updateRentalPriceQuery = `
+				UPDATE rentals
+				SET price_per_day = $2,
+					updated       = now()
+				WHERE rentals.id = $1;`
+
+var selectUserQuery = `
+				SELECT users.id AS user_id,
+					   users.first_name,
+					   users.last_name
+				FROM users
+				WHERE users.id = :id;`
+
+var insertRentalQuery = `
+				INSERT INTO rentals (user_id, name, description, type, vehicle_make, vehicle_model, vehicle_year,
+									 vehicle_length, sleeps, primary_image_url, price_per_day, home_city, home_state,
+									 home_zip, home_country, lat, lng, status, created, updated)
+				VALUES (:user_id, :name, :description, :type, :make, :model, :year, :length, :sleeps,
+						:primary_image_url, :price_per_day, :city, :state, :zip, :country, :lat, :lng, :status, now(),
+						now())
+				RETURNING id;`
+
+var updateRentalStatusQuery = `
+				UPDATE rentals
+				SET status  = :to_status,
+					updated = now()
+				WHERE rentals.id = :rental_id
+				  AND rentals.status = :from_status
+				  AND (:owner_id = 0 OR rentals.user_id = :owner_id)
+				RETURNING rentals.id;`
+
+var selectRentalOwnershipQuery = `
+				SELECT rentals.id,
+					   rentals.status,
+					   rentals.user_id
+				FROM rentals
+				WHERE rentals.id = :id;`
+
+var insertStatusTransitionQuery = `
+				INSERT INTO rental_status_transitions (rental_id, from_status, to_status, actor, actor_id, reason)
+				VALUES (:rental_id, :from_status, :to_status, :actor, :actor_id, :reason);`
+
+var selectStatusTransitionsQuery = `
+				SELECT rental_status_transitions.id,
+					   rental_status_transitions.rental_id,
+					   COALESCE(rental_status_transitions.from_status, '') AS from_status,
+					   rental_status_transitions.to_status,
+					   rental_status_transitions.actor,
+					   rental_status_transitions.actor_id,
+					   rental_status_transitions.reason,
+					   rental_status_transitions.created
+				FROM rental_status_transitions
+				WHERE rental_status_transitions.rental_id = $1
+				ORDER BY rental_status_transitions.created, rental_status_transitions.id;`
+
+var moderationQueueClause = `
+				WHERE rentals.status = 'pending_review'
+				ORDER BY rentals.updated, rentals.id`
+
+var promoCodeColumns = `
+				SELECT promo_codes.id,
+					   promo_codes.code,
+					   promo_codes.discount_type,
+					   promo_codes.discount_value,
+					   promo_codes.min_nights,
+					   promo_codes.valid_from,
+					   promo_codes.valid_until,
+					   promo_codes.max_redemptions,
+					   promo_codes.max_redemptions_per_user,
+					   promo_codes.rental_types,
+					   promo_codes.rental_states,
+					   promo_codes.active,
+					   (SELECT COUNT(*)
+						FROM promo_code_redemptions
+						WHERE promo_code_redemptions.promo_code_id = promo_codes.id) AS redemptions
+				FROM promo_codes`
+
+var selectAllPromoCodesQuery = promoCodeColumns + `
+				ORDER BY promo_codes.id;`
+
+var selectPromoCodeQuery = promoCodeColumns + `
+				WHERE promo_codes.code = :code;`
+
+var selectPromoCodeForUpdateQuery = promoCodeColumns + `
+				WHERE promo_codes.code = $1
+				FOR UPDATE OF promo_codes;`
+
+var selectPromoCodeUsageQuery = `
+				SELECT COUNT(*)                                 AS total,
+					   COUNT(*) FILTER (WHERE user_id = :user_id) AS user_redemptions
+				FROM promo_code_redemptions
+				WHERE promo_code_redemptions.promo_code_id = :promo_code_id;`
+
+var insertPromoCodeQuery = `
+				INSERT INTO promo_codes (code, discount_type, discount_value, min_nights, valid_from, valid_until,
+										 max_redemptions, max_redemptions_per_user, rental_types, rental_states)
+				VALUES (:code, :discount_type, :discount_value, :min_nights, :valid_from, :valid_until,
+						:max_redemptions, :max_redemptions_per_user, :rental_types, :rental_states)
+				RETURNING code;`
+
+var deactivatePromoCodeQuery = `
+				UPDATE promo_codes
+				SET active  = false,
+					updated = now()
+				WHERE promo_codes.code = :code
+				RETURNING code;`
+
+var selectRentalForBookingQuery = `
+				SELECT rentals.status
+				FROM rentals
+				WHERE rentals.id = $1
+				FOR UPDATE;`
+
+var selectRentalAvailabilityConflictQuery = `
+				SELECT EXISTS(SELECT 1
+							  FROM bookings
+							  WHERE bookings.rental_id = $1
+								AND bookings.status = 'confirmed'
+								AND bookings.starts_on < $3
+								AND bookings.ends_on > $2)
+						   OR EXISTS(SELECT 1
+									 FROM rental_blocked_ranges
+									 WHERE rental_blocked_ranges.rental_id = $1
+									   AND rental_blocked_ranges.starts_on < $3
+									   AND rental_blocked_ranges.ends_on > $2);`
+
+var insertBookingQuery = `
+				INSERT INTO bookings (rental_id, user_id, starts_on, ends_on, nightly_price, discount, delivery_fee,
+									  total, promo_code_id)
+				VALUES (:rental_id, :user_id, :starts_on, :ends_on, :nightly_price, :discount, :delivery_fee, :total,
+						:promo_code_id)
+				RETURNING id;`
+
+var insertPromoCodeRedemptionQuery = `
+				INSERT INTO promo_code_redemptions (promo_code_id, booking_id, user_id, discount)
+				VALUES (:promo_code_id, :booking_id, :user_id, :discount);`
+
+var selectBookingQuery = `
+				SELECT bookings.id,
+					   bookings.rental_id,
+					   bookings.user_id,
+					   bookings.starts_on,
+					   bookings.ends_on,
+					   bookings.status,
+					   bookings.nightly_price,
+					   bookings.discount,
+					   bookings.delivery_fee,
+					   bookings.total,
+					   COALESCE(promo_codes.code, '') AS promo_code,
+					   bookings.created,
+					   bookings.updated
+				FROM bookings
+						LEFT JOIN promo_codes ON promo_codes.id = bookings.promo_code_id
+				WHERE bookings.id = :id;`
+
+var cancelBookingQuery = `
+				UPDATE bookings
+				SET status  = 'cancelled',
+					updated = now()
+				WHERE bookings.id = :id
+				  AND bookings.user_id = :user_id
+				  AND bookings.status = 'confirmed'
+				RETURNING bookings.id;`
+
+var selectBookingOwnershipQuery = `
+				SELECT bookings.id,
+					   bookings.user_id,
+					   bookings.status
+				FROM bookings
+				WHERE bookings.id = :id;`
+
+var deletePromoCodeRedemptionQuery = `
+				DELETE FROM promo_code_redemptions
+				WHERE promo_code_redemptions.booking_id = :id;`
+
+var selectRentalBookingsQuery = `
+				SELECT bookings.id,
+					   bookings.rental_id,
+					   bookings.starts_on,
+					   bookings.ends_on,
+					   bookings.updated
+				FROM bookings
+				WHERE bookings.rental_id = $1
+				  AND bookings.status = 'confirmed'
+				ORDER BY bookings.starts_on, bookings.id;`
+
+var selectOwnerRentalsQuery = `
+				SELECT rentals.id,
+					   rentals.name
+				FROM rentals
+				WHERE rentals.user_id = $1
+				ORDER BY rentals.id;`
+
+var selectOwnerBookingsQuery = `
+				SELECT bookings.id,
+					   bookings.rental_id,
+					   bookings.starts_on,
+					   bookings.ends_on,
+					   bookings.status,
+					   bookings.nightly_price,
+					   bookings.discount,
+					   bookings.total
+				FROM bookings
+						 JOIN rentals ON rentals.id = bookings.rental_id
+				WHERE rentals.user_id = $1
+				  AND bookings.starts_on < $3
+				  AND bookings.ends_on > $2
+				ORDER BY bookings.starts_on, bookings.id;`
+
+var selectOwnerBlockedRangesQuery = `
+				SELECT rental_blocked_ranges.id,
+					   rental_blocked_ranges.rental_id,
+					   rental_blocked_ranges.starts_on,
+					   rental_blocked_ranges.ends_on
+				FROM rental_blocked_ranges
+						 JOIN rentals ON rentals.id = rental_blocked_ranges.rental_id
+				WHERE rentals.user_id = $1
+				  AND rental_blocked_ranges.starts_on < $3
+				  AND rental_blocked_ranges.ends_on > $2;`
+
+var upsertRentalViewCountsQuery = `
+				INSERT INTO rental_view_counts (rental_id, day, kind, count)
+				SELECT counts.rental_id, counts.day, counts.kind, counts.count
+				FROM unnest($1::integer[], $2::date[], $3::text[], $4::bigint[]) AS counts (rental_id, day, kind, count)
+				WHERE EXISTS(SELECT 1 FROM rentals WHERE rentals.id = counts.rental_id)
+				ON CONFLICT (rental_id, day, kind) DO UPDATE
+					SET count = rental_view_counts.count + EXCLUDED.count;`
+
+// trendingRentalScoresQuery ranks the published rentals by their views within the window, every view weighted by
+// half for every half-life it is old. The distance condition is appended by the caller.
+var trendingRentalScoresQuery = `
+				SELECT rental_view_counts.rental_id,
+					   SUM(rental_view_counts.count) AS views,
+					   SUM(rental_view_counts.count *
+						   POWER(0.5, ((now() AT TIME ZONE 'UTC')::date - rental_view_counts.day) / $2::float)) AS score
+				FROM rental_view_counts
+						 JOIN rentals ON rentals.id = rental_view_counts.rental_id
+				WHERE rental_view_counts.kind = 'view'
+				  AND rental_view_counts.day > (now() AT TIME ZONE 'UTC')::date - $1::integer
+				  AND rentals.status = 'published' %s
+				GROUP BY rental_view_counts.rental_id
+				ORDER BY score DESC, rental_view_counts.rental_id
+				LIMIT $3;`
+
+var selectOwnerViewCountsQuery = `
+				SELECT rental_view_counts.rental_id,
+					   rental_view_counts.day,
+					   rental_view_counts.count
+				FROM rental_view_counts
+						 JOIN rentals ON rentals.id = rental_view_counts.rental_id
+				WHERE rentals.user_id = $1
+				  AND rental_view_counts.kind = 'view'
+				  AND rental_view_counts.day >= $2
+				  AND rental_view_counts.day < $3;`
+
+var rentalsByIdsClause = `
+				WHERE rentals.id = ANY ($1)`
+
+// comparableRentalsClause selects the published rentals of the same type with a similar capacity and length. The
+// distance condition is appended by the caller.
+var comparableRentalsClause = `
+				WHERE rentals.id <> $1
+				  AND rentals.status = 'published'
+				  AND rentals.type = $2
+				  AND rentals.sleeps BETWEEN $3 AND $4
+				  AND rentals.vehicle_length BETWEEN $5 AND $6
+				  AND %s <= $7
+				ORDER BY rentals.price_per_day, rentals.id`
+
+var selectRentalMileageQuery = `
+				SELECT rentals.included_miles_per_day,
+					   rentals.mileage_overage_fee,
+					   rentals.mpg
+				FROM rentals
+				WHERE rentals.id = :id;`
+
+var updateRentalDeliveryQuery = `
+				UPDATE rentals
+				SET delivery_radius       = :radius,
+					delivery_fee_per_mile = :fee_per_mile,
+					delivery_minimum_fee  = :minimum_fee,
+					updated               = now()
+				WHERE rentals.id = :rental_id
+				  AND rentals.user_id = :owner_id
+				RETURNING rentals.id;`
+
+var updateRentalBookingRulesQuery = `
+				UPDATE rentals
+				SET min_nights         = :min_nights,
+					max_nights         = :max_nights,
+					check_in_days      = :check_in_days,
+					notice_hours       = :notice_hours,
+					max_advance_months = :max_advance_months,
+					updated            = now()
+				WHERE rentals.id = :rental_id
+				  AND rentals.user_id = :owner_id
+				RETURNING rentals.id;`
+
+var selectRentalAddonsQuery = `
+				SELECT rental_addons.id,
+					   rental_addons.rental_id,
+					   rental_addons.name,
+					   rental_addons.description,
+					   rental_addons.pricing_mode,
+					   rental_addons.price,
+					   rental_addons.max_quantity,
+					   rental_addons.available
+				FROM rental_addons
+				WHERE rental_addons.rental_id = $1
+				ORDER BY rental_addons.id;`
+
+var insertAddonQuery = `
+				INSERT INTO rental_addons (rental_id, name, description, pricing_mode, price, max_quantity, available)
+				SELECT rentals.id, :name, :description, :pricing_mode, :price, :max_quantity, :available
+				FROM rentals
+				WHERE rentals.id = :rental_id
+				  AND rentals.user_id = :owner_id
+				RETURNING rental_addons.id;`
+
+var updateAddonQuery = `
+				UPDATE rental_addons
+				SET name         = :name,
+					description  = :description,
+					pricing_mode = :pricing_mode,
+					price        = :price,
+					max_quantity = :max_quantity,
+					available    = :available,
+					updated      = now()
+				FROM rentals
+				WHERE rental_addons.id = :addon_id
+				  AND rental_addons.rental_id = :rental_id
+				  AND rentals.id = rental_addons.rental_id
+				  AND rentals.user_id = :owner_id
+				RETURNING rental_addons.id;`
+
+var deleteAddonQuery = `
+				DELETE FROM rental_addons
+				USING rentals
+				WHERE rental_addons.id = :addon_id
+				  AND rental_addons.rental_id = :rental_id
+				  AND rentals.id = rental_addons.rental_id
+				  AND rentals.user_id = :owner_id;`
+
+var selectAddonQuery = `
+				SELECT rental_addons.id,
+					   rental_addons.rental_id,
+					   rental_addons.name,
+					   rental_addons.description,
+					   rental_addons.pricing_mode,
+					   rental_addons.price,
+					   rental_addons.max_quantity,
+					   rental_addons.available
+				FROM rental_addons
+				WHERE rental_addons.id = :id;`
+
+var insertBookingAddonQuery = `
+				INSERT INTO booking_addons (booking_id, addon_id, name, quantity, amount)
+				VALUES (:booking_id, :addon_id, :name, :quantity, :amount);`
+
+var selectUsersByIdsQuery = `
+				SELECT users.id AS user_id,
+					   users.first_name,
+					   users.last_name
+				FROM users
+				WHERE users.id = ANY ($1);`
+
+var publishedRentalsByOwnersClause = `
+				WHERE rentals.user_id = ANY ($1)
+				  AND rentals.status = 'published'
+				ORDER BY rentals.id`
+
+var selectRentalEventsAfterQuery = `
+				SELECT rental_events.id,
+					   rental_events.rental_id,
+					   rental_events.kind,
+					   rental_events.created
+				FROM rental_events
+				WHERE rental_events.id > $1
+				ORDER BY rental_events.id;`
+
+var selectOldestRentalEventIdQuery = `
+				SELECT COALESCE(MIN(rental_events.id), 0)
+				FROM rental_events;`
+
+var webhookColumns = `
+				SELECT webhooks.id,
+					   webhooks.url,
+					   webhooks.event_types,
+					   webhooks.active,
+					   webhooks.created,
+					   webhooks.updated
+				FROM webhooks`
+
+var selectAllWebhooksQuery = webhookColumns + `
+				ORDER BY webhooks.id;`
+
+var selectWebhookQuery = webhookColumns + `
+				WHERE webhooks.id = :id;`
+
+var insertWebhookQuery = `
+				INSERT INTO webhooks (url, event_types, secret, active)
+				VALUES (:url, :event_types, :secret, :active)
+				RETURNING id;`
+
+var updateWebhookQuery = `
+				UPDATE webhooks
+				SET url         = :url,
+					event_types = :event_types,
+					secret      = CASE WHEN :secret = '' THEN webhooks.secret ELSE :secret END,
+					active      = :active,
+					updated     = now()
+				WHERE webhooks.id = :id
+				RETURNING webhooks.id;`
+
+var deleteWebhookQuery = `
+				DELETE FROM webhooks
+				WHERE webhooks.id = :id;`
+
+var selectWebhookDeliveriesQuery = `
+				SELECT webhook_deliveries.id,
+					   webhook_deliveries.webhook_id,
+					   webhook_deliveries.event_type,
+					   webhook_deliveries.payload,
+					   webhook_deliveries.status,
+					   webhook_deliveries.attempts,
+					   webhook_deliveries.next_attempt,
+					   webhook_deliveries.response_status,
+					   webhook_deliveries.error,
+					   webhook_deliveries.created,
+					   webhook_deliveries.updated
+				FROM webhook_deliveries
+				WHERE webhook_deliveries.webhook_id = $1
+				  AND ($2 = '' OR webhook_deliveries.status = $2)
+				ORDER BY webhook_deliveries.id DESC
+				LIMIT $3 OFFSET $4;`
+
+// claimWebhookDeliveriesQuery takes the due deliveries and pushes their next attempt past the lease, so other
+// instances skip them while they are sent, and a delivery whose instance stops is retried after the lease. The
+// deliveries of inactive webhooks stay pending until their webhook is activated again.
+var claimWebhookDeliveriesQuery = `
+				UPDATE webhook_deliveries
+				SET next_attempt = now() + $2::integer * interval '1 second'
+				FROM webhooks
+				WHERE webhook_deliveries.id IN (SELECT due.id
+												FROM webhook_deliveries due
+														 JOIN webhooks active ON active.id = due.webhook_id
+												WHERE due.status = 'pending'
+												  AND due.next_attempt <= now()
+												  AND active.active
+												ORDER BY due.next_attempt
+												LIMIT $1 FOR UPDATE OF due SKIP LOCKED)
+				  AND webhooks.id = webhook_deliveries.webhook_id
+				RETURNING webhook_deliveries.id,
+						  webhook_deliveries.webhook_id,
+						  webhook_deliveries.event_type,
+						  webhook_deliveries.payload,
+						  webhook_deliveries.attempts,
+						  webhooks.url,
+						  webhooks.secret;`
+
+var updateWebhookDeliveryQuery = `
+				UPDATE webhook_deliveries
+				SET status          = :status,
+					attempts        = :attempts,
+					next_attempt    = :next_attempt,
+					response_status = :response_status,
+					error           = :error,
+					updated         = now()
+				WHERE webhook_deliveries.id = :id;`
+
+// selectRentalChangesQuery pages the rentals and the tombstones of the deleted ones by (updated, id). Changes more
+// recent than the settle time are left for the next page, as transactions still running may write older ones.
+var selectRentalChangesQuery = `
+				SELECT changes.id,
+					   changes.updated,
+					   changes.deleted
+				FROM (SELECT rentals.id,
+							 rentals.updated,
+							 false AS deleted
+					  FROM rentals
+					  UNION ALL
+					  SELECT rental_tombstones.rental_id,
+							 rental_tombstones.deleted,
+							 true
+					  FROM rental_tombstones) changes
+				WHERE (changes.updated, changes.id) > ($1, $2)
+				  AND changes.updated <= now() - $3::integer * interval '1 second'
+				ORDER BY changes.updated, changes.id
+				LIMIT $4;`
@@ -0,0 +1,115 @@
+package internal
+
+import (
+	"fmt"
+	"math"
+	"outdoorsy-api/database"
+	"outdoorsy-api/utils"
+	"strconv"
+	"strings"
+)
+
+const maximumDeliveryRadius = 500
+
+// UpdateRentalDelivery replaces the delivery settings of the owner's rental. Radius is in miles and a zero radius
+// means the rental is not delivered, fees are in base currency cents. If the settings are not valid -
+// failedValidation will be set to true and descriptive validation error will be returned.
+func UpdateRentalDelivery(ownerId int, rentalId int, settings Delivery) (rental Rental, failedValidation bool, err error) {
+	var updatedId int
+
+	if err = validateDelivery(settings); err != nil {
+		failedValidation = true
+		return
+	}
+
+	err = database.GetSingleRecordNamedQuery(&updatedId, updateRentalDeliveryQuery, map[string]interface{}{
+		"rental_id":    rentalId,
+		"owner_id":     ownerId,
+		"radius":       settings.Radius,
+		"fee_per_mile": settings.FeePerMile,
+		"minimum_fee":  settings.MinimumFee,
+	})
+	if err != nil {
+		return
+	}
+
+	rental, err = GetASingleRental(rentalId)
+	return
+}
+
+// fee returns the delivery fee for the distance in miles - the per-mile fee, but never less than the minimum fee.
+func (delivery Delivery) fee(distance float64) int {
+	return int(math.Max(float64(delivery.MinimumFee), math.Round(distance*float64(delivery.FeePerMile))))
+}
+
+// deliveryDistance returns the great-circle distance in miles between the rental and the delivery point. The
+// point is already validated by utils.ValidateParameters.
+func deliveryDistance(rental Rental, deliveryTo string) float64 {
+	coordinates := strings.Split(deliveryTo, ",")
+	lat, _ := strconv.ParseFloat(coordinates[0], 64)
+	lng, _ := strconv.ParseFloat(coordinates[1], 64)
+	return utils.GreatCircleDistance(rental.Lat, rental.Lng, lat, lng, utils.EarthRadiusMiles)
+}
+
+// sqlDeliveryClause returns the SQL condition matching the rentals which deliver to the point, comparing the
+// distance with the radius in the unit system.
+func sqlDeliveryClause(deliveryTo string, units unitSystem) string {
+	coordinates := strings.Split(deliveryTo, ",")
+	return fmt.Sprintf(" rentals.delivery_radius > 0 AND %s <= %s",
+		units.sqlDistanceExpression(coordinates[0], coordinates[1]), units.sqlMilesExpression("rentals.delivery_radius"))
+}
+
+// applyDelivery sets the delivery distance of every rental from the point in the unit system and the delivery fee,
+// in base currency cents. The fee is always charged by the mile.
+func applyDelivery(rentals []Rental, deliveryTo string, units unitSystem) {
+	if deliveryTo == "" {
+		return
+	}
+
+	for position := range rentals {
+		rental := &rentals[position]
+		distance := deliveryDistance(*rental, deliveryTo)
+		fee := rental.Delivery.fee(distance)
+		convertedDistance := units.convertDistance(distance)
+		rental.Delivery.Distance = &convertedDistance
+		rental.Delivery.Fee = &fee
+	}
+}
+
+// addDelivery adds the delivery line of the rental to the point to the quote, with the distance in the unit
+// system. A rental which doesn't deliver there returns the localized reason.
+func (quote *Quote) addDelivery(rental Rental, deliveryTo string, rate ExchangeRate, units unitSystem) error {
+	distance := deliveryDistance(rental, deliveryTo)
+	if rental.Delivery.Radius <= 0 {
+		return utils.NewLocalizedError("delivery_not_offered")
+	}
+	if distance > rental.Delivery.Radius {
+		code := "delivery_out_of_range"
+		if units.name == utils.UnitsMetric {
+			code = "delivery_out_of_range_metric"
+		}
+		return utils.NewLocalizedError(code, units.convertDistance(distance), units.convertDistance(rental.Delivery.Radius))
+	}
+
+	fee := rate.Convert(rental.Delivery.fee(distance))
+	quote.addLine(QuoteLine{
+		Type:        "delivery",
+		Description: fmt.Sprintf("Delivery over %.2f %s", units.convertDistance(distance), units.distanceName),
+		Quantity:    1,
+		UnitAmount:  fee,
+		Amount:      fee,
+	})
+	return nil
+}
+
+func validateDelivery(settings Delivery) error {
+	switch {
+	case settings.Radius < 0 || settings.Radius > maximumDeliveryRadius:
+		return utils.NewLocalizedError("field_invalid", "radius")
+	case settings.FeePerMile < 0:
+		return utils.NewLocalizedError("field_invalid", "fee_per_mile")
+	case settings.MinimumFee < 0:
+		return utils.NewLocalizedError("field_invalid", "minimum_fee")
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package internal
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/url"
+	"outdoorsy-api/utils"
+	"testing"
+	"time"
+)
+
+func TestAddDeliveryShouldChargeThePerMileFeeButNotLessThanTheMinimum(test *testing.T) {
+	var (
+		rental   = Rental{IdRental: 1, Price: Price{Day: 10000}, Delivery: Delivery{Radius: 100, FeePerMile: 250, MinimumFee: 5000}}
+		dollar   = newExchangeRate(BaseCurrency, 1)
+		imperial = getUnitSystem(utils.UnitsImperial)
+		from     = time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+	)
+
+	quote := newQuote(rental, from, from.AddDate(0, 0, 2), dollar)
+	if err := quote.addDelivery(rental, "0,1", dollar, imperial); err != nil {
+		test.Fatalf("Error on adding delivery - %s", err.Error())
+	}
+	assert.Equal(test, 17273, quote.Lines[1].Amount, "Expected 69.09 miles at 2.50 USD")
+
+	quote = newQuote(rental, from, from.AddDate(0, 0, 2), dollar)
+	if err := quote.addDelivery(rental, "0,0.1", dollar, imperial); err != nil {
+		test.Fatalf("Error on adding delivery - %s", err.Error())
+	}
+	assert.Equal(test, 5000, quote.Lines[1].Amount, "Expected the minimum fee for short deliveries")
+
+	err := quote.addDelivery(rental, "0,2", dollar, imperial)
+	assert.Error(test, err, "Delivery beyond the radius should return error")
+	assert.Equal(test, "delivery point is 138.19 miles away, the rental delivers within 100 miles", err.Error(), "Correct error message is expected")
+
+	quote = newQuote(rental, from, from.AddDate(0, 0, 2), dollar)
+	if err = quote.addDelivery(rental, "0,1", dollar, getUnitSystem(utils.UnitsMetric)); err != nil {
+		test.Fatalf("Error on adding delivery - %s", err.Error())
+	}
+	assert.Equal(test, "Delivery over 111.20 km", quote.Lines[1].Description, "Expected the distance in kilometers")
+	assert.Equal(test, 17273, quote.Lines[1].Amount, "Expected the fee of the distance in miles")
+
+	err = quote.addDelivery(rental, "0,2", dollar, getUnitSystem(utils.UnitsMetric))
+	assert.Equal(test, "delivery point is 222.39 km away, the rental delivers within 160.93 km", err.Error(), "Expected the distances in kilometers")
+
+	rental.Delivery.Radius = 0
+	err = quote.addDelivery(rental, "0,0.1", dollar, imperial)
+	assert.Equal(test, "rental does not offer delivery", err.Error(), "Correct error message is expected")
+}
+
+func TestGetMultipleRentalsWithDeliveryToShouldReturnOnlyRentalsDeliveringThere(test *testing.T) {
+	defer setupTest(test)()
+
+	rentals, _, err := GetMultipleRentals(url.Values{"delivery_to": {"33.7,-117.9"}, "sort": {"id"}})
+	if err != nil {
+		test.Fatalf("Error on getting rentals - %s", err.Error())
+	}
+
+	assert.NotEmpty(test, rentals, "Expected rentals delivering near Costa Mesa")
+	for _, rental := range rentals {
+		assert.NotNil(test, rental.Delivery.Fee, "Expected the delivery fee of every rental")
+		assert.LessOrEqual(test, *rental.Delivery.Distance, rental.Delivery.Radius, "Expected only rentals delivering within their radius")
+		assert.GreaterOrEqual(test, *rental.Delivery.Fee, rental.Delivery.MinimumFee, "Expected the fee not to be less than the minimum")
+	}
+
+	metric, _, err := GetMultipleRentals(url.Values{"delivery_to": {"33.7,-117.9"}, "sort": {"id"}, "units": {utils.UnitsMetric}})
+	if err != nil {
+		test.Fatalf("Error on getting rentals in metric units - %s", err.Error())
+	}
+	if assert.Len(test, metric, len(rentals), "Expected the same rentals whatever the units") {
+		for position, rental := range metric {
+			assert.InDelta(test, rentals[position].Delivery.Radius*1.609, rental.Delivery.Radius, rental.Delivery.Radius/100, "Expected the radius in kilometers")
+			assert.InDelta(test, *rentals[position].Delivery.Distance*1.609, *rental.Delivery.Distance, 0.1+*rental.Delivery.Distance/100, "Expected the distance in kilometers")
+			assert.Equal(test, *rentals[position].Delivery.Fee, *rental.Delivery.Fee, "Expected the fee of the distance in miles")
+		}
+	}
+}
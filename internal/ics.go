@@ -0,0 +1,320 @@
+package internal
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	icsDateFormat      = "20060102"
+	icsDateTimeFormat  = "20060102T150405"
+	icsMaxLineOctets   = 75
+	icsProductId       = "-//Outdoorsy API//Rental Calendar//EN"
+	icsStatusCancelled = "CANCELLED"
+)
+
+// calendarEvent is a single VEVENT reduced to what matters for availability - the whole days it blocks.
+// EndsOn is exclusive, the same way DTEND of an all-day event is.
+type calendarEvent struct {
+	UID       string
+	Summary   string
+	StartsOn  time.Time
+	EndsOn    time.Time
+	Stamp     time.Time
+	Cancelled bool
+}
+
+// writeICS serializes the events as an RFC 5545 calendar. Lines are CRLF terminated and folded at 75 octets.
+func writeICS(writer io.Writer, name string, events []calendarEvent) error {
+	buffered := bufio.NewWriter(writer)
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:" + icsProductId,
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:" + escapeICSText(name),
+	}
+
+	for _, event := range events {
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			"UID:"+escapeICSText(event.UID),
+			"DTSTAMP:"+event.Stamp.UTC().Format(icsDateTimeFormat)+"Z",
+			"DTSTART;VALUE=DATE:"+event.StartsOn.Format(icsDateFormat),
+			"DTEND;VALUE=DATE:"+event.EndsOn.Format(icsDateFormat),
+			"SUMMARY:"+escapeICSText(event.Summary),
+			"TRANSP:OPAQUE",
+			"END:VEVENT",
+		)
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	for _, line := range lines {
+		if _, err := buffered.WriteString(foldICSLine(line)); err != nil {
+			return err
+		}
+	}
+	return buffered.Flush()
+}
+
+// parseICS extracts the events of an RFC 5545 calendar. Nested components like VALARM are ignored.
+func parseICS(reader io.Reader) (events []calendarEvent, err error) {
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return
+	}
+
+	var (
+		components []string
+		event      *calendarEvent
+		properties map[string]icsProperty
+	)
+
+	for number, line := range unfoldICSLines(string(content)) {
+		if line == "" {
+			continue
+		}
+
+		property, parseErr := parseICSProperty(line)
+		if parseErr != nil {
+			return nil, fmt.Errorf("calendar line %d: %s", number+1, parseErr.Error())
+		}
+
+		switch property.Name {
+		case "BEGIN":
+			components = append(components, strings.ToUpper(property.Value))
+			if strings.ToUpper(property.Value) == "VEVENT" {
+				event = &calendarEvent{}
+				properties = make(map[string]icsProperty)
+			}
+			continue
+		case "END":
+			if len(components) == 0 || components[len(components)-1] != strings.ToUpper(property.Value) {
+				return nil, fmt.Errorf("calendar line %d: unexpected END:%s", number+1, property.Value)
+			}
+			components = components[:len(components)-1]
+			if strings.ToUpper(property.Value) == "VEVENT" {
+				if err = buildCalendarEvent(event, properties); err != nil {
+					return nil, err
+				}
+				events = append(events, *event)
+				event = nil
+			}
+			continue
+		}
+
+		if event != nil && components[len(components)-1] == "VEVENT" {
+			properties[property.Name] = property
+		}
+	}
+
+	if len(components) > 0 {
+		return nil, errors.New("calendar is not terminated with END:VCALENDAR")
+	}
+	return
+}
+
+type icsProperty struct {
+	Name   string
+	Params map[string]string
+	Value  string
+}
+
+func parseICSProperty(line string) (property icsProperty, err error) {
+	var (
+		inQuotes  bool
+		separator = -1
+	)
+
+	for index, character := range line {
+		if character == '"' {
+			inQuotes = !inQuotes
+		} else if character == ':' && !inQuotes {
+			separator = index
+			break
+		}
+	}
+
+	if separator < 0 {
+		err = errors.New("property is missing a value separator")
+		return
+	}
+
+	nameAndParams := strings.Split(line[:separator], ";")
+	property.Name = strings.ToUpper(nameAndParams[0])
+	property.Value = line[separator+1:]
+	property.Params = make(map[string]string, len(nameAndParams)-1)
+	for _, param := range nameAndParams[1:] {
+		key, value, _ := strings.Cut(param, "=")
+		property.Params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+	}
+	return
+}
+
+func buildCalendarEvent(event *calendarEvent, properties map[string]icsProperty) (err error) {
+	uid, found := properties["UID"]
+	if !found || uid.Value == "" {
+		return errors.New("calendar event is missing UID")
+	}
+	event.UID = unescapeICSText(uid.Value)
+	event.Summary = unescapeICSText(properties["SUMMARY"].Value)
+	event.Cancelled = strings.ToUpper(properties["STATUS"].Value) == icsStatusCancelled
+
+	start, found := properties["DTSTART"]
+	if !found {
+		return fmt.Errorf("calendar event %s is missing DTSTART", event.UID)
+	}
+
+	startTime, startIsDate, err := parseICSTime(start)
+	if err != nil {
+		return fmt.Errorf("calendar event %s has invalid DTSTART: %s", event.UID, err.Error())
+	}
+	event.StartsOn = truncateToDate(startTime)
+
+	if end, found := properties["DTEND"]; found {
+		endTime, _, err := parseICSTime(end)
+		if err != nil {
+			return fmt.Errorf("calendar event %s has invalid DTEND: %s", event.UID, err.Error())
+		}
+		event.EndsOn = endOfRange(endTime)
+	} else if duration, found := properties["DURATION"]; found {
+		length, err := parseICSDuration(duration.Value)
+		if err != nil {
+			return fmt.Errorf("calendar event %s has invalid DURATION: %s", event.UID, err.Error())
+		}
+		event.EndsOn = endOfRange(startTime.Add(length))
+	} else if startIsDate {
+		event.EndsOn = event.StartsOn.AddDate(0, 0, 1)
+	}
+
+	if !event.EndsOn.After(event.StartsOn) {
+		event.EndsOn = event.StartsOn.AddDate(0, 0, 1)
+	}
+	return
+}
+
+// parseICSTime parses DATE and DATE-TIME values. UTC and TZID qualified times are kept in their own zone, so
+// the calendar day is the one the event was written for.
+func parseICSTime(property icsProperty) (value time.Time, isDate bool, err error) {
+	if property.Params["VALUE"] == "DATE" || len(property.Value) == len(icsDateFormat) {
+		value, err = time.Parse(icsDateFormat, property.Value)
+		return value, true, err
+	}
+
+	if strings.HasSuffix(property.Value, "Z") {
+		value, err = time.Parse(icsDateTimeFormat, strings.TrimSuffix(property.Value, "Z"))
+		return
+	}
+
+	location := time.UTC
+	if tzid := property.Params["TZID"]; tzid != "" {
+		if loaded, loadErr := time.LoadLocation(tzid); loadErr == nil {
+			location = loaded
+		}
+	}
+	value, err = time.ParseInLocation(icsDateTimeFormat, property.Value, location)
+	return
+}
+
+// parseICSDuration supports the week, day and time designators of RFC 5545 durations, e.g. P1W, P3D, PT12H.
+func parseICSDuration(value string) (duration time.Duration, err error) {
+	value = strings.TrimPrefix(strings.TrimPrefix(value, "+"), "P")
+	if value == "" || strings.HasPrefix(value, "-") {
+		return 0, errors.New("unsupported duration")
+	}
+
+	var (
+		number   strings.Builder
+		timePart bool
+		units    = map[bool]map[rune]time.Duration{
+			false: {'W': 7 * 24 * time.Hour, 'D': 24 * time.Hour},
+			true:  {'H': time.Hour, 'M': time.Minute, 'S': time.Second},
+		}
+	)
+
+	for _, character := range value {
+		if character >= '0' && character <= '9' {
+			number.WriteRune(character)
+			continue
+		}
+		if character == 'T' {
+			timePart = true
+			continue
+		}
+
+		unit, found := units[timePart][character]
+		if !found || number.Len() == 0 {
+			return 0, errors.New("unsupported duration")
+		}
+		amount, _ := strconv.Atoi(number.String())
+		duration += time.Duration(amount) * unit
+		number.Reset()
+	}
+
+	if number.Len() > 0 {
+		return 0, errors.New("unsupported duration")
+	}
+	return
+}
+
+func truncateToDate(value time.Time) time.Time {
+	return time.Date(value.Year(), value.Month(), value.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// endOfRange converts an exclusive end time to an exclusive end date - a range ending during a day blocks
+// that day as well.
+func endOfRange(value time.Time) time.Time {
+	date := truncateToDate(value)
+	if value.Hour() != 0 || value.Minute() != 0 || value.Second() != 0 {
+		date = date.AddDate(0, 0, 1)
+	}
+	return date
+}
+
+func unfoldICSLines(content string) []string {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	content = strings.ReplaceAll(content, "\n ", "")
+	content = strings.ReplaceAll(content, "\n\t", "")
+	return strings.Split(content, "\n")
+}
+
+func foldICSLine(line string) string {
+	var builder strings.Builder
+	limit := icsMaxLineOctets
+
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		builder.WriteString(line[:cut])
+		builder.WriteString("\r\n ")
+		line = line[cut:]
+		// the leading space of a continuation line counts towards its length
+		limit = icsMaxLineOctets - 1
+	}
+
+	builder.WriteString(line)
+	builder.WriteString("\r\n")
+	return builder.String()
+}
+
+var (
+	icsTextEscaper   = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+	icsTextUnescaper = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+)
+
+func escapeICSText(value string) string {
+	return icsTextEscaper.Replace(value)
+}
+
+func unescapeICSText(value string) string {
+	return icsTextUnescaper.Replace(value)
+}
@@ -0,0 +1,183 @@
+package internal
+
+import (
+	"bytes"
+	"database/sql"
+	"github.com/stretchr/testify/assert"
+	"outdoorsy-api/database"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteICSShouldProduceAFeedThatParsesBack(test *testing.T) {
+	var (
+		buffer bytes.Buffer
+		events = []calendarEvent{
+			{
+				UID:      "blocked-1@outdoorsy-api",
+				Summary:  "Maintenance, oil change; tyres " + strings.Repeat("and more ", 10),
+				StartsOn: time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC),
+				EndsOn:   time.Date(2026, 5, 4, 0, 0, 0, 0, time.UTC),
+				Stamp:    time.Date(2026, 4, 1, 10, 0, 0, 0, time.UTC),
+			},
+		}
+	)
+
+	err := writeICS(&buffer, "Daisy", events)
+	if err != nil {
+		test.Fatalf("Error on writing calendar - %s", err.Error())
+	}
+
+	for _, line := range strings.Split(strings.TrimSuffix(buffer.String(), "\r\n"), "\r\n") {
+		assert.LessOrEqual(test, len(line), 75, "Expected every line to be folded at 75 octets")
+	}
+	assert.Contains(test, buffer.String(), "DTSTART;VALUE=DATE:20260501\r\n", "Expected all-day start date")
+
+	parsed, err := parseICS(&buffer)
+	if err != nil {
+		test.Fatalf("Error on parsing written calendar - %s", err.Error())
+	}
+
+	assert.Equal(test, 1, len(parsed), "Expected the single event to be parsed back")
+	assert.Equal(test, events[0].UID, parsed[0].UID, "Expected UID to survive the round trip")
+	assert.Equal(test, events[0].Summary, parsed[0].Summary, "Expected escaped summary to survive the round trip")
+	assert.Equal(test, events[0].StartsOn, parsed[0].StartsOn, "Expected start date to survive the round trip")
+	assert.Equal(test, events[0].EndsOn, parsed[0].EndsOn, "Expected end date to survive the round trip")
+}
+
+func TestParseICSShouldHandleDateTimesDurationsAndCancellations(test *testing.T) {
+	calendar := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"BEGIN:VEVENT",
+		"UID:timed@example.com",
+		"DTSTART;TZID=America/Denver:20260601T150000",
+		"DTEND;TZID=America/Denver:20260603T110000",
+		"SUMMARY:Reserved",
+		"BEGIN:VALARM",
+		"UID:alarm-should-be-ignored",
+		"END:VALARM",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:duration@exam",
+		" ple.com",
+		"DTSTART;VALUE=DATE:20260610",
+		"DURATION:P1W",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:cancelled@example.com",
+		"DTSTART:20260620T000000Z",
+		"STATUS:CANCELLED",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+
+	events, err := parseICS(strings.NewReader(calendar))
+	if err != nil {
+		test.Fatalf("Error on parsing calendar - %s", err.Error())
+	}
+
+	assert.Equal(test, 3, len(events), "Expected three events to be parsed")
+	assert.Equal(test, "timed@example.com", events[0].UID, "Expected nested VALARM properties to be ignored")
+	assert.Equal(test, time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC), events[0].StartsOn, "Expected the local start day")
+	assert.Equal(test, time.Date(2026, 6, 4, 0, 0, 0, 0, time.UTC), events[0].EndsOn, "Expected a partially used end day to be blocked")
+	assert.Equal(test, "duration@example.com", events[1].UID, "Expected folded lines to be unfolded")
+	assert.Equal(test, time.Date(2026, 6, 17, 0, 0, 0, 0, time.UTC), events[1].EndsOn, "Expected duration to define the end")
+	assert.True(test, events[2].Cancelled, "Expected cancelled status to be recognised")
+	assert.Equal(test, time.Date(2026, 6, 21, 0, 0, 0, 0, time.UTC), events[2].EndsOn, "Expected an event without end to block a single day")
+}
+
+func TestParseICSShouldReturnDescriptiveErrorInCaseOfInvalidCalendar(test *testing.T) {
+	_, err := parseICS(strings.NewReader("BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nDTSTART:20260101\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"))
+	assert.Error(test, err, "Event without UID should fail the import")
+	assert.Equal(test, "calendar event is missing UID", err.Error(), "Correct error message is expected")
+
+	_, err = parseICS(strings.NewReader("BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:1\r\nDTSTART:20260101\r\nEND:VEVENT\r\n"))
+	assert.Error(test, err, "Unterminated calendar should fail the import")
+}
+
+func TestImportRentalCalendarShouldBeIdempotent(test *testing.T) {
+	defer setupTest(test)()
+
+	rental := createTestDraftRental(test, "Calendar import van")
+	feed := func(events ...string) string {
+		return "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n" + strings.Join(events, "") + "END:VCALENDAR\r\n"
+	}
+	var (
+		maintenance = "BEGIN:VEVENT\r\nUID:maintenance@example.com\r\nDTSTART;VALUE=DATE:20310501\r\nDTEND;VALUE=DATE:20310504\r\nSUMMARY:Maintenance\r\nEND:VEVENT\r\n"
+		reserved    = "BEGIN:VEVENT\r\nUID:reserved@example.com\r\nDTSTART;VALUE=DATE:20310610\r\nDTEND;VALUE=DATE:20310612\r\nSUMMARY:Reserved\r\nEND:VEVENT\r\n"
+		rescheduled = "BEGIN:VEVENT\r\nUID:reserved@example.com\r\nDTSTART;VALUE=DATE:20310611\r\nDTEND;VALUE=DATE:20310613\r\nSUMMARY:Reserved\r\nEND:VEVENT\r\n"
+		cancelled   = "BEGIN:VEVENT\r\nUID:maintenance@example.com\r\nDTSTART;VALUE=DATE:20310501\r\nSTATUS:CANCELLED\r\nEND:VEVENT\r\n"
+	)
+
+	steps := []struct {
+		calendar string
+		result   CalendarImportResult
+		message  string
+	}{
+		{feed(maintenance, reserved), CalendarImportResult{Created: 2}, "Expected every event to be created on the first import"},
+		{feed(maintenance, reserved), CalendarImportResult{Unchanged: 2}, "Expected nothing to change when the same feed is imported again"},
+		{feed(maintenance, rescheduled), CalendarImportResult{Updated: 1, Unchanged: 1}, "Expected only the rescheduled event to be updated"},
+		{feed(cancelled, rescheduled), CalendarImportResult{Unchanged: 1, Removed: 1}, "Expected the cancelled event to be removed"},
+		{feed(cancelled, rescheduled), CalendarImportResult{Unchanged: 1}, "Expected nothing to change when the cancellation is imported again"},
+	}
+	for _, step := range steps {
+		result, failedValidation, err := ImportRentalCalendar(1, rental.IdRental, strings.NewReader(step.calendar))
+		if err != nil || failedValidation {
+			test.Fatalf("Error on importing calendar - %v", err)
+		}
+		assert.Equal(test, step.result, result, step.message)
+	}
+}
+
+func TestImportRentalCalendarShouldOnlyImportIntoTheOwnersRentals(test *testing.T) {
+	defer setupTest(test)()
+
+	rental := createTestDraftRental(test, "Calendar owner van")
+	calendar := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VEVENT\r\nUID:stranger@example.com\r\nDTSTART;VALUE=DATE:20310701\r\nDTEND;VALUE=DATE:20310703\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+
+	_, failedValidation, err := ImportRentalCalendar(2, rental.IdRental, strings.NewReader(calendar))
+	assert.False(test, failedValidation, "Expected the feed to be valid")
+	assert.ErrorIs(test, err, sql.ErrNoRows, "Expected the rental of another owner not to be found")
+
+	var ranges []BlockedRange
+	if err = database.GetMultipleRecords(&ranges, selectRentalBlockedRangesQuery, rental.IdRental); err != nil {
+		test.Fatalf("Error on getting blocked ranges - %s", err.Error())
+	}
+	assert.Empty(test, ranges, "Expected nothing to be imported into the rental of another owner")
+}
+
+func TestImportRentalCalendarShouldNotChangeTheBlackoutsOfAnExportedFeed(test *testing.T) {
+	defer setupTest(test)()
+
+	rental := createTestDraftRental(test, "Calendar blackout van")
+	blackout, _, err := CreateRentalBlackout(1, rental.IdRental, BlackoutInput{From: "2031-08-01", To: "2031-08-04", Reason: "Engine repair"})
+	if err != nil {
+		test.Fatalf("Error on creating blackout - %s", err.Error())
+	}
+
+	exported, err := ExportRentalCalendar(rental.IdRental)
+	if err != nil {
+		test.Fatalf("Error on exporting calendar - %s", err.Error())
+	}
+	cancelled := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VEVENT\r\nUID:" + blackout.UID + "\r\nDTSTART;VALUE=DATE:20310801\r\nSTATUS:CANCELLED\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+
+	for _, calendar := range []string{string(exported), cancelled} {
+		result, failedValidation, err := ImportRentalCalendar(1, rental.IdRental, strings.NewReader(calendar))
+		if err != nil || failedValidation {
+			test.Fatalf("Error on importing calendar - %v", err)
+		}
+		assert.Equal(test, CalendarImportResult{Skipped: 1}, result, "Expected the exported blackout to be skipped")
+	}
+
+	var ranges []BlockedRange
+	if err = database.GetMultipleRecords(&ranges, selectRentalBlockedRangesQuery, rental.IdRental); err != nil {
+		test.Fatalf("Error on getting blocked ranges - %s", err.Error())
+	}
+	if assert.Len(test, ranges, 1, "Expected only the blackout to block the rental") {
+		assert.Equal(test, "blackout", ranges[0].Source, "Expected the blackout to stay a blackout")
+		assert.Equal(test, "Engine repair", ranges[0].Summary, "Expected the blackout to keep its reason")
+	}
+	assert.Nil(test, DeleteRentalBlackout(1, rental.IdRental, blackout.Id), "Expected the owner to still remove the blackout")
+}
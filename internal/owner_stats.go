@@ -0,0 +1,283 @@
+package internal
+
+import (
+	"math"
+	"net/url"
+	"outdoorsy-api/database"
+	"outdoorsy-api/utils"
+	"time"
+)
+
+// GetOwnerStats builds the dashboard statistics of the owner's rentals between the from and to dates of the
+// parameters - for every rental and for all of them, in total and in day, week or month buckets. Nights are counted
+// within the range, bookings and cancellations by the night the trip starts and the conversion rate is the share of
+// the detail views which ended in a confirmed booking. Amounts are in the requested currency.
+// If the parameters are not valid - failedValidation will be set to true and descriptive validation error will be
+// returned.
+func GetOwnerStats(ownerId int, params url.Values) (stats OwnerStats, failedValidation bool, err error) {
+	var (
+		owner    User
+		rentals  []RentalStats
+		bookings []Booking
+		ranges   []BlockedRange
+		views    []rentalViewCount
+	)
+
+	if err = utils.ValidateStatsParameters(params); err != nil {
+		failedValidation = true
+		return
+	}
+
+	rate, err := GetExchangeRate(params.Get("currency"))
+	if err != nil {
+		failedValidation = true
+		return
+	}
+
+	err = database.GetSingleRecordNamedQuery(&owner, selectUserQuery, map[string]interface{}{"id": ownerId})
+	if err != nil {
+		return
+	}
+
+	from, _ := time.Parse(utils.DateFormat, params.Get("from"))
+	to, _ := time.Parse(utils.DateFormat, params.Get("to"))
+
+	if err = database.GetMultipleRecords(&rentals, selectOwnerRentalsQuery, ownerId); err != nil {
+		return
+	}
+	if err = database.GetMultipleRecords(&bookings, selectOwnerBookingsQuery, ownerId, from, to); err != nil {
+		return
+	}
+	if err = database.GetMultipleRecords(&ranges, selectOwnerBlockedRangesQuery, ownerId, from, to); err != nil {
+		return
+	}
+	if err = database.GetMultipleRecords(&views, selectOwnerViewCountsQuery, ownerId, from, to); err != nil {
+		return
+	}
+
+	granularity := params.Get("granularity")
+	if granularity == "" {
+		granularity = utils.GranularityDay
+	}
+
+	stats = newOwnerStats(from, to, granularity, rentals, bookings, ranges, views)
+	stats.convert(rate)
+	return
+}
+
+// newOwnerStats counts the nights, revenue, bookings, cancellations and views of every rental in the buckets of the
+// range and sums them up for all of the rentals. The revenue of a booking is spread evenly over its nights, so a
+// stay crossing the range or a bucket is counted only for its nights inside.
+func newOwnerStats(from time.Time, to time.Time, granularity string, rentals []RentalStats, bookings []Booking, ranges []BlockedRange, views []rentalViewCount) (stats OwnerStats) {
+	var (
+		buckets   = statsBuckets(from, to, granularity)
+		days      = int(to.Sub(from).Hours() / 24)
+		dayBucket = make([]int, days)
+		positions = make(map[int]int, len(rentals))
+		blocked   = make(map[int][]bool, len(rentals))
+		booked    = make(map[int][]bool, len(rentals))
+	)
+
+	for position, bucket := range buckets {
+		start, _ := time.Parse(utils.DateFormat, bucket.From)
+		end, _ := time.Parse(utils.DateFormat, bucket.To)
+		for day := dayIndex(from, start); day < dayIndex(from, end); day++ {
+			dayBucket[day] = position
+		}
+	}
+
+	stats = OwnerStats{
+		From:        from.Format(utils.DateFormat),
+		To:          to.Format(utils.DateFormat),
+		Granularity: granularity,
+		Currency:    BaseCurrency,
+		Buckets:     copyStatsBuckets(buckets),
+		Rentals:     make([]RentalStats, len(rentals)),
+	}
+	stats.Totals.Views = new(int)
+	for bucket := range stats.Buckets {
+		stats.Buckets[bucket].Views = new(int)
+	}
+	for position, rental := range rentals {
+		rental.Buckets = copyStatsBuckets(buckets)
+		for bucket := range rental.Buckets {
+			rental.Buckets[bucket].Views = new(int)
+		}
+		stats.Rentals[position] = rental
+		positions[rental.RentalId] = position
+		blocked[rental.RentalId] = make([]bool, days)
+		booked[rental.RentalId] = make([]bool, days)
+	}
+
+	for _, blockedRange := range ranges {
+		// a rental created between the two reads has ranges but no stats
+		if _, found := positions[blockedRange.RentalId]; !found {
+			continue
+		}
+		for day := max(0, dayIndex(from, blockedRange.StartsOn)); day < min(days, dayIndex(from, blockedRange.EndsOn)); day++ {
+			blocked[blockedRange.RentalId][day] = true
+		}
+	}
+
+	for _, viewCount := range views {
+		position, found := positions[viewCount.RentalId]
+		if day := dayIndex(from, viewCount.Day); found && day >= 0 && day < days {
+			*stats.Rentals[position].Buckets[dayBucket[day]].Views += viewCount.Count
+		}
+	}
+
+	for _, booking := range bookings {
+		position, found := positions[booking.RentalId]
+		if !found {
+			continue
+		}
+		rental := &stats.Rentals[position]
+
+		if start := dayIndex(from, booking.StartsOn); start >= 0 && start < days {
+			metrics := &rental.Buckets[dayBucket[start]].StatsMetrics
+			metrics.Bookings++
+			if booking.Status == "cancelled" {
+				metrics.Cancellations++
+			}
+		}
+		if booking.Status == "cancelled" {
+			continue
+		}
+
+		nights := dayIndex(booking.StartsOn, booking.EndsOn)
+		nightlyAmount := booking.NightlyPrice*nights - booking.Discount
+		for night := 0; night < nights; night++ {
+			day := dayIndex(from, booking.StartsOn) + night
+			if day < 0 || day >= days {
+				continue
+			}
+			booked[booking.RentalId][day] = true
+			metrics := &rental.Buckets[dayBucket[day]].StatsMetrics
+			metrics.GrossRevenue += evenShare(booking.Total, nights, night)
+			metrics.nightlyRevenue += evenShare(nightlyAmount, nights, night)
+		}
+	}
+
+	for position := range stats.Rentals {
+		rental := &stats.Rentals[position]
+		for day := 0; day < days; day++ {
+			metrics := &rental.Buckets[dayBucket[day]].StatsMetrics
+			switch {
+			case booked[rental.RentalId][day]:
+				metrics.BookedNights++
+				metrics.AvailableNights++
+			case !blocked[rental.RentalId][day]:
+				metrics.AvailableNights++
+			}
+		}
+
+		for bucket := range rental.Buckets {
+			rental.Totals.add(rental.Buckets[bucket].StatsMetrics)
+			stats.Buckets[bucket].add(rental.Buckets[bucket].StatsMetrics)
+			rental.Buckets[bucket].finalize()
+		}
+		stats.Totals.add(rental.Totals)
+		rental.Totals.finalize()
+	}
+
+	for bucket := range stats.Buckets {
+		stats.Buckets[bucket].finalize()
+	}
+	stats.Totals.finalize()
+	return
+}
+
+// statsBuckets splits the range into day, week (starting on Monday) or month buckets. The first and the last
+// bucket are cut to the range.
+func statsBuckets(from time.Time, to time.Time, granularity string) (buckets []StatsBucket) {
+	for start := from; start.Before(to); {
+		end := nextBucketStart(start, granularity)
+		if end.After(to) {
+			end = to
+		}
+		buckets = append(buckets, StatsBucket{From: start.Format(utils.DateFormat), To: end.Format(utils.DateFormat)})
+		start = end
+	}
+	return
+}
+
+func nextBucketStart(day time.Time, granularity string) time.Time {
+	switch granularity {
+	case utils.GranularityWeek:
+		return day.AddDate(0, 0, 7-(int(day.Weekday())+6)%7)
+	case utils.GranularityMonth:
+		return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, 1, 0)
+	}
+	return day.AddDate(0, 0, 1)
+}
+
+func copyStatsBuckets(buckets []StatsBucket) []StatsBucket {
+	return append(make([]StatsBucket, 0, len(buckets)), buckets...)
+}
+
+// dayIndex returns the number of days from the start to the day, negative for days before the start.
+func dayIndex(start time.Time, day time.Time) int {
+	return int(math.Round(day.Sub(start).Hours() / 24))
+}
+
+// evenShare returns the part of the amount falling on the index when it is split into count parts. The parts differ
+// by at most one cent and always add up to the amount.
+func evenShare(amount int, count int, index int) int {
+	return amount*(index+1)/count - amount*index/count
+}
+
+func (metrics *StatsMetrics) add(other StatsMetrics) {
+	metrics.BookedNights += other.BookedNights
+	metrics.AvailableNights += other.AvailableNights
+	metrics.GrossRevenue += other.GrossRevenue
+	metrics.nightlyRevenue += other.nightlyRevenue
+	metrics.Bookings += other.Bookings
+	metrics.Cancellations += other.Cancellations
+	if other.Views != nil {
+		views := *other.Views
+		if metrics.Views != nil {
+			views += *metrics.Views
+		}
+		metrics.Views = &views
+	}
+}
+
+// finalize derives the rates of the counted metrics.
+func (metrics *StatsMetrics) finalize() {
+	metrics.OccupancyRate = ratio(metrics.BookedNights, metrics.AvailableNights)
+	metrics.CancellationRate = ratio(metrics.Cancellations, metrics.Bookings)
+	if metrics.BookedNights > 0 {
+		metrics.AverageNightlyRate = int(math.Round(float64(metrics.nightlyRevenue) / float64(metrics.BookedNights)))
+	}
+	if metrics.Views != nil {
+		conversionRate := ratio(metrics.Bookings-metrics.Cancellations, *metrics.Views)
+		metrics.ConversionRate = &conversionRate
+	}
+}
+
+func (stats *OwnerStats) convert(rate ExchangeRate) {
+	convert := func(metrics *StatsMetrics) {
+		metrics.GrossRevenue = rate.Convert(metrics.GrossRevenue)
+		metrics.AverageNightlyRate = rate.Convert(metrics.AverageNightlyRate)
+	}
+
+	stats.Currency = rate.Currency
+	convert(&stats.Totals)
+	for bucket := range stats.Buckets {
+		convert(&stats.Buckets[bucket].StatsMetrics)
+	}
+	for position := range stats.Rentals {
+		convert(&stats.Rentals[position].Totals)
+		for bucket := range stats.Rentals[position].Buckets {
+			convert(&stats.Rentals[position].Buckets[bucket].StatsMetrics)
+		}
+	}
+}
+
+// ratio returns the part of the whole rounded to four decimals, zero for an empty whole.
+func ratio(part int, whole int) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return math.Round(float64(part)/float64(whole)*10000) / 10000
+}
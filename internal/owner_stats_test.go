@@ -0,0 +1,86 @@
+package internal
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestNewOwnerStatsShouldCountNightsRevenueAndCancellationsInBuckets(test *testing.T) {
+	var (
+		date = func(value string) time.Time {
+			parsed, _ := time.Parse("2006-01-02", value)
+			return parsed
+		}
+		rentals  = []RentalStats{{RentalId: 6, Name: "Van"}, {RentalId: 11, Name: "Trailer"}}
+		bookings = []Booking{
+			{RentalId: 6, StartsOn: date("2026-05-30"), EndsOn: date("2026-06-03"), Status: "confirmed", NightlyPrice: 10000, Total: 40000},
+			{RentalId: 6, StartsOn: date("2026-06-07"), EndsOn: date("2026-06-10"), Status: "confirmed", NightlyPrice: 9000, Discount: 3000, Total: 30001},
+			{RentalId: 11, StartsOn: date("2026-06-02"), EndsOn: date("2026-06-05"), Status: "cancelled", NightlyPrice: 5000, Total: 15000},
+		}
+		ranges = []BlockedRange{{RentalId: 11, StartsOn: date("2026-06-10"), EndsOn: date("2026-06-20")}}
+		views  = []rentalViewCount{
+			{RentalId: 6, Day: date("2026-06-01"), Count: 30},
+			{RentalId: 6, Day: date("2026-06-09"), Count: 10},
+			{RentalId: 11, Day: date("2026-06-02"), Count: 40},
+			{RentalId: 11, Day: date("2026-06-20"), Count: 99},
+		}
+	)
+
+	stats := newOwnerStats(date("2026-06-01"), date("2026-06-15"), "week", rentals, bookings, ranges, views)
+
+	van := stats.Rentals[0].Totals
+	assert.Equal(test, 5, van.BookedNights, "Expected only the nights inside the range")
+	assert.Equal(test, 14, van.AvailableNights, "Expected every night of an unblocked rental to be available")
+	assert.Equal(test, 0.3571, van.OccupancyRate, "Expected booked nights of available nights")
+	assert.Equal(test, 20000+30001, van.GrossRevenue, "Expected the revenue of the nights inside the range")
+	assert.Equal(test, 8800, van.AverageNightlyRate, "Expected the discounted nightly price per booked night")
+	assert.Equal(test, 1, van.Bookings, "Expected only the bookings starting inside the range")
+
+	trailer := stats.Rentals[1].Totals
+	assert.Equal(test, 9, trailer.AvailableNights, "Expected blocked nights not to be available")
+	assert.Equal(test, 1.0, trailer.CancellationRate, "Expected cancelled bookings of all bookings")
+
+	assert.Len(test, stats.Buckets, 2, "Expected a bucket for every week")
+	assert.Equal(test, "2026-06-08", stats.Buckets[0].To, "Expected weeks to start on Monday")
+	assert.Equal(test, 3, stats.Buckets[0].BookedNights, "Expected the booked nights of the first week")
+	assert.Equal(test, 30000, stats.Buckets[0].GrossRevenue, "Expected the revenue of the first week")
+	assert.Equal(test, 20001, stats.Buckets[1].GrossRevenue, "Expected the revenue of the second week")
+	assert.Equal(test, 9, stats.Buckets[1].AvailableNights, "Expected the available nights of the second week")
+	assert.Equal(test, 23, stats.Totals.AvailableNights, "Expected the available nights of all rentals")
+	assert.Equal(test, 0.5, stats.Totals.CancellationRate, "Expected the cancellation rate of all rentals")
+	assert.Equal(test, 80, *stats.Totals.Views, "Expected only the views inside the range")
+	assert.Equal(test, 30, *stats.Rentals[0].Buckets[0].Views, "Expected the views of the first week")
+	assert.Equal(test, 0.0125, *stats.Totals.ConversionRate, "Expected confirmed bookings of views")
+
+	buckets := statsBuckets(date("2026-05-20"), date("2026-07-03"), "month")
+	assert.Equal(test, []StatsBucket{
+		{From: "2026-05-20", To: "2026-06-01"},
+		{From: "2026-06-01", To: "2026-07-01"},
+		{From: "2026-07-01", To: "2026-07-03"},
+	}, buckets, "Expected month buckets cut to the range")
+}
+
+func TestNewOwnerStatsShouldIgnoreRangesOfRentalsWithoutStats(test *testing.T) {
+	from := time.Date(2026, time.June, 1, 0, 0, 0, 0, time.UTC)
+	rentals := []RentalStats{{RentalId: 6, Name: "Van"}}
+	ranges := []BlockedRange{{RentalId: 99, StartsOn: from, EndsOn: from.AddDate(0, 0, 3)}}
+
+	stats := newOwnerStats(from, from.AddDate(0, 0, 7), "day", rentals, nil, ranges, nil)
+
+	assert.Equal(test, 7, stats.Totals.AvailableNights, "Expected the range of a rental read after the others to be ignored")
+}
+
+func TestGetOwnerStatsShouldReturnDescriptiveErrorInCaseOfInvalidParameters(test *testing.T) {
+	testCases := map[string]url.Values{
+		"from and to dates are required":            {"from": {"2026-01-01"}},
+		"granularity should be day, week or month":  {"from": {"2026-01-01"}, "to": {"2026-02-01"}, "granularity": {"year"}},
+		"from and to can be at most 731 days apart": {"from": {"2024-01-01"}, "to": {"2026-02-01"}},
+	}
+	for message, params := range testCases {
+		_, failedValidation, err := GetOwnerStats(1, params)
+		assert.True(test, failedValidation, "Invalid parameters should fail validation")
+		assert.Equal(test, message, err.Error(), "Correct error message is expected")
+	}
+}
@@ -0,0 +1,68 @@
+package internal
+
+import (
+	"github.com/lib/pq"
+	"net/url"
+	"outdoorsy-api/database"
+	"outdoorsy-api/utils"
+)
+
+// GetUsersByIds retrieves the users with a single query, keyed by their id. Missing users are left out.
+func GetUsersByIds(ids []int) (users map[int]User, err error) {
+	var records []User
+
+	err = database.GetMultipleRecords(&records, selectUsersByIdsQuery, pq.Array(toInt64s(ids)))
+	if err != nil {
+		return
+	}
+
+	users = make(map[int]User, len(records))
+	for _, user := range records {
+		users[user.Id] = user
+	}
+	return
+}
+
+// GetPublishedRentalsByOwners retrieves the published rentals of all the owners with a single query, keyed by the
+// owner id and presented according to the currency and units parameters. Owners without published rentals get an
+// empty list. If the parameters are not valid - failedValidation will be set to true.
+func GetPublishedRentalsByOwners(ownerIds []int, params url.Values) (rentals map[int][]Rental, failedValidation bool, err error) {
+	var records []Rental
+
+	presentationParams := url.Values{"currency": {params.Get("currency")}, "units": {params.Get("units")}}
+	if err = utils.ValidateParameters(presentationParams); err != nil {
+		failedValidation = true
+		return
+	}
+
+	options, err := getRentalsQueryOptions(presentationParams)
+	if err != nil {
+		failedValidation = true
+		return
+	}
+
+	err = database.GetMultipleRecords(&records, selectAllRentalsQuery+publishedRentalsByOwnersClause, pq.Array(toInt64s(ownerIds)))
+	if err != nil {
+		return
+	}
+	if err = presentRentals(records, presentationParams, options); err != nil {
+		return
+	}
+
+	rentals = make(map[int][]Rental, len(ownerIds))
+	for _, ownerId := range ownerIds {
+		rentals[ownerId] = make([]Rental, 0)
+	}
+	for _, rental := range records {
+		rentals[rental.User.Id] = append(rentals[rental.User.Id], rental)
+	}
+	return
+}
+
+func toInt64s(values []int) []int64 {
+	converted := make([]int64, 0, len(values))
+	for _, value := range values {
+		converted = append(converted, int64(value))
+	}
+	return converted
+}
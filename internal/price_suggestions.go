@@ -0,0 +1,132 @@
+package internal
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"outdoorsy-api/database"
+	"outdoorsy-api/utils"
+	"sort"
+	"strconv"
+)
+
+const (
+	defaultComparablesRadius = 100
+	comparableSleepsRange    = 1
+	comparableLengthRange    = 0.2
+	minimumComparables       = 3
+	maximumVehicleLength     = 100
+)
+
+// GetPriceSuggestion compares the price of the rental with comparable listings - published rentals of the same
+// type, sleeping one person more or less, up to 20% shorter or longer and within the radius (100 by default, in
+// the selected units). The statistics and the suggestion, the comparables median rounded to whole units, are
+// left empty when there are fewer than 3 comparables. If the parameters are not valid - failedValidation will be
+// set to true and descriptive validation error will be returned.
+func GetPriceSuggestion(id int, params url.Values) (suggestion PriceSuggestion, failedValidation bool, err error) {
+	var comparables []Rental
+
+	if err = utils.ValidatePriceSuggestionParameters(params); err != nil {
+		failedValidation = true
+		return
+	}
+
+	rate, err := GetExchangeRate(params.Get("currency"))
+	if err != nil {
+		failedValidation = true
+		return
+	}
+
+	units := getUnitSystem(params.Get("units"))
+	radius := float64(defaultComparablesRadius)
+	if params.Get("radius") != "" {
+		radius, _ = strconv.ParseFloat(params.Get("radius"), 64)
+	}
+
+	rental, err := GetASingleRental(id)
+	if err != nil {
+		return
+	}
+
+	minLength, maxLength := 0.0, float64(maximumVehicleLength)
+	if rental.Length > 0 {
+		minLength, maxLength = rental.Length*(1-comparableLengthRange), rental.Length*(1+comparableLengthRange)
+	}
+
+	lat := strconv.FormatFloat(rental.Lat, 'f', -1, 64)
+	lng := strconv.FormatFloat(rental.Lng, 'f', -1, 64)
+	query := selectAllRentalsQuery + fmt.Sprintf(comparableRentalsClause, units.sqlDistanceExpression(lat, lng))
+
+	err = database.GetMultipleRecords(&comparables, query, rental.IdRental, rental.Type,
+		rental.Sleeps-comparableSleepsRange, rental.Sleeps+comparableSleepsRange, minLength, maxLength, radius)
+	if err != nil {
+		return
+	}
+	if err = attachAmenities(comparables); err != nil {
+		return
+	}
+
+	prices := make([]int, 0, len(comparables))
+	for _, comparable := range comparables {
+		prices = append(prices, comparable.Price.Day)
+	}
+
+	suggestion = PriceSuggestion{
+		RentalId:    rental.IdRental,
+		Currency:    rate.Currency,
+		Units:       units.name,
+		Radius:      radius,
+		Price:       rate.Convert(rental.Price.Day),
+		Comparables: comparables,
+	}
+
+	if len(prices) >= minimumComparables {
+		sort.Ints(prices)
+		percentile := percentileRank(prices, rental.Price.Day)
+		p25 := rate.Convert(percentileValue(prices, 0.25))
+		median := rate.Convert(percentileValue(prices, 0.5))
+		p75 := rate.Convert(percentileValue(prices, 0.75))
+		suggested := roundToWholeUnits(median, rate)
+
+		suggestion.Percentile = &percentile
+		suggestion.P25 = &p25
+		suggestion.Median = &median
+		suggestion.P75 = &p75
+		suggestion.SuggestedPrice = &suggested
+	}
+
+	convertRentalPrices(suggestion.Comparables, rate)
+	applyUnits(suggestion.Comparables, units, lat+","+lng)
+	return
+}
+
+// percentileValue returns the percentile of the sorted prices, interpolating linearly between the closest ranks.
+func percentileValue(sortedPrices []int, percentile float64) int {
+	position := percentile * float64(len(sortedPrices)-1)
+	lower := int(math.Floor(position))
+	upper := int(math.Ceil(position))
+	fraction := position - float64(lower)
+	return int(math.Round(float64(sortedPrices[lower]) + fraction*float64(sortedPrices[upper]-sortedPrices[lower])))
+}
+
+// percentileRank returns the percentage of the sorted prices below the price, counting equal prices as half,
+// rounded to one decimal place.
+func percentileRank(sortedPrices []int, price int) float64 {
+	var below, equal int
+	for _, comparable := range sortedPrices {
+		if comparable < price {
+			below++
+		} else if comparable == price {
+			equal++
+		}
+	}
+
+	rank := (float64(below) + float64(equal)/2) / float64(len(sortedPrices)) * 100
+	return math.Round(rank*10) / 10
+}
+
+// roundToWholeUnits rounds an amount in minor units of the rate's currency to whole units of the currency.
+func roundToWholeUnits(amount int, rate ExchangeRate) int {
+	unit := int(math.Pow10(rate.MinorUnits))
+	return int(math.Round(float64(amount)/float64(unit))) * unit
+}
@@ -0,0 +1,40 @@
+package internal
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/url"
+	"testing"
+)
+
+func TestPricePercentilesShouldInterpolateBetweenRanks(test *testing.T) {
+	prices := []int{10000, 12000, 15000, 20000}
+
+	assert.Equal(test, 11500, percentileValue(prices, 0.25), "Expected p25 between the first two prices")
+	assert.Equal(test, 13500, percentileValue(prices, 0.5), "Expected the median between the middle prices")
+	assert.Equal(test, 16250, percentileValue(prices, 0.75), "Expected p75 between the last two prices")
+	assert.Equal(test, 62.5, percentileRank(prices, 15000), "Expected equal prices to count as half")
+	assert.Equal(test, 100.0, percentileRank(prices, 25000), "Expected the most expensive price to be the 100th percentile")
+	assert.Equal(test, 13500, roundToWholeUnits(13549, newExchangeRate(BaseCurrency, 1)), "Expected rounding to whole dollars")
+	assert.Equal(test, 25553, roundToWholeUnits(25553, newExchangeRate("JPY", 151.2)), "Expected currencies without minor units to be left untouched")
+}
+
+func TestGetPriceSuggestionShouldOnlyCompareSimilarRentals(test *testing.T) {
+	defer setupTest(test)()
+
+	suggestion, failedValidation, err := GetPriceSuggestion(1, url.Values{"radius": {"1000"}})
+	if err != nil || failedValidation {
+		test.Fatalf("Error on suggesting rental price - %v", err)
+	}
+
+	rental, _ := GetASingleRental(1)
+	assert.Equal(test, rental.Price.Day, suggestion.Price, "Expected the current price of the rental")
+	for _, comparable := range suggestion.Comparables {
+		assert.NotEqual(test, 1, comparable.IdRental, "Expected the rental not to be compared with itself")
+		assert.Equal(test, rental.Type, comparable.Type, "Expected comparables of the same type")
+		assert.InDelta(test, rental.Sleeps, comparable.Sleeps, 1, "Expected comparables of a similar capacity")
+		assert.LessOrEqual(test, *comparable.Distance, 1000.0, "Expected comparables within the radius")
+	}
+	if len(suggestion.Comparables) >= minimumComparables {
+		assert.NotNil(test, suggestion.SuggestedPrice, "Expected a suggestion with enough comparables")
+	}
+}
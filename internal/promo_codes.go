@@ -0,0 +1,187 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"outdoorsy-api/database"
+	"outdoorsy-api/utils"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	DiscountPercentage = "percentage"
+	DiscountFixed      = "fixed"
+
+	uniqueViolationCode = "23505"
+)
+
+var promoCodePattern = regexp.MustCompile(`^[A-Z0-9_-]{3,32}$`)
+
+// CreatePromoCode creates a new active promo code. Codes are case-insensitive and stored uppercase. If the input
+// is not valid - failedValidation will be set to true and descriptive validation error will be returned, an
+// already existing code returns a promo_code_exists error.
+func CreatePromoCode(input PromoCode) (promoCode PromoCode, failedValidation bool, err error) {
+	var code string
+
+	input.Code = normalizePromoCode(input.Code)
+	input.RentalTypes = normalizeRestrictions(input.RentalTypes, strings.ToLower)
+	input.RentalStates = normalizeRestrictions(input.RentalStates, strings.ToUpper)
+
+	if err = validatePromoCodeInput(input); err != nil {
+		failedValidation = true
+		return
+	}
+
+	err = database.GetSingleRecordNamedQuery(&code, insertPromoCodeQuery, map[string]interface{}{
+		"code":                     input.Code,
+		"discount_type":            input.DiscountType,
+		"discount_value":           input.DiscountValue,
+		"min_nights":               input.MinNights,
+		"valid_from":               input.ValidFrom,
+		"valid_until":              input.ValidUntil,
+		"max_redemptions":          input.MaxRedemptions,
+		"max_redemptions_per_user": input.MaxRedemptionsPerUser,
+		"rental_types":             input.RentalTypes,
+		"rental_states":            input.RentalStates,
+	})
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolationCode {
+			err = utils.NewLocalizedError("promo_code_exists", input.Code)
+		}
+		return
+	}
+
+	promoCode, err = GetPromoCode(code)
+	return
+}
+
+// GetPromoCodes retrieves every promo code together with the number of its redemptions.
+func GetPromoCodes() (promoCodes []PromoCode, err error) {
+	err = database.GetMultipleRecords(&promoCodes, selectAllPromoCodesQuery)
+	return
+}
+
+func GetPromoCode(code string) (promoCode PromoCode, err error) {
+	err = database.GetSingleRecordNamedQuery(&promoCode, selectPromoCodeQuery, map[string]interface{}{"code": normalizePromoCode(code)})
+	return
+}
+
+// DeactivatePromoCode stops the promo code from being applied. Redemptions made so far are kept.
+func DeactivatePromoCode(code string) (promoCode PromoCode, err error) {
+	err = database.GetSingleRecordNamedQuery(&code, deactivatePromoCodeQuery, map[string]interface{}{"code": normalizePromoCode(code)})
+	if err != nil {
+		return
+	}
+
+	promoCode, err = GetPromoCode(code)
+	return
+}
+
+// getPromoCodeUsage counts the redemptions of the promo code, overall and by the user.
+func getPromoCodeUsage(promoCodeId int, userId int) (usage promoCodeUsage, err error) {
+	err = database.GetSingleRecordNamedQuery(&usage, selectPromoCodeUsageQuery, map[string]interface{}{
+		"promo_code_id": promoCodeId,
+		"user_id":       userId,
+	})
+	return
+}
+
+// checkPromoCodeEligibility returns the localized reason the promo code can not be applied to the trip, or nil
+// if it can. The user is 0 if not known, which is enough only for codes without a per-user cap.
+func checkPromoCodeEligibility(promoCode PromoCode, rental Rental, nights int, userId int, usage promoCodeUsage, now time.Time) error {
+	switch {
+	case !promoCode.Active:
+		return utils.NewLocalizedError("promo_code_inactive", promoCode.Code)
+	case promoCode.ValidFrom != nil && now.Before(*promoCode.ValidFrom):
+		return utils.NewLocalizedError("promo_code_not_started", promoCode.Code, promoCode.ValidFrom.Format(utils.DateFormat))
+	case promoCode.ValidUntil != nil && !now.Before(*promoCode.ValidUntil):
+		return utils.NewLocalizedError("promo_code_expired", promoCode.Code, promoCode.ValidUntil.Format(utils.DateFormat))
+	case nights < promoCode.MinNights:
+		return utils.NewLocalizedError("promo_code_min_nights", promoCode.Code, promoCode.MinNights)
+	case len(promoCode.RentalTypes) > 0 && !containsFold(promoCode.RentalTypes, rental.Type):
+		return utils.NewLocalizedError("promo_code_rental_type", promoCode.Code, rental.Type)
+	case len(promoCode.RentalStates) > 0 && !containsFold(promoCode.RentalStates, rental.State):
+		return utils.NewLocalizedError("promo_code_rental_state", promoCode.Code, rental.State)
+	case promoCode.MaxRedemptions != nil && usage.Total >= *promoCode.MaxRedemptions:
+		return utils.NewLocalizedError("promo_code_exhausted", promoCode.Code)
+	case promoCode.MaxRedemptionsPerUser != nil && userId == 0:
+		return utils.NewLocalizedError("promo_code_user_required", promoCode.Code)
+	case promoCode.MaxRedemptionsPerUser != nil && usage.User >= *promoCode.MaxRedemptionsPerUser:
+		return utils.NewLocalizedError("promo_code_user_exhausted", promoCode.Code)
+	}
+	return nil
+}
+
+// discount returns the discount of the promo code on the subtotal, in minor units of the rate's currency. Fixed
+// discounts are stored in base currency cents and never exceed the subtotal.
+func (promoCode PromoCode) discount(subtotal int, rate ExchangeRate) int {
+	var discount int
+	if promoCode.DiscountType == DiscountPercentage {
+		discount = (subtotal*promoCode.DiscountValue + 50) / 100
+	} else {
+		discount = rate.Convert(promoCode.DiscountValue)
+	}
+
+	if discount > subtotal {
+		return subtotal
+	}
+	return discount
+}
+
+func (promoCode PromoCode) describe() string {
+	if promoCode.DiscountType == DiscountPercentage {
+		return fmt.Sprintf("Promo code %s (%d%% off)", promoCode.Code, promoCode.DiscountValue)
+	}
+	return fmt.Sprintf("Promo code %s", promoCode.Code)
+}
+
+func validatePromoCodeInput(input PromoCode) error {
+	switch {
+	case input.Code == "":
+		return utils.NewLocalizedError("field_required", "code")
+	case !promoCodePattern.MatchString(input.Code):
+		return utils.NewLocalizedError("field_invalid", "code")
+	case input.DiscountType != DiscountPercentage && input.DiscountType != DiscountFixed:
+		return utils.NewLocalizedError("field_invalid", "discount_type")
+	case input.DiscountValue <= 0 || (input.DiscountType == DiscountPercentage && input.DiscountValue > 100):
+		return utils.NewLocalizedError("field_invalid", "discount_value")
+	case input.MinNights < 0:
+		return utils.NewLocalizedError("field_invalid", "min_nights")
+	case input.ValidFrom != nil && input.ValidUntil != nil && !input.ValidUntil.After(*input.ValidFrom):
+		return utils.NewLocalizedError("field_invalid", "valid_until")
+	case input.MaxRedemptions != nil && *input.MaxRedemptions <= 0:
+		return utils.NewLocalizedError("field_invalid", "max_redemptions")
+	case input.MaxRedemptionsPerUser != nil && *input.MaxRedemptionsPerUser <= 0:
+		return utils.NewLocalizedError("field_invalid", "max_redemptions_per_user")
+	}
+	return nil
+}
+
+func normalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// normalizeRestrictions trims the restriction values and drops the empty ones. The result is never nil, so it
+// is stored as an empty array rather than NULL.
+func normalizeRestrictions(values []string, normalize func(string) string) pq.StringArray {
+	normalized := make(pq.StringArray, 0, len(values))
+	for _, value := range values {
+		if value = normalize(strings.TrimSpace(value)); value != "" {
+			normalized = append(normalized, value)
+		}
+	}
+	return normalized
+}
+
+func containsFold(values []string, value string) bool {
+	for _, candidate := range values {
+		if strings.EqualFold(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
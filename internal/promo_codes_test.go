@@ -0,0 +1,136 @@
+package internal
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckPromoCodeEligibilityShouldReturnThePreciseRejectionReason(test *testing.T) {
+	var (
+		now       = time.Date(2026, 7, 1, 12, 0, 0, 0, time.UTC)
+		yesterday = now.AddDate(0, 0, -1)
+		tomorrow  = now.AddDate(0, 0, 1)
+		one       = 1
+		rental    = Rental{Type: "camper-van", Location: Location{State: "CA"}}
+	)
+
+	cases := []struct {
+		promoCode PromoCode
+		nights    int
+		userId    int
+		usage     promoCodeUsage
+		message   string
+	}{
+		{promoCode: PromoCode{Code: "OFF", Active: false}, nights: 3, message: "promo code OFF is no longer active"},
+		{promoCode: PromoCode{Code: "SOON", Active: true, ValidFrom: &tomorrow}, nights: 3, message: "promo code SOON is valid from 2026-07-02"},
+		{promoCode: PromoCode{Code: "OLD", Active: true, ValidUntil: &yesterday}, nights: 3, message: "promo code OLD expired on 2026-06-30"},
+		{promoCode: PromoCode{Code: "LONG", Active: true, MinNights: 7}, nights: 3, message: "promo code LONG requires a trip of at least 7 nights"},
+		{promoCode: PromoCode{Code: "RV", Active: true, RentalTypes: []string{"class-a"}}, nights: 3, message: "promo code RV does not apply to camper-van rentals"},
+		{promoCode: PromoCode{Code: "TEXAS", Active: true, RentalStates: []string{"TX"}}, nights: 3, message: "promo code TEXAS does not apply to rentals in CA"},
+		{promoCode: PromoCode{Code: "GONE", Active: true, MaxRedemptions: &one}, nights: 3, usage: promoCodeUsage{Total: 1}, message: "promo code GONE has reached its redemption limit"},
+		{promoCode: PromoCode{Code: "ONCE", Active: true, MaxRedemptionsPerUser: &one}, nights: 3, message: "promo code ONCE can be applied only for a known user_id"},
+		{promoCode: PromoCode{Code: "ONCE", Active: true, MaxRedemptionsPerUser: &one}, nights: 3, userId: 2, usage: promoCodeUsage{Total: 4, User: 1}, message: "promo code ONCE was already redeemed the maximum number of times by this user"},
+	}
+
+	for _, testCase := range cases {
+		err := checkPromoCodeEligibility(testCase.promoCode, rental, testCase.nights, testCase.userId, testCase.usage, now)
+		assert.Error(test, err, "Expected promo code %s to be rejected", testCase.promoCode.Code)
+		if err != nil {
+			assert.Equal(test, testCase.message, err.Error(), "Correct rejection reason is expected")
+		}
+	}
+
+	eligible := PromoCode{Code: "CAMPER", Active: true, ValidFrom: &yesterday, ValidUntil: &tomorrow, MinNights: 3, RentalTypes: []string{"Camper-Van"}, RentalStates: []string{"ca"}}
+	assert.NoError(test, checkPromoCodeEligibility(eligible, rental, 3, 0, promoCodeUsage{}, now), "Expected an eligible promo code to be accepted")
+}
+
+func TestApplyPromoCodeShouldAddADiscountLineOfTheNightlyPrice(test *testing.T) {
+	var (
+		rental     = Rental{IdRental: 1, Type: "camper-van", Price: Price{Day: 16900}}
+		from       = time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+		euro       = newExchangeRate("EUR", 0.92)
+		percentage = PromoCode{Code: "SUMMER15", Active: true, DiscountType: DiscountPercentage, DiscountValue: 15}
+		fixed      = PromoCode{Code: "WELCOME", Active: true, DiscountType: DiscountFixed, DiscountValue: 100000}
+	)
+
+	quote := newQuote(rental, from, from.AddDate(0, 0, 3), euro)
+	err := quote.applyPromoCode(percentage, rental, 0, promoCodeUsage{}, euro, from)
+	if err != nil {
+		test.Fatalf("Error on applying promo code - %s", err.Error())
+	}
+	assert.Equal(test, "SUMMER15", quote.PromoCode, "Expected the applied promo code in the quote")
+	assert.Equal(test, -6997, quote.Lines[1].Amount, "Expected 15% of 466.44 EUR rounded to the cent")
+	assert.Equal(test, 46644-6997, quote.Total, "Expected the discount to be deducted from the total")
+
+	quote = newQuote(rental, from, from.AddDate(0, 0, 3), euro)
+	err = quote.applyPromoCode(fixed, rental, 0, promoCodeUsage{}, euro, from)
+	if err != nil {
+		test.Fatalf("Error on applying promo code - %s", err.Error())
+	}
+	assert.Equal(test, 0, quote.Total, "Expected a fixed discount never to exceed the nightly price")
+}
+
+func TestBookingsShouldRedeemPromoCodesUpToTheirCap(test *testing.T) {
+	defer setupTest(test)()
+
+	var (
+		maxRedemptions = 1
+		code           = "CAP" + strings.ToUpper(strconv.FormatInt(time.Now().UnixNano(), 36))
+		from           = time.Now().UTC().AddDate(1, 0, 0)
+	)
+
+	promoCode, failedValidation, err := CreatePromoCode(PromoCode{
+		Code:           code,
+		DiscountType:   DiscountPercentage,
+		DiscountValue:  10,
+		MaxRedemptions: &maxRedemptions,
+	})
+	if err != nil || failedValidation {
+		test.Fatalf("Error on creating promo code - %v", err)
+	}
+	assert.True(test, promoCode.Active, "Expected a new promo code to be active")
+
+	_, _, err = CreatePromoCode(PromoCode{Code: code, DiscountType: DiscountFixed, DiscountValue: 500})
+	assert.Error(test, err, "Expected promo codes to be unique")
+
+	first, failedValidation, err := CreateBooking(1, BookingInput{
+		UserId:    1,
+		From:      from.Format("2006-01-02"),
+		To:        from.AddDate(0, 0, 2).Format("2006-01-02"),
+		PromoCode: code,
+	})
+	if err != nil || failedValidation {
+		test.Fatalf("Error on creating booking - %v", err)
+	}
+	assert.Equal(test, 2*16900-3380, first.Total, "Expected 10% off two nights")
+	assert.Equal(test, code, first.PromoCode, "Expected the redeemed promo code on the booking")
+
+	_, failedValidation, err = CreateBooking(1, BookingInput{
+		UserId:    2,
+		From:      from.AddDate(0, 0, 5).Format("2006-01-02"),
+		To:        from.AddDate(0, 0, 7).Format("2006-01-02"),
+		PromoCode: code,
+	})
+	assert.True(test, failedValidation, "Expected an exhausted promo code to fail the booking")
+	assert.Equal(test, "promo code "+code+" has reached its redemption limit", err.Error(), "Correct rejection reason is expected")
+
+	_, failedValidation, err = CreateBooking(1, BookingInput{
+		UserId: 2,
+		From:   from.AddDate(0, 0, 1).Format("2006-01-02"),
+		To:     from.AddDate(0, 0, 3).Format("2006-01-02"),
+	})
+	assert.Error(test, err, "Expected overlapping bookings to be rejected")
+	assert.False(test, failedValidation, "Expected an unavailable rental not to be a failed validation")
+
+	if _, err = CancelBooking(1, first.Id); err != nil {
+		test.Fatalf("Error on cancelling booking - %s", err.Error())
+	}
+	promoCode, err = GetPromoCode(code)
+	if err != nil {
+		test.Fatalf("Error on getting promo code - %s", err.Error())
+	}
+	assert.Equal(test, 0, promoCode.Redemptions, "Expected a cancelled booking to release its redemption")
+}
@@ -0,0 +1,219 @@
+package internal
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/jmoiron/sqlx"
+	"outdoorsy-api/database"
+	"outdoorsy-api/utils"
+	"strings"
+	"time"
+)
+
+const (
+	StatusDraft         = "draft"
+	StatusPendingReview = "pending_review"
+	StatusPublished     = "published"
+	StatusUnlisted      = "unlisted"
+
+	ActorOwner = "owner"
+	ActorAdmin = "admin"
+)
+
+type statusTransitionRule struct {
+	from  string
+	to    string
+	actor string
+}
+
+// statusTransitionRules is the moderation workflow - every action moves a rental from exactly one status to
+// another and can be taken only by its actor.
+var statusTransitionRules = map[string]statusTransitionRule{
+	"submit":  {from: StatusDraft, to: StatusPendingReview, actor: ActorOwner},
+	"approve": {from: StatusPendingReview, to: StatusPublished, actor: ActorAdmin},
+	"reject":  {from: StatusPendingReview, to: StatusDraft, actor: ActorAdmin},
+	"unlist":  {from: StatusPublished, to: StatusUnlisted, actor: ActorOwner},
+	"relist":  {from: StatusUnlisted, to: StatusPublished, actor: ActorOwner},
+}
+
+// CreateDraftRental creates a new rental of the owner in draft status. If the input is not valid -
+// failedValidation will be set to true and descriptive validation error will be returned.
+func CreateDraftRental(ownerId int, input RentalInput) (rental Rental, failedValidation bool, err error) {
+	if err = validateRentalInput(input); err != nil {
+		failedValidation = true
+		return
+	}
+
+	var (
+		owner    User
+		rentalId int
+	)
+
+	err = database.GetSingleRecordNamedQuery(&owner, selectUserQuery, map[string]interface{}{"id": ownerId})
+	if err != nil {
+		return
+	}
+
+	err = database.WithTransaction(func(transaction *sqlx.Tx) error {
+		insertStatement, err := transaction.PrepareNamed(insertRentalQuery)
+		if err != nil {
+			return err
+		}
+		defer insertStatement.Close()
+
+		err = insertStatement.Get(&rentalId, map[string]interface{}{
+			"user_id":           ownerId,
+			"name":              input.Name,
+			"description":       input.Description,
+			"type":              input.Type,
+			"make":              input.Make,
+			"model":             input.Model,
+			"year":              input.Year,
+			"length":            input.Length,
+			"sleeps":            input.Sleeps,
+			"primary_image_url": input.PrimaryImageURL,
+			"price_per_day":     input.Price.Day,
+			"city":              input.Location.City,
+			"state":             input.Location.State,
+			"zip":               input.Location.Zip,
+			"country":           input.Location.Country,
+			"lat":               input.Location.Lat,
+			"lng":               input.Location.Lng,
+			"status":            StatusDraft,
+		})
+		if err != nil {
+			return err
+		}
+
+		return recordStatusTransition(transaction, rentalId, nil, StatusDraft, ActorOwner, &ownerId, "")
+	})
+	if err != nil {
+		return
+	}
+
+	rental, err = GetASingleRental(rentalId)
+	return
+}
+
+// TransitionRentalStatus applies a moderation action to the rental and records the transition. Owner actions
+// are taken only on rentals of the owner, admin actions are taken with ownerId 0. A rental which is not in the
+// status the action starts from returns a rental_transition_invalid error.
+func TransitionRentalStatus(rentalId int, action string, ownerId int, reason string) (rental Rental, failedValidation bool, err error) {
+	rule, found := statusTransitionRules[action]
+	if !found || (ownerId != 0) != (rule.actor == ActorOwner) {
+		return rental, true, utils.NewLocalizedError("rental_action_unknown", action)
+	}
+
+	if action == "reject" && strings.TrimSpace(reason) == "" {
+		return rental, true, utils.NewLocalizedError("reason_required")
+	}
+
+	var actorId *int
+	if rule.actor == ActorOwner {
+		actorId = &ownerId
+	}
+
+	err = database.WithTransaction(func(transaction *sqlx.Tx) error {
+		var (
+			updatedId int
+			current   struct {
+				Id     int    `db:"id"`
+				Status string `db:"status"`
+				UserId int    `db:"user_id"`
+			}
+		)
+
+		updateStatement, err := transaction.PrepareNamed(updateRentalStatusQuery)
+		if err != nil {
+			return err
+		}
+		defer updateStatement.Close()
+
+		err = updateStatement.Get(&updatedId, map[string]interface{}{
+			"rental_id":   rentalId,
+			"from_status": rule.from,
+			"to_status":   rule.to,
+			"owner_id":    ownerId,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			selectStatement, err := transaction.PrepareNamed(selectRentalOwnershipQuery)
+			if err != nil {
+				return err
+			}
+			defer selectStatement.Close()
+
+			if err = selectStatement.Get(&current, map[string]interface{}{"id": rentalId}); err != nil {
+				return err
+			}
+			if ownerId != 0 && current.UserId != ownerId {
+				return sql.ErrNoRows
+			}
+			return utils.NewLocalizedError("rental_transition_invalid", action, current.Status)
+		}
+		if err != nil {
+			return err
+		}
+
+		return recordStatusTransition(transaction, rentalId, &rule.from, rule.to, rule.actor, actorId, reason)
+	})
+	if err != nil {
+		return
+	}
+
+	rental, err = GetASingleRental(rentalId)
+	return
+}
+
+// GetModerationQueue retrieves the rentals waiting for review, the longest waiting first.
+func GetModerationQueue() (rentals []Rental, err error) {
+	err = database.GetMultipleRecords(&rentals, selectAllRentalsQuery+moderationQueueClause)
+	if err != nil {
+		return
+	}
+	err = attachAmenities(rentals)
+	convertRentalPrices(rentals, baseExchangeRate())
+	applyUnits(rentals, getUnitSystem(""), "")
+	return
+}
+
+// GetRentalStatusTransitions retrieves the moderation history of the rental, the oldest transition first.
+func GetRentalStatusTransitions(rentalId int) (transitions []StatusTransition, err error) {
+	err = database.GetMultipleRecords(&transitions, selectStatusTransitionsQuery, rentalId)
+	return
+}
+
+func recordStatusTransition(transaction *sqlx.Tx, rentalId int, from *string, to string, actor string, actorId *int, reason string) error {
+	_, err := transaction.NamedExec(insertStatusTransitionQuery, map[string]interface{}{
+		"rental_id":   rentalId,
+		"from_status": from,
+		"to_status":   to,
+		"actor":       actor,
+		"actor_id":    actorId,
+		"reason":      strings.TrimSpace(reason),
+	})
+	return err
+}
+
+func validateRentalInput(input RentalInput) error {
+	switch {
+	case strings.TrimSpace(input.Name) == "":
+		return utils.NewLocalizedError("rental_field_required", "name")
+	case strings.TrimSpace(input.Type) == "":
+		return utils.NewLocalizedError("rental_field_required", "type")
+	case input.Price.Day <= 0:
+		return utils.NewLocalizedError("rental_field_required", "price.day")
+	case input.Price.Currency != "" && input.Price.Currency != BaseCurrency:
+		return utils.NewLocalizedError("rental_field_invalid", "price.currency")
+	case input.Sleeps <= 0:
+		return utils.NewLocalizedError("rental_field_required", "sleeps")
+	case input.Year != 0 && (input.Year < 1900 || input.Year > time.Now().Year()+1):
+		return utils.NewLocalizedError("rental_field_invalid", "year")
+	case input.Length < 0 || input.Length >= 100:
+		return utils.NewLocalizedError("rental_field_invalid", "length")
+	case input.Location.Lat < -90 || input.Location.Lat > 90:
+		return utils.NewLocalizedError("rental_field_invalid", "location.lat")
+	case input.Location.Lng < -180 || input.Location.Lng > 180:
+		return utils.NewLocalizedError("rental_field_invalid", "location.lng")
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package internal
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/url"
+	"strconv"
+	"testing"
+)
+
+func TestRentalPublicationWorkflowShouldRecordEveryTransition(test *testing.T) {
+	defer setupTest(test)()
+
+	rental := createTestDraftRental(test, "Publication workflow van")
+	assert.Equal(test, StatusDraft, rental.Status, "Expected a new rental to be a draft")
+
+	rentals, _, err := GetMultipleRentals(url.Values{"ids": {"1," + strconv.Itoa(rental.IdRental)}})
+	if err != nil {
+		test.Fatalf("Error on getting rentals - %s", err.Error())
+	}
+	assert.Equal(test, 1, len(rentals), "Expected drafts to be left out of the listing by default")
+
+	_, failedValidation, err := TransitionRentalStatus(rental.IdRental, "approve", 1, "")
+	assert.True(test, failedValidation, "Expected owners not to be able to approve their rentals")
+
+	_, failedValidation, err = TransitionRentalStatus(rental.IdRental, "approve", 0, "")
+	assert.Error(test, err, "Expected a draft not to be approved before it's submitted")
+	assert.Equal(test, "can not approve a rental in draft status", err.Error(), "Correct error message is expected")
+
+	steps := []struct {
+		action  string
+		ownerId int
+		reason  string
+		status  string
+	}{
+		{action: "submit", ownerId: 1, status: StatusPendingReview},
+		{action: "reject", ownerId: 0, reason: "Photos are missing", status: StatusDraft},
+		{action: "submit", ownerId: 1, status: StatusPendingReview},
+		{action: "approve", ownerId: 0, status: StatusPublished},
+		{action: "unlist", ownerId: 1, status: StatusUnlisted},
+	}
+	for _, step := range steps {
+		rental, failedValidation, err = TransitionRentalStatus(rental.IdRental, step.action, step.ownerId, step.reason)
+		if err != nil || failedValidation {
+			test.Fatalf("Error on %s transition - %v", step.action, err)
+		}
+		assert.Equal(test, step.status, rental.Status, "Expected the rental to move to the next status")
+	}
+
+	transitions, err := GetRentalStatusTransitions(rental.IdRental)
+	if err != nil {
+		test.Fatalf("Error on getting transitions - %s", err.Error())
+	}
+	assert.Equal(test, len(steps)+1, len(transitions), "Expected the creation and every action to be recorded")
+	assert.Equal(test, "Photos are missing", transitions[2].Reason, "Expected the rejection reason to be recorded")
+}
+
+func TestRejectingARentalShouldRequireAReason(test *testing.T) {
+	defer setupTest(test)()
+
+	_, failedValidation, err := TransitionRentalStatus(1, "reject", 0, " ")
+
+	assert.True(test, failedValidation, "Failed validation is expected")
+	assert.Equal(test, "a reason is required to reject a rental", err.Error(), "Correct error message is expected")
+}
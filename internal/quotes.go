@@ -0,0 +1,142 @@
+package internal
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"outdoorsy-api/utils"
+	"strconv"
+	"time"
+)
+
+// GetQuote prices a trip with the rental between the from and to dates of the parameters, in the requested currency.
+// If the parameters are not valid or the trip breaks a booking rule of the rental - failedValidation will be set to
+// true and descriptive validation error will be returned. A promo code which can not be applied doesn't fail the
+// quote, the reason is kept in the quote and returned by PromoCodeError.
+func GetQuote(id int, params url.Values) (quote Quote, failedValidation bool, err error) {
+	if err = utils.ValidateQuoteParameters(params); err != nil {
+		failedValidation = true
+		return
+	}
+
+	rate, err := GetExchangeRate(params.Get("currency"))
+	if err != nil {
+		failedValidation = true
+		return
+	}
+
+	rental, err := GetASingleRental(id)
+	if err != nil {
+		return
+	}
+
+	from, _ := time.Parse(utils.DateFormat, params.Get("from"))
+	to, _ := time.Parse(utils.DateFormat, params.Get("to"))
+	userId, _ := strconv.Atoi(params.Get("user_id"))
+	if err = checkBookingRules(rental.BookingRules, from, to, time.Now()); err != nil {
+		failedValidation = true
+		return
+	}
+
+	quote = newQuote(rental, from, to, rate)
+
+	if err = quote.addAddons(rental, parseAddonSelections(params.Get("addons")), rate); err != nil {
+		failedValidation = true
+		return
+	}
+
+	if deliveryTo := params.Get("delivery_to"); deliveryTo != "" {
+		if err = quote.addDelivery(rental, deliveryTo, rate, getUnitSystem(params.Get("units"))); err != nil {
+			failedValidation = true
+			return
+		}
+	}
+
+	if code := params.Get("promo_code"); code != "" {
+		var (
+			promoCode PromoCode
+			usage     promoCodeUsage
+		)
+
+		promoCode, err = GetPromoCode(code)
+		if errors.Is(err, sql.ErrNoRows) {
+			quote.promoCodeError = utils.NewLocalizedError("promo_code_unknown", normalizePromoCode(code))
+			return quote, false, nil
+		}
+		if err != nil {
+			return
+		}
+
+		usage, err = getPromoCodeUsage(promoCode.Id, userId)
+		if err != nil {
+			return
+		}
+
+		quote.promoCodeError = quote.applyPromoCode(promoCode, rental, userId, usage, rate, time.Now())
+	}
+	return
+}
+
+// PromoCodeError returns the localized reason the requested promo code was not applied, nil if it was applied
+// or none was requested.
+func (quote Quote) PromoCodeError() error {
+	return quote.promoCodeError
+}
+
+// newQuote prices every night of the trip with the rental in the rate's currency.
+func newQuote(rental Rental, from time.Time, to time.Time, rate ExchangeRate) (quote Quote) {
+	nights := int(to.Sub(from).Hours() / 24)
+	nightlyPrice := rate.Convert(rental.Price.Day)
+
+	quote = Quote{
+		RentalId: rental.IdRental,
+		From:     from.Format(utils.DateFormat),
+		To:       to.Format(utils.DateFormat),
+		Nights:   nights,
+		Currency: rate.Currency,
+		Lines:    make([]QuoteLine, 0),
+	}
+	quote.addLine(QuoteLine{
+		Type:        "nightly",
+		Description: fmt.Sprintf("%d nights", nights),
+		Quantity:    nights,
+		UnitAmount:  nightlyPrice,
+		Amount:      nights * nightlyPrice,
+	})
+	return
+}
+
+// applyPromoCode adds the discount line of the promo code to the quote if the code is eligible for the trip,
+// otherwise it returns the reason it was rejected. The discount applies to the nightly price only.
+func (quote *Quote) applyPromoCode(promoCode PromoCode, rental Rental, userId int, usage promoCodeUsage, rate ExchangeRate, now time.Time) error {
+	if err := checkPromoCodeEligibility(promoCode, rental, quote.Nights, userId, usage, now); err != nil {
+		return err
+	}
+
+	discount := promoCode.discount(quote.linesAmount("nightly"), rate)
+	quote.PromoCode = promoCode.Code
+	quote.addLine(QuoteLine{
+		Type:        "discount",
+		Description: promoCode.describe(),
+		Quantity:    1,
+		UnitAmount:  -discount,
+		Amount:      -discount,
+	})
+	return nil
+}
+
+func (quote *Quote) addLine(line QuoteLine) {
+	quote.Lines = append(quote.Lines, line)
+	quote.Total += line.Amount
+}
+
+// linesAmount sums the amounts of the quote lines of the type.
+func (quote *Quote) linesAmount(lineType string) (amount int) {
+	for _, line := range quote.Lines {
+		if line.Type == lineType {
+			amount += line.Amount
+		}
+	}
+	return
+}
@@ -0,0 +1,47 @@
+package internal
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/url"
+	"testing"
+)
+
+func TestGetQuoteShouldPriceEveryNightInTheRequestedCurrency(test *testing.T) {
+	defer setupTest(test)()
+
+	if err := LoadExchangeRates("../exchange_rates.json"); err != nil {
+		test.Fatalf("Error on loading exchange rates - %s", err.Error())
+	}
+
+	var params = make(url.Values)
+	params.Set("from", "2026-07-01")
+	params.Set("to", "2026-07-04")
+	params.Set("currency", "EUR")
+
+	quote, failedValidation, err := GetQuote(1, params)
+	if err != nil {
+		test.Fatalf("Error on quoting rental - %s", err.Error())
+	}
+
+	if failedValidation {
+		test.Fatalf("There should be no failed validation in case of a valid input")
+	}
+
+	assert.Equal(test, 3, quote.Nights, "Expected 3 nights between the dates")
+	assert.Equal(test, "EUR", quote.Currency, "Expected quote to be in EUR")
+	assert.Equal(test, 3*15548, quote.Total, "Expected 3 nights of 155.48 EUR")
+}
+
+func TestGetQuoteShouldReturnDescriptiveErrorInCaseOfInvalidDates(test *testing.T) {
+	defer setupTest(test)()
+
+	var params = make(url.Values)
+	params.Set("from", "2026-07-04")
+	params.Set("to", "2026-07-01")
+
+	_, failedValidation, err := GetQuote(1, params)
+
+	assert.Error(test, err, "Quoting with end before start should return error")
+	assert.True(test, failedValidation, "Failed validation is expected")
+	assert.Equal(test, "to must be after from", err.Error(), "Correct error message is expected in case of failed validation")
+}
@@ -0,0 +1,132 @@
+package internal
+
+import (
+	"encoding/base64"
+	"net/url"
+	"outdoorsy-api/database"
+	"outdoorsy-api/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultRentalChangesPage = 100
+	maximumRentalChangesPage = 1000
+
+	// rentalChangesSettleTime is longer than the longest write transaction, whose changes are stamped with its start
+	// time, so once a change is this old no transaction can commit an older one anymore and pages never skip a
+	// change. It follows database.TransactionTimeout, after which transactions are cancelled.
+	rentalChangesSettleTime = database.TransactionTimeout + 5*time.Second
+)
+
+// rentalStatuses lists every rental status, the change feed of the admins follows the rentals whatever their status.
+var rentalStatuses = strings.Join([]string{StatusDraft, StatusPendingReview, StatusPublished, StatusUnlisted}, ",")
+
+// GetRentalChanges retrieves the rentals changed or deleted after the since token, oldest first, together with the
+// token of the next page. Without a token the feed starts from the beginning. The next token is returned even if
+// there are no changes, so consumers keep polling with it. If the parameters are not valid - failedValidation will be
+// set to true and descriptive validation error will be returned.
+func GetRentalChanges(params url.Values) (changes RentalChanges, failedValidation bool, err error) {
+	return getRentalChanges(params, rentalChangesSettleTime)
+}
+
+func getRentalChanges(params url.Values, settleTime time.Duration) (changes RentalChanges, failedValidation bool, err error) {
+	if err = utils.ValidateRentalChangesParameters(params); err != nil {
+		failedValidation = true
+		return
+	}
+
+	updated, id, err := decodeRentalChangesToken(params.Get("since"))
+	if err != nil {
+		failedValidation = true
+		return
+	}
+
+	limit := defaultRentalChangesPage
+	if value := params.Get("limit"); value != "" {
+		limit, _ = strconv.Atoi(value)
+		limit = min(limit, maximumRentalChangesPage)
+	}
+
+	err = database.GetMultipleRecords(&changes.Changes, selectRentalChangesQuery, updated, id, int(settleTime.Seconds()), limit+1)
+	if err != nil {
+		return
+	}
+
+	if len(changes.Changes) > limit {
+		changes.Changes, changes.HasMore = changes.Changes[:limit], true
+	}
+	if changes.Changes == nil {
+		changes.Changes = []RentalChange{}
+	}
+
+	changes.Next = params.Get("since")
+	if last := len(changes.Changes) - 1; last >= 0 {
+		changes.Next = encodeRentalChangesToken(changes.Changes[last].Updated, changes.Changes[last].RentalId)
+	}
+
+	err = attachChangedRentals(changes.Changes)
+	return
+}
+
+// attachChangedRentals loads the current state of the rentals which are not deleted. A rental deleted since the page
+// was read is attached as deleted, its tombstone comes in a later page.
+func attachChangedRentals(changes []RentalChange) error {
+	ids := make([]string, 0, len(changes))
+	for _, change := range changes {
+		if !change.Deleted {
+			ids = append(ids, strconv.Itoa(change.RentalId))
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	rentals, _, err := GetMultipleRentals(url.Values{"ids": {strings.Join(ids, ",")}, "status": {rentalStatuses}})
+	if err != nil {
+		return err
+	}
+
+	byId := make(map[int]*Rental, len(rentals))
+	for i := range rentals {
+		byId[rentals[i].IdRental] = &rentals[i]
+	}
+	for i := range changes {
+		if changes[i].Deleted {
+			continue
+		}
+		if changes[i].Rental = byId[changes[i].RentalId]; changes[i].Rental == nil {
+			changes[i].Deleted = true
+		}
+	}
+	return nil
+}
+
+// encodeRentalChangesToken builds the opaque since token of the change following the given one.
+func encodeRentalChangesToken(updated time.Time, id int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(updated.UTC().Format(time.RFC3339Nano) + "," + strconv.Itoa(id)))
+}
+
+func decodeRentalChangesToken(token string) (updated time.Time, id int, err error) {
+	if token == "" {
+		return
+	}
+
+	invalid := utils.NewLocalizedError("since_invalid")
+	decoded, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return updated, id, invalid
+	}
+	timestamp, rentalId, found := strings.Cut(string(decoded), ",")
+	if !found {
+		return updated, id, invalid
+	}
+	if updated, err = time.Parse(time.RFC3339Nano, timestamp); err != nil {
+		return updated, id, invalid
+	}
+	if id, err = strconv.Atoi(rentalId); err != nil || id <= 0 {
+		return updated, id, invalid
+	}
+	return
+}
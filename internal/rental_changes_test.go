@@ -0,0 +1,99 @@
+package internal
+
+import (
+	"encoding/base64"
+	"github.com/stretchr/testify/assert"
+	"net/url"
+	"outdoorsy-api/database"
+	"testing"
+	"time"
+)
+
+func TestRentalChangesTokenShouldRoundTrip(test *testing.T) {
+	updated := time.Date(2026, 10, 19, 8, 30, 15, 123456000, time.UTC)
+
+	decodedUpdated, decodedId, err := decodeRentalChangesToken(encodeRentalChangesToken(updated, 42))
+	assert.NoError(test, err, "Expected a valid token")
+	assert.True(test, updated.Equal(decodedUpdated), "Expected the microseconds of the timestamp to be kept")
+	assert.Equal(test, 42, decodedId, "Expected the rental id")
+
+	decodedUpdated, decodedId, err = decodeRentalChangesToken("")
+	assert.NoError(test, err, "Expected no token to start from the beginning")
+	assert.True(test, decodedUpdated.IsZero(), "Expected the feed to start from the beginning")
+	assert.Equal(test, 0, decodedId, "Expected the feed to start from the beginning")
+}
+
+func TestGetRentalChangesShouldReturnDescriptiveErrorInCaseOfInvalidParameters(test *testing.T) {
+	_, failedValidation, err := GetRentalChanges(url.Values{"limit": {"0"}})
+	assert.True(test, failedValidation, "Invalid parameters should fail validation")
+	assert.Equal(test, "limit and offset must be a positive integer", err.Error(), "Correct error message is expected")
+
+	for _, token := range []string{"not a token", "2026-10-19T08:30:15Z", "2026-10-19,1", "2026-10-19T08:30:15Z,0"} {
+		_, failedValidation, err = GetRentalChanges(url.Values{"since": {base64.RawURLEncoding.EncodeToString([]byte(token))}})
+		assert.True(test, failedValidation, "Invalid tokens should fail validation")
+		assert.Equal(test, "since must be the next token of a previous page of changes", err.Error(), "Correct error message is expected")
+	}
+}
+
+func TestGetRentalChangesShouldResumeWhereThePreviousPageEnded(test *testing.T) {
+	defer setupTest(test)()
+
+	first, _, err := getRentalChanges(url.Values{"limit": {"3"}}, 0)
+	if err != nil {
+		test.Fatalf("Error on getting rental changes - %s", err.Error())
+	}
+	assert.Len(test, first.Changes, 3, "Expected the page to be limited")
+	assert.True(test, first.HasMore, "Expected more changes after the first page")
+
+	second, _, err := getRentalChanges(url.Values{"since": {first.Next}, "limit": {"3"}}, 0)
+	if err != nil {
+		test.Fatalf("Error on getting rental changes - %s", err.Error())
+	}
+	assert.NotEqual(test, first.Changes[2].RentalId, second.Changes[0].RentalId, "Expected the next page to start after the last change")
+	assert.False(test, second.Changes[0].Updated.Before(first.Changes[2].Updated), "Expected the changes ordered by their updated time")
+
+	head := second
+	for head.HasMore {
+		if head, _, err = getRentalChanges(url.Values{"since": {head.Next}, "limit": {"1000"}}, 0); err != nil {
+			test.Fatalf("Error on getting rental changes - %s", err.Error())
+		}
+	}
+
+	rental := createTestDraftRental(test, "Change feed van")
+
+	changes, _, err := getRentalChanges(url.Values{"since": {head.Next}}, 0)
+	if err != nil {
+		test.Fatalf("Error on getting rental changes - %s", err.Error())
+	}
+	created := findRentalChange(changes.Changes, rental.IdRental)
+	if assert.NotNil(test, created, "Expected the created rental") && assert.NotNil(test, created.Rental, "Expected the rental") {
+		assert.False(test, created.Deleted, "Expected the rental not to be deleted")
+		assert.Equal(test, StatusDraft, created.Rental.Status, "Expected rentals of every status")
+	}
+
+	if err = database.Exec("DELETE FROM rentals WHERE rentals.id = $1;", rental.IdRental); err != nil {
+		test.Fatalf("Error on deleting rental - %s", err.Error())
+	}
+
+	changes, _, err = getRentalChanges(url.Values{"since": {changes.Next}}, 0)
+	if err != nil {
+		test.Fatalf("Error on getting rental changes - %s", err.Error())
+	}
+	deleted := findRentalChange(changes.Changes, rental.IdRental)
+	if assert.NotNil(test, deleted, "Expected the tombstone of the deleted rental") {
+		assert.True(test, deleted.Deleted, "Expected the rental to be deleted")
+		assert.Nil(test, deleted.Rental, "Expected no rental for a tombstone")
+	}
+
+	unchanged, _, _ := getRentalChanges(url.Values{"since": {changes.Next}}, 0)
+	assert.Nil(test, findRentalChange(unchanged.Changes, rental.IdRental), "Expected no change of the rental after its tombstone")
+}
+
+func findRentalChange(changes []RentalChange, rentalId int) *RentalChange {
+	for i := range changes {
+		if changes[i].RentalId == rentalId {
+			return &changes[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+package internal
+
+import (
+	"encoding/json"
+	log "github.com/sirupsen/logrus"
+	"net/url"
+	"outdoorsy-api/database"
+	"outdoorsy-api/utils"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	RentalEventCreated = "created"
+	RentalEventUpdated = "updated"
+	RentalEventDeleted = "deleted"
+	// RentalEventRemoved is sent to the subscribers whose filters an updated rental doesn't match anymore.
+	RentalEventRemoved = "removed"
+
+	rentalEventsChannel = "rental_events"
+	// rentalEventsBuffer is how far a subscriber may fall behind before it is dropped.
+	rentalEventsBuffer = 64
+	// rentalEventsPingInterval checks the listener connection between notifications, as advised by lib/pq.
+	rentalEventsPingInterval = 90 * time.Second
+	// rentalEventsListenBackoff is the delay before listening again after a failure, doubled up to
+	// rentalEventsListenBackoffMaximum.
+	rentalEventsListenBackoff        = time.Second
+	rentalEventsListenBackoffMaximum = time.Minute
+)
+
+// rentalEventHub fans the rental events out to the subscribers. Subscribers which don't keep up are dropped, they
+// resume from the event log instead of slowing the others down.
+type rentalEventHub struct {
+	sync.Mutex
+	subscribers map[chan RentalEvent]struct{}
+	listening   atomic.Bool
+}
+
+var rentalEvents = &rentalEventHub{subscribers: make(map[chan RentalEvent]struct{})}
+
+// StartRentalEventListener listens to the rental events notified by the database on a single connection and
+// publishes them to the subscribers.
+func StartRentalEventListener() {
+	go rentalEvents.listen()
+}
+
+// RentalEventsAvailable tells whether the rental events are listened to, streams opened before would miss events.
+func RentalEventsAvailable() bool {
+	return rentalEvents.listening.Load()
+}
+
+// SubscribeRentalEvents returns the rental events committed from now on, in the order they are committed. The
+// channel is closed if the subscriber falls behind. Unsubscribe once the events are not read anymore.
+func SubscribeRentalEvents() (events <-chan RentalEvent, unsubscribe func()) {
+	return rentalEvents.subscribe()
+}
+
+// GetRentalEventsAfter retrieves the logged events following the event id. complete is false if the log doesn't go
+// back that far anymore, so some events are missing. Events are committed in the order of their ids, so no event
+// committed later can come before the id.
+func GetRentalEventsAfter(id int64) (events []RentalEvent, complete bool, err error) {
+	var oldest []int64
+	if err = database.GetMultipleRecords(&oldest, selectOldestRentalEventIdQuery); err != nil {
+		return
+	}
+	if err = database.GetMultipleRecords(&events, selectRentalEventsAfterQuery, id); err != nil {
+		return
+	}
+	complete = len(oldest) == 0 || oldest[0] == 0 || oldest[0] <= id+1
+	return
+}
+
+// ValidateRentalEventsParameters validates the filters of a rental event stream, which are the ones of
+// GetMultipleRentals.
+func ValidateRentalEventsParameters(params url.Values) (failedValidation bool, err error) {
+	_, _, failedValidation, err = getRentalsQuery(params)
+	return
+}
+
+// FilterRentalEvent tells the kind of event a subscriber with the filters of GetMultipleRentals gets, empty if the
+// event isn't sent, and returns the rental as it is now, presented by the parameters. A rental updated so it doesn't
+// match the filters anymore is removed, as the subscriber may have it from before. Deleted and removed rentals can
+// only be filtered by their ids.
+func FilterRentalEvent(event RentalEvent, params url.Values) (rental Rental, kind string, err error) {
+	if ids := params.Get("ids"); ids != "" && !containsId(ids, event.RentalId) {
+		return
+	}
+	if event.Kind == RentalEventDeleted {
+		return Rental{IdRental: event.RentalId}, event.Kind, nil
+	}
+
+	filters := url.Values{}
+	for key, values := range params {
+		filters[key] = values
+	}
+	filters.Set("ids", strconv.Itoa(event.RentalId))
+	filters.Del("limit")
+	filters.Del("offset")
+
+	rentals, _, err := GetMultipleRentals(filters)
+	if err != nil {
+		return
+	}
+	if len(rentals) == 0 {
+		if event.Kind == RentalEventUpdated {
+			return Rental{IdRental: event.RentalId}, RentalEventRemoved, nil
+		}
+		return
+	}
+	return rentals[0], event.Kind, nil
+}
+
+func containsId(ids string, id int) bool {
+	for _, value := range strings.Split(ids, ",") {
+		if value == strconv.Itoa(id) {
+			return true
+		}
+	}
+	return false
+}
+
+func (hub *rentalEventHub) subscribe() (<-chan RentalEvent, func()) {
+	events := make(chan RentalEvent, rentalEventsBuffer)
+
+	hub.Lock()
+	hub.subscribers[events] = struct{}{}
+	hub.Unlock()
+
+	return events, func() {
+		hub.Lock()
+		defer hub.Unlock()
+		if _, subscribed := hub.subscribers[events]; subscribed {
+			delete(hub.subscribers, events)
+			close(events)
+		}
+	}
+}
+
+func (hub *rentalEventHub) publish(event RentalEvent) {
+	hub.Lock()
+	defer hub.Unlock()
+
+	for events := range hub.subscribers {
+		select {
+		case events <- event:
+		default:
+			delete(hub.subscribers, events)
+			close(events)
+		}
+	}
+}
+
+// listen publishes the notified events. Listening is retried until it succeeds, after a reconnection the events
+// missed meanwhile are read from the log.
+func (hub *rentalEventHub) listen() {
+	listener, err := database.Listen(rentalEventsChannel)
+	for delay := rentalEventsListenBackoff; err != nil; delay = min(2*delay, rentalEventsListenBackoffMaximum) {
+		utils.GetLogger().WithFields(log.Fields{"error": err.Error(), "retry": delay.String()}).Error("Unable to listen to rental events")
+		time.Sleep(delay)
+		listener, err = database.Listen(rentalEventsChannel)
+	}
+	hub.listening.Store(true)
+
+	var (
+		lastId int64
+		ping   = time.NewTicker(rentalEventsPingInterval)
+	)
+	for {
+		select {
+		case notification := <-listener.Notify:
+			if notification == nil {
+				lastId = hub.publishMissed(lastId)
+				continue
+			}
+
+			var event RentalEvent
+			if err = json.Unmarshal([]byte(notification.Extra), &event); err != nil {
+				utils.GetLogger().WithFields(log.Fields{"error": err.Error(), "payload": notification.Extra}).Error("Error on decoding rental event")
+				continue
+			}
+			lastId = max(lastId, event.Id)
+			hub.publish(event)
+		case <-ping.C:
+			go func() {
+				_ = listener.Ping()
+			}()
+		}
+	}
+}
+
+// publishMissed publishes the events logged after the last one published, after the listener reconnected.
+func (hub *rentalEventHub) publishMissed(lastId int64) int64 {
+	if lastId == 0 {
+		return lastId
+	}
+
+	missed, _, err := GetRentalEventsAfter(lastId)
+	if err != nil {
+		utils.GetLogger().WithFields(log.Fields{"error": err.Error(), "id": lastId}).Error("Error on getting missed rental events")
+		return lastId
+	}
+	for _, event := range missed {
+		lastId = max(lastId, event.Id)
+		hub.publish(event)
+	}
+	return lastId
+}
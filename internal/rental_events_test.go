@@ -0,0 +1,170 @@
+package internal
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"net/url"
+	"outdoorsy-api/database"
+	"testing"
+	"time"
+)
+
+func TestRentalEventHubShouldFanOutAndDropLaggingSubscribers(test *testing.T) {
+	hub := &rentalEventHub{subscribers: make(map[chan RentalEvent]struct{})}
+
+	reading, unsubscribeReading := hub.subscribe()
+	lagging, _ := hub.subscribe()
+
+	for id := int64(1); id <= rentalEventsBuffer; id++ {
+		hub.publish(RentalEvent{Id: id, RentalId: 1, Kind: RentalEventUpdated})
+		event := <-reading
+		assert.Equal(test, id, event.Id, "Expected the events in the order they are published")
+	}
+	hub.publish(RentalEvent{Id: rentalEventsBuffer + 1, RentalId: 1, Kind: RentalEventUpdated})
+	assert.Len(test, hub.subscribers, 1, "Expected the subscriber which fell behind to be dropped")
+
+	received := 0
+	for range lagging {
+		received++
+	}
+	assert.Equal(test, rentalEventsBuffer, received, "Expected the lagging subscriber to get the buffered events before its channel is closed")
+
+	event := <-reading
+	assert.Equal(test, int64(rentalEventsBuffer+1), event.Id, "Expected the reading subscriber to get every event")
+
+	unsubscribeReading()
+	unsubscribeReading()
+	_, open := <-reading
+	assert.False(test, open, "Expected the channel to be closed once unsubscribed")
+	assert.Empty(test, hub.subscribers, "Expected no subscriber left")
+}
+
+func TestFilterRentalEventShouldFilterDeletedRentalsByIds(test *testing.T) {
+	event := RentalEvent{Id: 1, RentalId: 7, Kind: RentalEventDeleted}
+
+	rental, kind, err := FilterRentalEvent(event, url.Values{"ids": {"3,7"}})
+	assert.NoError(test, err, "Expected no error for a deleted rental")
+	assert.Equal(test, RentalEventDeleted, kind, "Expected a deleted rental to match the ids filter listing it")
+	assert.Equal(test, 7, rental.IdRental, "Expected the id of the deleted rental")
+
+	_, kind, _ = FilterRentalEvent(event, url.Values{"ids": {"3,17"}})
+	assert.Empty(test, kind, "Expected a deleted rental not to match the ids filter leaving it out")
+
+	_, kind, _ = FilterRentalEvent(event, url.Values{"price_min": {"100000"}})
+	assert.Equal(test, RentalEventDeleted, kind, "Expected a deleted rental to match filters other than ids")
+
+	_, kind, _ = FilterRentalEvent(RentalEvent{Id: 2, RentalId: 7, Kind: RentalEventUpdated}, url.Values{"ids": {"3,17"}})
+	assert.Empty(test, kind, "Expected an updated rental left out by the ids filter not to be removed")
+}
+
+func TestFilterRentalEventShouldRemoveRentalsLeavingTheFilters(test *testing.T) {
+	defer setupTest(test)()
+
+	updated := RentalEvent{Id: 1, RentalId: 1, Kind: RentalEventUpdated}
+	rental, kind, err := FilterRentalEvent(updated, url.Values{"ids": {"1"}})
+	if err != nil {
+		test.Fatalf("Error on filtering rental event - %s", err.Error())
+	}
+	assert.Equal(test, RentalEventUpdated, kind, "Expected an updated rental matching the filters to be updated")
+	assert.NotEmpty(test, rental.Name, "Expected the rental as it is now")
+
+	_, kind, _ = FilterRentalEvent(updated, url.Values{"price_min": {"1000000"}})
+	assert.Equal(test, RentalEventRemoved, kind, "Expected an updated rental out of the filters to be removed")
+
+	_, kind, _ = FilterRentalEvent(RentalEvent{Id: 2, RentalId: 1, Kind: RentalEventCreated}, url.Values{"price_min": {"1000000"}})
+	assert.Empty(test, kind, "Expected a created rental out of the filters not to be sent")
+}
+
+func TestUpdatingARentalShouldLogAnEvent(test *testing.T) {
+	defer setupTest(test)()
+
+	before, _, err := GetRentalEventsAfter(0)
+	if err != nil {
+		test.Fatalf("Error on getting rental events - %s", err.Error())
+	}
+	var lastId int64
+	if len(before) > 0 {
+		lastId = before[len(before)-1].Id
+	}
+
+	rental := createTestDraftRental(test, "Rental events van")
+	if _, _, err = TransitionRentalStatus(rental.IdRental, "submit", 1, ""); err != nil {
+		test.Fatalf("Error on submitting rental - %s", err.Error())
+	}
+
+	events, complete, err := GetRentalEventsAfter(lastId)
+	if err != nil {
+		test.Fatalf("Error on getting rental events - %s", err.Error())
+	}
+	assert.True(test, complete, "Expected the log to go back to the last event")
+
+	var kinds []string
+	for _, event := range events {
+		if event.RentalId == rental.IdRental {
+			kinds = append(kinds, event.Kind)
+		}
+	}
+	assert.Equal(test, []string{RentalEventCreated, RentalEventUpdated}, kinds, "Expected an event for the creation and one for the update")
+}
+
+func TestRentalEventsShouldBeCommittedInTheOrderOfTheirIds(test *testing.T) {
+	defer setupTest(test)()
+
+	before, _, err := GetRentalEventsAfter(0)
+	if err != nil {
+		test.Fatalf("Error on getting rental events - %s", err.Error())
+	}
+	var lastId int64
+	if len(before) > 0 {
+		lastId = before[len(before)-1].Id
+	}
+
+	first := createTestDraftRental(test, "First ordered events van")
+	second := createTestDraftRental(test, "Second ordered events van")
+	rename := "UPDATE rentals SET name = name || ' renamed' WHERE rentals.id = $1;"
+
+	var (
+		firstUpdated = make(chan struct{})
+		commitFirst  = make(chan struct{})
+		firstDone    = make(chan error, 1)
+		secondDone   = make(chan error, 1)
+	)
+	go func() {
+		firstDone <- database.WithTransaction(func(transaction *sqlx.Tx) error {
+			if _, err := transaction.Exec(rename, first.IdRental); err != nil {
+				return err
+			}
+			close(firstUpdated)
+			<-commitFirst
+			return nil
+		})
+	}()
+	<-firstUpdated
+	go func() {
+		secondDone <- database.WithTransaction(func(transaction *sqlx.Tx) error {
+			_, err := transaction.Exec(rename, second.IdRental)
+			return err
+		})
+	}()
+
+	select {
+	case err = <-secondDone:
+		test.Fatalf("Expected the second change to wait for the first one to be committed, got %v", err)
+	case <-time.After(500 * time.Millisecond):
+	}
+	close(commitFirst)
+	assert.NoError(test, <-firstDone, "Expected the first change to be committed")
+	assert.NoError(test, <-secondDone, "Expected the second change to be committed")
+
+	events, _, err := GetRentalEventsAfter(lastId)
+	if err != nil {
+		test.Fatalf("Error on getting rental events - %s", err.Error())
+	}
+	var renamed []int
+	for _, event := range events {
+		if event.Kind == RentalEventUpdated && (event.RentalId == first.IdRental || event.RentalId == second.IdRental) {
+			renamed = append(renamed, event.RentalId)
+		}
+	}
+	assert.Equal(test, []int{first.IdRental, second.IdRental}, renamed, "Expected the ids of the events to follow the order of the commits")
+}
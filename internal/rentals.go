@@ -1,16 +1,66 @@
 package internal
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"net/url"
 	"outdoorsy-api/database"
 	"outdoorsy-api/utils"
 	"strings"
+	"time"
 )
 
+// rentalsStreamChunkSize is the number of streamed rentals which are completed with their amenities at once.
+const rentalsStreamChunkSize = 200
+
 // GetASingleRental retrieves a single rental from the database by the specified id.
 func GetASingleRental(id int) (rental Rental, err error) {
 	err = database.GetSingleRecordNamedQuery(&rental, selectSingleRentalQuery, map[string]interface{}{"id": id})
+	if err != nil {
+		return
+	}
+
+	rentals := []Rental{rental}
+	if err = attachAmenities(rentals); err != nil {
+		return
+	}
+	rental = rentals[0]
+	err = attachAddons(&rental)
+	rental.Price.Currency = BaseCurrency
+	rental.Units = utils.UnitsImperial
+	return
+}
+
+// GetASingleRentalWithParameters retrieves a single rental the same way as GetASingleRental, presented according
+// to the currency and units parameters. Rentals which are not published are only retrieved for admins, for anyone
+// else they don't exist. If the parameters are not valid - failedValidation will be set to true.
+func GetASingleRentalWithParameters(id int, params url.Values, admin bool) (rental Rental, failedValidation bool, err error) {
+	presentationParams := url.Values{"currency": {params.Get("currency")}, "units": {params.Get("units")}}
+	if err = utils.ValidateParameters(presentationParams); err != nil {
+		failedValidation = true
+		return
+	}
+
+	options, err := getRentalsQueryOptions(presentationParams)
+	if err != nil {
+		failedValidation = true
+		return
+	}
+
+	rental, err = GetASingleRental(id)
+	if err != nil {
+		return
+	}
+	if rental.Status != StatusPublished && !admin {
+		rental, err = Rental{}, sql.ErrNoRows
+		return
+	}
+
+	rentals := []Rental{rental}
+	convertRentalPrices(rentals, options.rate)
+	applyUnits(rentals, options.units, "")
+	rental = rentals[0]
 	return
 }
 
@@ -20,8 +70,67 @@ func GetASingleRental(id int) (rental Rental, err error) {
 // be returned. In case of non supported parameter - all records will be retrieved as if no parameter was
 // added.
 func GetMultipleRentals(params url.Values) (rentals []Rental, failedValidation bool, err error) {
+	query, options, failedValidation, err := getRentalsQuery(params)
+	if err != nil {
+		return
+	}
+
+	err = database.GetMultipleRecords(&rentals, query)
+	if err != nil {
+		return
+	}
+	err = presentRentals(rentals, params, options)
+	return
+}
+
+// StreamMultipleRentals filters the rentals the same way as GetMultipleRentals, but passes them to the handler one
+// by one as they are read from the database, so large results are never held in memory. The parameters are
+// validated before the handler is called for the first time. The query stops when the context is cancelled.
+func StreamMultipleRentals(ctx context.Context, params url.Values, handler func(rental Rental) error) (failedValidation bool, err error) {
+	var (
+		rental Rental
+		chunk  = make([]Rental, 0, rentalsStreamChunkSize)
+	)
+
+	query, options, failedValidation, err := getRentalsQuery(params)
+	if err != nil {
+		return
+	}
+
+	flush := func() error {
+		if err := presentRentals(chunk, params, options); err != nil {
+			return err
+		}
+		for _, presented := range chunk {
+			if err := handler(presented); err != nil {
+				return err
+			}
+		}
+		chunk = chunk[:0]
+		return nil
+	}
+
+	err = database.ForEachRecord(ctx, &rental, func() error {
+		chunk = append(chunk, rental)
+		rental = Rental{}
+		if len(chunk) < rentalsStreamChunkSize {
+			return nil
+		}
+		return flush()
+	}, query)
+	if err != nil {
+		return
+	}
+	err = flush()
+	return
+}
+
+// getRentalsQuery validates the parameters and builds the query of the rentals they filter. Without parameters only
+// the published rentals are selected.
+func getRentalsQuery(params url.Values) (query string, options rentalsQueryOptions, failedValidation bool, err error) {
 	if len(params) == 0 {
-		err = database.GetMultipleRecords(&rentals, selectAllRentalsQuery)
+		options, err = getRentalsQueryOptions(params)
+		query = selectAllRentalsQuery + publishedRentalsClause
 		return
 	}
 
@@ -30,15 +139,51 @@ func GetMultipleRentals(params url.Values) (rentals []Rental, failedValidation b
 		return
 	}
 
-	additionalQueryParams := transpileParamsToDBQueries(params)
-	err = database.GetMultipleRecords(&rentals, selectAllRentalsQuery+additionalQueryParams)
+	options, err = getRentalsQueryOptions(params)
+	if err != nil {
+		failedValidation = true
+		return
+	}
+
+	if err = ValidateSort(params.Get("sort")); err != nil {
+		failedValidation = true
+		return
+	}
+
+	if failedValidation, err = validateAmenities(params.Get("amenities")); err != nil {
+		return
+	}
+
+	query = selectAllRentalsQuery + transpileParamsToDBQueries(params, options)
+	return
+}
+
+// presentRentals completes the rentals read from the database with their amenities and presents them according to
+// the parameters.
+func presentRentals(rentals []Rental, params url.Values, options rentalsQueryOptions) (err error) {
+	err = attachAmenities(rentals)
+	applyDelivery(rentals, params.Get("delivery_to"), options.units)
+	convertRentalPrices(rentals, options.rate)
+	applyUnits(rentals, options.units, params.Get("near"))
 	return
 }
 
-func transpileParamsToDBQueries(params url.Values) (additionalQueryParams string) {
+// rentalsQueryOptions holds how the rental filters are interpreted and how the results are presented.
+type rentalsQueryOptions struct {
+	rate  ExchangeRate
+	units unitSystem
+}
+
+func getRentalsQueryOptions(params url.Values) (options rentalsQueryOptions, err error) {
+	options.rate, err = GetExchangeRate(params.Get("currency"))
+	options.units = getUnitSystem(params.Get("units"))
+	return
+}
+
+func transpileParamsToDBQueries(params url.Values, options rentalsQueryOptions) (additionalQueryParams string) {
 	var builder strings.Builder
 
-	handleDBQueryWhereClauses(params, &builder)
+	handleDBQueryWhereClauses(params, options, &builder)
 	handleDBQuerySortingClause(params, &builder)
 	handleDBQueryFinalClauses(params, &builder)
 
@@ -48,14 +193,21 @@ func transpileParamsToDBQueries(params url.Values) (additionalQueryParams string
 	return
 }
 
-func handleDBQueryWhereClauses(params url.Values, builder *strings.Builder) {
+func handleDBQueryWhereClauses(params url.Values, options rentalsQueryOptions, builder *strings.Builder) {
 	var (
 		queryWhereClause string
 		whereParameters  = map[string]string{
-			"price_min": " price_per_day >= %s",
-			"price_max": " price_per_day <= %s",
-			"ids":       " rentals.id IN (%s)",
-			"near":      " lat >= %s AND lng >= %s",
+			"price_min":     " " + options.rate.sqlPriceExpression() + " >= %s",
+			"price_max":     " " + options.rate.sqlPriceExpression() + " <= %s",
+			"length_min":    " " + options.units.sqlLengthExpression() + " >= %s",
+			"ids":           " rentals.id IN (%s)",
+			"near":          " lat >= %s AND lng >= %s",
+			"status":        " rentals.status IN (%s)",
+			"delivery_to":   "%s",
+			"from":          "%s",
+			"created_after": " rentals.created > '%s'",
+			"updated_after": " rentals.updated > '%s'",
+			"amenities":     " rentals.id IN (SELECT rental_amenities.rental_id FROM rental_amenities JOIN amenities ON amenities.id = rental_amenities.amenity_id WHERE amenities.key IN (%s) GROUP BY rental_amenities.rental_id HAVING COUNT(DISTINCT amenities.key) = %d)",
 		}
 	)
 
@@ -65,35 +217,69 @@ func handleDBQueryWhereClauses(params url.Values, builder *strings.Builder) {
 			continue
 		}
 
-		if key == "near" {
+		if key == "near" && params.Get("radius") != "" {
+			splitValues := strings.Split(value[0], ",")
+			queryWhereClause = fmt.Sprintf(" %s <= %s", options.units.sqlDistanceExpression(splitValues[0], splitValues[1]), params.Get("radius"))
+		} else if key == "near" {
 			splitValues := strings.Split(value[0], ",")
 			queryWhereClause = fmt.Sprintf(content, splitValues[0], splitValues[1])
+		} else if key == "amenities" {
+			keys := parseAmenityKeys(value[0])
+			queryWhereClause = fmt.Sprintf(content, "'"+strings.Join(keys, "','")+"'", len(keys))
+		} else if key == "delivery_to" {
+			queryWhereClause = sqlDeliveryClause(value[0], options.units)
+		} else if key == "from" {
+			queryWhereClause = sqlBookingRulesClause(value[0], params.Get("to"))
+		} else if key == "created_after" || key == "updated_after" {
+			timestamp, _ := utils.ParseTimestamp(key, value[0])
+			queryWhereClause = fmt.Sprintf(content, timestamp.UTC().Format(time.RFC3339Nano))
+		} else if key == "status" {
+			queryWhereClause = fmt.Sprintf(content, "'"+strings.Join(strings.Split(value[0], ","), "','")+"'")
 		} else {
 			queryWhereClause = fmt.Sprintf(content, value)
 		}
 
-		if builder.Len() > 0 {
-			builder.WriteString(" AND ")
-			builder.WriteString(queryWhereClause)
-		} else {
-			builder.WriteString(" WHERE ")
-			builder.WriteString(queryWhereClause)
-		}
+		writeDBQueryWhereClause(builder, queryWhereClause)
+	}
+
+	// only published rentals are listed, unless other statuses are requested explicitly
+	if params.Get("status") == "" {
+		writeDBQueryWhereClause(builder, " rentals.status = '"+StatusPublished+"'")
 	}
 }
 
+func writeDBQueryWhereClause(builder *strings.Builder, queryWhereClause string) {
+	if builder.Len() > 0 {
+		builder.WriteString(" AND ")
+		builder.WriteString(queryWhereClause)
+	} else {
+		builder.WriteString(" WHERE ")
+		builder.WriteString(queryWhereClause)
+	}
+}
+
+// rentalSortings maps the values of the sort parameter to the orderings of the rentals. Ties are broken by the id,
+// so pages of the same ordering never overlap.
+var rentalSortings = map[string]string{
+	"id":     " ORDER BY rentals.id",
+	"name":   " ORDER BY rentals.name, rentals.id",
+	"year":   " ORDER BY rentals.vehicle_year, rentals.id",
+	"length": " ORDER BY rentals.vehicle_length, rentals.id",
+	"sleeps": " ORDER BY rentals.sleeps, rentals.id",
+	"price":  " ORDER BY price_per_day, rentals.id",
+	"newest": " ORDER BY rentals.created DESC, rentals.id DESC",
+}
+
+// ValidateSort allows only the orderings of rentalSortings, the sort parameter never reaches the query as it is.
+func ValidateSort(sort string) error {
+	if _, found := rentalSortings[sort]; sort != "" && !found {
+		return utils.NewLocalizedError("sort_invalid")
+	}
+	return nil
+}
+
 func handleDBQuerySortingClause(params url.Values, builder *strings.Builder) {
-	var querySortingClause string
-
-	if params.Has("sort") {
-		sortingValue := params.Get("sort")
-		if sortingValue == "" {
-			return
-		} else if sortingValue == "price" {
-			querySortingClause = " ORDER BY price_per_day"
-		} else {
-			querySortingClause = fmt.Sprintf(" ORDER BY %s", sortingValue)
-		}
+	if querySortingClause, found := rentalSortings[params.Get("sort")]; found {
 		builder.WriteString(querySortingClause)
 	}
 }
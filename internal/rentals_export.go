@@ -0,0 +1,123 @@
+package internal
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RentalRecord is the flat representation of a rental used by the CSV and NDJSON exports. The embedded price,
+// location and user are prefixed with their name, and the json tags are the column names of the CSV, in order.
+type RentalRecord struct {
+	Id               int      `json:"id"`
+	Name             string   `json:"name"`
+	Description      string   `json:"description"`
+	Type             string   `json:"type"`
+	Make             string   `json:"make"`
+	Model            string   `json:"model"`
+	Year             int      `json:"year"`
+	Length           float64  `json:"length"`
+	Sleeps           int      `json:"sleeps"`
+	PrimaryImageURL  string   `json:"primary_image_url"`
+	Status           string   `json:"status"`
+	Created          string   `json:"created"`
+	Updated          string   `json:"updated"`
+	PriceDay         int      `json:"price_day"`
+	PriceCurrency    string   `json:"price_currency"`
+	LocationCity     string   `json:"location_city"`
+	LocationState    string   `json:"location_state"`
+	LocationZip      string   `json:"location_zip"`
+	LocationCountry  string   `json:"location_country"`
+	LocationLat      float64  `json:"location_lat"`
+	LocationLng      float64  `json:"location_lng"`
+	UserId           int      `json:"user_id"`
+	UserFirstName    string   `json:"user_first_name"`
+	UserLastName     string   `json:"user_last_name"`
+	Units            string   `json:"units"`
+	Distance         *float64 `json:"distance"`
+	DeliveryDistance *float64 `json:"delivery_distance"`
+	DeliveryFee      *int     `json:"delivery_fee"`
+	Amenities        string   `json:"amenities"`
+}
+
+// rentalRecordColumns are the CSV columns, taken from the json tags so both exports always name the fields alike.
+var rentalRecordColumns = func() (columns []string) {
+	recordType := reflect.TypeOf(RentalRecord{})
+	for index := 0; index < recordType.NumField(); index++ {
+		columns = append(columns, recordType.Field(index).Tag.Get("json"))
+	}
+	return
+}()
+
+// RentalRecordColumns returns the header of the CSV export.
+func RentalRecordColumns() []string {
+	return append([]string(nil), rentalRecordColumns...)
+}
+
+// NewRentalRecord flattens the rental. Amenities are listed by their keys, separated by semicolons.
+func NewRentalRecord(rental Rental) RentalRecord {
+	amenities := make([]string, 0, len(rental.Amenities))
+	for _, amenity := range rental.Amenities {
+		amenities = append(amenities, amenity.Key)
+	}
+
+	return RentalRecord{
+		Id:               rental.IdRental,
+		Name:             rental.Name,
+		Description:      rental.Description,
+		Type:             rental.Type,
+		Make:             rental.Make,
+		Model:            rental.Model,
+		Year:             rental.Year,
+		Length:           rental.Length,
+		Sleeps:           rental.Sleeps,
+		PrimaryImageURL:  rental.PrimaryImageURL,
+		Status:           rental.Status,
+		Created:          rental.Created.Format(time.RFC3339Nano),
+		Updated:          rental.Updated.Format(time.RFC3339Nano),
+		PriceDay:         rental.Price.Day,
+		PriceCurrency:    rental.Price.Currency,
+		LocationCity:     rental.Location.City,
+		LocationState:    rental.Location.State,
+		LocationZip:      rental.Location.Zip,
+		LocationCountry:  rental.Location.Country,
+		LocationLat:      rental.Location.Lat,
+		LocationLng:      rental.Location.Lng,
+		UserId:           rental.User.Id,
+		UserFirstName:    rental.User.FirstName,
+		UserLastName:     rental.User.LastName,
+		Units:            rental.Units,
+		Distance:         rental.Distance,
+		DeliveryDistance: rental.Delivery.Distance,
+		DeliveryFee:      rental.Delivery.Fee,
+		Amenities:        strings.Join(amenities, ";"),
+	}
+}
+
+// Values returns the CSV row of the record, in the order of RentalRecordColumns. Missing values are empty.
+func (record RentalRecord) Values() []string {
+	value := reflect.ValueOf(record)
+	values := make([]string, value.NumField())
+	for index := range values {
+		values[index] = formatRecordValue(value.Field(index))
+	}
+	return values
+}
+
+func formatRecordValue(value reflect.Value) string {
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return ""
+		}
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Int:
+		return strconv.FormatInt(value.Int(), 10)
+	case reflect.Float64:
+		return strconv.FormatFloat(value.Float(), 'f', -1, 64)
+	}
+	return value.String()
+}
@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"context"
 	validator "github.com/asaskevich/govalidator"
 	"github.com/knadh/koanf/parsers/dotenv"
 	"github.com/knadh/koanf/providers/file"
@@ -8,7 +9,9 @@ import (
 	"github.com/stretchr/testify/assert"
 	"net/url"
 	"outdoorsy-api/database"
+	"strconv"
 	"testing"
+	"time"
 )
 
 type configurations struct {
@@ -52,6 +55,26 @@ func setupTest(test *testing.T) func() {
 	}
 }
 
+// createTestDraftRental creates a draft camper van of the first user, which is deleted when the test ends.
+func createTestDraftRental(test *testing.T, name string) Rental {
+	input := RentalInput{
+		Name:     name,
+		Type:     "camper-van",
+		Sleeps:   2,
+		Price:    Price{Day: 9900},
+		Location: Location{City: "Denver", State: "CO", Country: "US", Lat: 39.67, Lng: -104.92},
+	}
+
+	rental, failedValidation, err := CreateDraftRental(1, input)
+	if err != nil || failedValidation {
+		test.Fatalf("Error on creating draft rental - %v", err)
+	}
+	test.Cleanup(func() {
+		_ = database.Exec("DELETE FROM rentals WHERE rentals.id = $1;", rental.IdRental)
+	})
+	return rental
+}
+
 func TestGetASingleRentalShouldReturnCorrectResponse(test *testing.T) {
 	defer setupTest(test)()
 	rentalId := 1
@@ -289,6 +312,74 @@ func TestGetMultipleRentalsWithSortParameterShouldSortByPrice(test *testing.T) {
 	assert.Equal(test, 13, rentals[2].IdRental, "Expected retrieved rentals id 13 to be first result")
 }
 
+func TestGetMultipleRentalsShouldReturnDescriptiveErrorInCaseOfInvalidTimestamp(test *testing.T) {
+	defer setupTest(test)()
+
+	var params = make(url.Values)
+	params.Set("updated_after", "yesterday")
+
+	rentals, failedValidation, err := GetMultipleRentals(params)
+
+	assert.Error(test, err, "Getting rentals updated after an invalid timestamp should return error")
+	assert.True(test, failedValidation, "Failed validation is expected")
+	assert.Equal(test, "updated_after must be an RFC 3339 timestamp or a YYYY-MM-DD date", err.Error(), "Correct error message is expected in case of failed validation")
+	assert.Equal(test, 0, len(rentals), "No results should be returned in case of failed validation")
+}
+
+func TestGetMultipleRentalsShouldReturnDescriptiveErrorInCaseOfUnknownSort(test *testing.T) {
+	defer setupTest(test)()
+
+	var params = make(url.Values)
+	params.Set("sort", "price_per_day; DROP TABLE rentals")
+
+	rentals, failedValidation, err := GetMultipleRentals(params)
+
+	assert.Error(test, err, "Getting rentals sorted by an unknown ordering should return error")
+	assert.True(test, failedValidation, "Failed validation is expected")
+	assert.Equal(test, "sort should be id, name, year, length, sleeps, price or newest", err.Error(), "Correct error message is expected in case of failed validation")
+	assert.Equal(test, 0, len(rentals), "No results should be returned in case of failed validation")
+}
+
+func TestGetMultipleRentalsWithRecencyParametersShouldFilterAndSortByTimestamps(test *testing.T) {
+	defer setupTest(test)()
+
+	draft := createTestDraftRental(test, "Recency van")
+	assert.False(test, draft.Created.IsZero(), "Expected the creation time of the rental")
+	assert.Equal(test, draft.Created, draft.Updated, "Expected a new rental to be updated when it's created")
+
+	submitted, _, err := TransitionRentalStatus(draft.IdRental, "submit", 1, "")
+	if err != nil {
+		test.Fatalf("Error on submitting rental - %s", err.Error())
+	}
+	assert.Equal(test, draft.Created, submitted.Created, "Expected the creation time to be kept")
+	assert.True(test, submitted.Updated.After(draft.Updated), "Expected the update time to be moved")
+
+	params := url.Values{
+		"status":        {StatusDraft + "," + StatusPendingReview + "," + StatusPublished},
+		"created_after": {draft.Created.Add(-time.Microsecond).Format(time.RFC3339Nano)},
+		"sort":          {"newest"},
+	}
+	rentals, _, err := GetMultipleRentals(params)
+	if err != nil {
+		test.Fatalf("Error on getting rentals created after the draft - %s", err.Error())
+	}
+	if assert.NotEmpty(test, rentals, "Expected the rentals created since the draft") {
+		assert.Equal(test, draft.IdRental, rentals[len(rentals)-1].IdRental, "Expected the oldest of the newest rentals last")
+	}
+	for _, rental := range rentals {
+		assert.False(test, rental.Created.Before(draft.Created), "Expected only the rentals created after the timestamp")
+	}
+
+	params = url.Values{"status": {StatusPendingReview}, "updated_after": {submitted.Updated.Format(time.RFC3339Nano)}, "ids": {strconv.Itoa(draft.IdRental)}}
+	rentals, _, _ = GetMultipleRentals(params)
+	assert.Empty(test, rentals, "Expected the rental not to be updated after its last update")
+
+	rentals, _, _ = GetMultipleRentals(url.Values{"updated_after": {"2021-11-30"}, "sort": {"newest"}, "limit": {"1"}})
+	for _, rental := range rentals {
+		assert.True(test, rental.Updated.After(time.Date(2021, 11, 30, 0, 0, 0, 0, time.UTC)), "Expected a date to mean its midnight in UTC")
+	}
+}
+
 func TestGetMultipleRentalsWithPriceMinShouldNotReturnCheaperVehicles(test *testing.T) {
 	defer setupTest(test)()
 
@@ -317,3 +408,186 @@ func TestGetMultipleRentalsWithPriceMinShouldNotReturnCheaperVehicles(test *test
 
 	assert.True(test, expectedResult, "Expected the first result to be with min price of 5k")
 }
+
+func TestGetASingleRentalShouldIncludeAmenities(test *testing.T) {
+	defer setupTest(test)()
+	rentalId := 5
+
+	rental, err := GetASingleRental(rentalId)
+	if err != nil {
+		test.Fatalf("Error on getting rental with id %d", rentalId)
+	}
+
+	assert.Equal(test, 3, len(rental.Amenities), "Expected rental 5 to have 3 amenities")
+	assert.Equal(test, "generator", rental.Amenities[0].Key, "Expected amenities to be ordered by catalog id")
+}
+
+func TestGetMultipleRentalsWithAmenitiesShouldMatchAllAmenities(test *testing.T) {
+	defer setupTest(test)()
+
+	var params = make(url.Values)
+	params.Set("amenities", "pet_friendly,generator")
+
+	rentals, failedValidation, err := GetMultipleRentals(params)
+	if err != nil {
+		test.Fatalf("Error on getting rentals with amenities parameter! - %s", err.Error())
+	}
+
+	if failedValidation {
+		test.Fatalf("There should be no failed validation in case of a valid input")
+	}
+
+	assert.Equal(test, 5, len(rentals), "Expected 5 rentals to have both a generator and be pet friendly")
+	for _, rental := range rentals {
+		keys := make([]string, 0, len(rental.Amenities))
+		for _, amenity := range rental.Amenities {
+			keys = append(keys, amenity.Key)
+		}
+		assert.Contains(test, keys, "generator", "Expected every rental to have a generator")
+		assert.Contains(test, keys, "pet_friendly", "Expected every rental to be pet friendly")
+	}
+}
+
+func TestGetMultipleRentalsShouldReturnDescriptiveErrorInCaseOfUnknownAmenity(test *testing.T) {
+	defer setupTest(test)()
+
+	var params = make(url.Values)
+	params.Set("amenities", "generator,hot_tub")
+
+	rentals, failedValidation, err := GetMultipleRentals(params)
+
+	assert.Error(test, err, "Getting rentals with unknown amenity should return error")
+	assert.True(test, failedValidation, "Getting rentals with unknown amenity should return true for failed validation")
+	assert.Equal(test, "unknown amenity: hot_tub", err.Error(), "Correct error message is expected in case of failed validation")
+	assert.Equal(test, 0, len(rentals), "No results should be returned in case of failed validation")
+}
+
+func TestGetMultipleRentalsWithCurrencyShouldFilterAndReturnConvertedPrices(test *testing.T) {
+	defer setupTest(test)()
+
+	if err := LoadExchangeRates("../exchange_rates.json"); err != nil {
+		test.Fatalf("Error on loading exchange rates - %s", err.Error())
+	}
+
+	var params = make(url.Values)
+	params.Set("currency", "EUR")
+	params.Set("price_min", "15548")
+	params.Set("sort", "price")
+
+	rentals, failedValidation, err := GetMultipleRentals(params)
+	if err != nil {
+		test.Fatalf("Error on getting rentals with currency parameter! - %s", err.Error())
+	}
+
+	if failedValidation {
+		test.Fatalf("There should be no failed validation in case of a valid input")
+	}
+
+	for _, rental := range rentals {
+		assert.Equal(test, "EUR", rental.Price.Currency, "Expected every price to be in EUR")
+		assert.GreaterOrEqual(test, rental.Price.Day, 15548, "Expected price_min to be applied in EUR")
+	}
+}
+
+func TestGetMultipleRentalsWithMetricUnitsShouldFilterByRadiusAndConvertLengths(test *testing.T) {
+	defer setupTest(test)()
+
+	var params = make(url.Values)
+	params.Set("units", "metric")
+	params.Set("near", "33.64,-117.93")
+	params.Set("radius", "100")
+	params.Set("length_min", "4.5")
+
+	rentals, failedValidation, err := GetMultipleRentals(params)
+	if err != nil {
+		test.Fatalf("Error on getting rentals with units parameter! - %s", err.Error())
+	}
+
+	if failedValidation {
+		test.Fatalf("There should be no failed validation in case of a valid input")
+	}
+
+	assert.NotEqual(test, 0, len(rentals), "Expected rentals around Costa Mesa to be found")
+	for _, rental := range rentals {
+		assert.Equal(test, "metric", rental.Units, "Expected the unit system to be echoed")
+		assert.GreaterOrEqual(test, rental.Length, 4.5, "Expected length_min to be applied in meters")
+		assert.LessOrEqual(test, *rental.Distance, 100.0, "Expected every rental to be within 100 km")
+	}
+}
+
+func TestGetMultipleRentalsShouldReturnDescriptiveErrorInCaseOfRadiusWithoutNear(test *testing.T) {
+	defer setupTest(test)()
+
+	var params = make(url.Values)
+	params.Set("radius", "100")
+
+	rentals, failedValidation, err := GetMultipleRentals(params)
+
+	assert.Error(test, err, "Getting rentals with radius but without near should return error")
+	assert.True(test, failedValidation, "Failed validation is expected")
+	assert.Equal(test, "radius can be used only together with near", err.Error(), "Correct error message is expected in case of failed validation")
+	assert.Equal(test, 0, len(rentals), "No results should be returned in case of failed validation")
+}
+
+func TestStreamMultipleRentalsShouldStopWhenTheContextIsCancelled(test *testing.T) {
+	defer setupTest(test)()
+
+	var streamed int
+	ctx, cancel := context.WithCancel(context.Background())
+	_, err := StreamMultipleRentals(ctx, url.Values{}, func(rental Rental) error {
+		streamed++
+		return nil
+	})
+	if err != nil {
+		test.Fatalf("Error on streaming rentals - %s", err.Error())
+	}
+	assert.Positive(test, streamed, "Expected the rentals to be streamed")
+
+	cancel()
+	streamed = 0
+	_, err = StreamMultipleRentals(ctx, url.Values{}, func(rental Rental) error {
+		streamed++
+		return nil
+	})
+	assert.ErrorIs(test, err, context.Canceled, "Expected the query to be cancelled with the context")
+	assert.Zero(test, streamed, "Expected no rentals to be streamed after the cancellation")
+}
+
+func TestNewRentalRecordShouldFlattenTheRentalIntoStableColumns(test *testing.T) {
+	distance := 12.5
+	rental := Rental{
+		IdRental:  7,
+		Name:      "Camper, \"cozy\"",
+		Year:      2019,
+		Length:    20.5,
+		Distance:  &distance,
+		Price:     Price{Day: 12000, Currency: "EUR"},
+		Location:  Location{City: "Denver", Lat: 39.74, Lng: -104.99},
+		User:      User{Id: 3, FirstName: "Jane"},
+		Amenities: []Amenity{{Key: "ac"}, {Key: "wifi"}},
+	}
+
+	columns := RentalRecordColumns()
+	assert.Equal(test, "id", columns[0], "Expected the id to be the first column")
+	assert.Contains(test, columns, "price_day", "Expected the price to be flattened")
+	assert.Contains(test, columns, "location_lat", "Expected the location to be flattened")
+	assert.Contains(test, columns, "user_first_name", "Expected the user to be flattened")
+
+	values := NewRentalRecord(rental).Values()
+	assert.Len(test, values, len(columns), "Expected a value for every column")
+
+	row := make(map[string]string, len(columns))
+	for index, column := range columns {
+		row[column] = values[index]
+	}
+	assert.Equal(test, "7", row["id"], "Expected the rental id")
+	assert.Equal(test, "Camper, \"cozy\"", row["name"], "Expected the name unchanged")
+	assert.Equal(test, "20.5", row["length"], "Expected the length without trailing zeros")
+	assert.Equal(test, "12000", row["price_day"], "Expected the price per day")
+	assert.Equal(test, "EUR", row["price_currency"], "Expected the price currency")
+	assert.Equal(test, "-104.99", row["location_lng"], "Expected the location longitude")
+	assert.Equal(test, "3", row["user_id"], "Expected the owner id")
+	assert.Equal(test, "12.5", row["distance"], "Expected the distance")
+	assert.Equal(test, "", row["delivery_fee"], "Expected missing values to be empty")
+	assert.Equal(test, "ac;wifi", row["amenities"], "Expected amenity keys separated by semicolons")
+}
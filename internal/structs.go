@@ -1,5 +1,12 @@
 package internal
 
+import (
+	"encoding/json"
+	"github.com/lib/pq"
+	"math/big"
+	"time"
+)
+
 type User struct {
 	Id        int    `db:"user_id" json:"id"`
 	FirstName string `db:"first_name" json:"first_name"`
@@ -16,21 +23,409 @@ type Location struct {
 }
 
 type Price struct {
-	Day int `db:"price_per_day" json:"day"`
+	Day      int    `db:"price_per_day" json:"day"`
+	Currency string `db:"-" json:"currency"`
+}
+
+type Delivery struct {
+	Radius     float64  `db:"delivery_radius" json:"radius"`
+	FeePerMile int      `db:"delivery_fee_per_mile" json:"fee_per_mile"`
+	MinimumFee int      `db:"delivery_minimum_fee" json:"minimum_fee"`
+	Distance   *float64 `db:"-" json:"distance,omitempty"`
+	Fee        *int     `db:"-" json:"fee,omitempty"`
+}
+
+type BookingRules struct {
+	MinNights        *int           `db:"min_nights" json:"min_nights"`
+	MaxNights        *int           `db:"max_nights" json:"max_nights"`
+	CheckInDays      pq.StringArray `db:"check_in_days" json:"check_in_days"`
+	NoticeHours      int            `db:"notice_hours" json:"notice_hours"`
+	MaxAdvanceMonths *int           `db:"max_advance_months" json:"max_advance_months"`
+}
+
+type Addon struct {
+	Id          int    `db:"id" json:"id"`
+	RentalId    int    `db:"rental_id" json:"rental_id"`
+	Name        string `db:"name" json:"name"`
+	Description string `db:"description" json:"description"`
+	PricingMode string `db:"pricing_mode" json:"pricing_mode"`
+	Price       int    `db:"price" json:"price"`
+	MaxQuantity *int   `db:"max_quantity" json:"max_quantity"`
+	Available   bool   `db:"available" json:"available"`
+}
+
+type AddonInput struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	PricingMode string `json:"pricing_mode"`
+	Price       int    `json:"price"`
+	MaxQuantity *int   `json:"max_quantity"`
+	Available   *bool  `json:"available"`
+}
+
+type AddonSelection struct {
+	AddonId  int `json:"id"`
+	Quantity int `json:"quantity"`
+}
+
+type Amenity struct {
+	Id   int    `db:"id" json:"id"`
+	Key  string `db:"key" json:"key"`
+	Name string `db:"name" json:"name"`
+}
+
+type rentalAmenity struct {
+	RentalId int `db:"rental_id"`
+	Amenity
 }
 
 type Rental struct {
-	IdRental        int     `db:"id" json:"id"`
-	Name            string  `db:"name" json:"name"`
-	Description     string  `db:"description" json:"description"`
-	Type            string  `db:"type" json:"type"`
-	Make            string  `db:"vehicle_make" json:"make"`
-	Model           string  `db:"vehicle_model" json:"model"`
-	Year            int     `db:"vehicle_year" json:"year"`
-	Length          float64 `db:"vehicle_length" json:"length"`
-	Sleeps          int     `db:"sleeps" json:"sleeps"`
-	PrimaryImageURL string  `db:"primary_image_url" json:"primary_image_url"`
+	IdRental        int       `db:"id" json:"id"`
+	Name            string    `db:"name" json:"name"`
+	Description     string    `db:"description" json:"description"`
+	Type            string    `db:"type" json:"type"`
+	Make            string    `db:"vehicle_make" json:"make"`
+	Model           string    `db:"vehicle_model" json:"model"`
+	Year            int       `db:"vehicle_year" json:"year"`
+	Length          float64   `db:"vehicle_length" json:"length"`
+	Sleeps          int       `db:"sleeps" json:"sleeps"`
+	PrimaryImageURL string    `db:"primary_image_url" json:"primary_image_url"`
+	Status          string    `db:"status" json:"status"`
+	Created         time.Time `db:"created" json:"created"`
+	Updated         time.Time `db:"updated" json:"updated"`
 	Price           `json:"price"`
 	Location        `json:"location"`
 	User            `json:"user"`
+	Delivery        `json:"delivery"`
+	BookingRules    `json:"booking_rules"`
+	Amenities       []Amenity `db:"-" json:"amenities"`
+	Addons          []Addon   `db:"-" json:"addons,omitempty"`
+	Distance        *float64  `db:"-" json:"distance,omitempty"`
+	Units           string    `db:"-" json:"units"`
+}
+
+type BlockedRange struct {
+	Id       int       `db:"id" json:"id"`
+	RentalId int       `db:"rental_id" json:"rental_id"`
+	UID      string    `db:"uid" json:"uid"`
+	Summary  string    `db:"summary" json:"summary"`
+	StartsOn time.Time `db:"starts_on" json:"starts_on"`
+	EndsOn   time.Time `db:"ends_on" json:"ends_on"`
+	Source   string    `db:"source" json:"source"`
+	Updated  time.Time `db:"updated" json:"updated"`
+}
+
+type BlackoutInput struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Reason string `json:"reason"`
+}
+
+type BulkOperation struct {
+	Type    string   `json:"type"`
+	Price   *int     `json:"price,omitempty"`
+	Percent *float64 `json:"percent,omitempty"`
+	Status  string   `json:"status,omitempty"`
+	BlackoutInput
+}
+
+type BulkRequest struct {
+	RentalIds  []int           `json:"rental_ids"`
+	Operations []BulkOperation `json:"operations"`
+}
+
+type ownedRental struct {
+	Id     int    `db:"id"`
+	Status string `db:"status"`
+	Price  int    `db:"price_per_day"`
+	UserId int    `db:"user_id"`
+}
+
+type BulkRentalResult struct {
+	RentalId        int               `json:"rental_id"`
+	Result          string            `json:"result"`
+	Price           int               `json:"price,omitempty"`
+	Status          string            `json:"status,omitempty"`
+	Blackouts       []BlockedRange    `json:"blackouts,omitempty"`
+	FailedOperation *int              `json:"failed_operation,omitempty"`
+	Error           map[string]string `json:"error,omitempty"`
+	err             error
+}
+
+type BulkReport struct {
+	Applied bool               `json:"applied"`
+	Rentals []BulkRentalResult `json:"rentals"`
+}
+
+type CalendarImportResult struct {
+	Created   int `json:"created"`
+	Updated   int `json:"updated"`
+	Unchanged int `json:"unchanged"`
+	Removed   int `json:"removed"`
+	// Skipped counts the events exported by the API itself, the blackouts and bookings of the rental.
+	Skipped int `json:"skipped"`
+}
+
+type ExchangeRate struct {
+	Currency   string  `json:"currency"`
+	Rate       float64 `json:"rate"`
+	MinorUnits int     `json:"minor_units"`
+	factor     *big.Rat
+}
+
+type ExchangeRatesTable struct {
+	Base     string             `json:"base"`
+	Rates    map[string]float64 `json:"rates"`
+	LoadedAt time.Time          `json:"loaded_at"`
+}
+
+type QuoteLine struct {
+	Type        string `json:"type"`
+	Description string `json:"description"`
+	Quantity    int    `json:"quantity"`
+	UnitAmount  int    `json:"unit_amount"`
+	Amount      int    `json:"amount"`
+	addonId     int
+	addonName   string
+}
+
+type Quote struct {
+	RentalId           int               `json:"rental_id"`
+	From               string            `json:"from"`
+	To                 string            `json:"to"`
+	Nights             int               `json:"nights"`
+	Currency           string            `json:"currency"`
+	Lines              []QuoteLine       `json:"lines"`
+	Total              int               `json:"total"`
+	PromoCode          string            `json:"promo_code,omitempty"`
+	PromoCodeRejection map[string]string `json:"promo_code_rejection,omitempty"`
+	promoCodeError     error
+}
+
+type RentalInput struct {
+	Name            string   `json:"name"`
+	Description     string   `json:"description"`
+	Type            string   `json:"type"`
+	Make            string   `json:"make"`
+	Model           string   `json:"model"`
+	Year            int      `json:"year"`
+	Length          float64  `json:"length"`
+	Sleeps          int      `json:"sleeps"`
+	PrimaryImageURL string   `json:"primary_image_url"`
+	Price           Price    `json:"price"`
+	Location        Location `json:"location"`
+}
+
+type StatusTransition struct {
+	Id         int       `db:"id" json:"id"`
+	RentalId   int       `db:"rental_id" json:"rental_id"`
+	FromStatus string    `db:"from_status" json:"from_status"`
+	ToStatus   string    `db:"to_status" json:"to_status"`
+	Actor      string    `db:"actor" json:"actor"`
+	ActorId    *int      `db:"actor_id" json:"actor_id"`
+	Reason     string    `db:"reason" json:"reason"`
+	Created    time.Time `db:"created" json:"created"`
+}
+
+type PromoCode struct {
+	Id                    int            `db:"id" json:"id"`
+	Code                  string         `db:"code" json:"code"`
+	DiscountType          string         `db:"discount_type" json:"discount_type"`
+	DiscountValue         int            `db:"discount_value" json:"discount_value"`
+	MinNights             int            `db:"min_nights" json:"min_nights"`
+	ValidFrom             *time.Time     `db:"valid_from" json:"valid_from"`
+	ValidUntil            *time.Time     `db:"valid_until" json:"valid_until"`
+	MaxRedemptions        *int           `db:"max_redemptions" json:"max_redemptions"`
+	MaxRedemptionsPerUser *int           `db:"max_redemptions_per_user" json:"max_redemptions_per_user"`
+	RentalTypes           pq.StringArray `db:"rental_types" json:"rental_types"`
+	RentalStates          pq.StringArray `db:"rental_states" json:"rental_states"`
+	Active                bool           `db:"active" json:"active"`
+	Redemptions           int            `db:"redemptions" json:"redemptions"`
+}
+
+type promoCodeUsage struct {
+	Total int `db:"total"`
+	User  int `db:"user_redemptions"`
+}
+
+type Booking struct {
+	Id           int       `db:"id" json:"id"`
+	RentalId     int       `db:"rental_id" json:"rental_id"`
+	UserId       int       `db:"user_id" json:"user_id"`
+	StartsOn     time.Time `db:"starts_on" json:"-"`
+	EndsOn       time.Time `db:"ends_on" json:"-"`
+	From         string    `db:"-" json:"from"`
+	To           string    `db:"-" json:"to"`
+	Status       string    `db:"status" json:"status"`
+	NightlyPrice int       `db:"nightly_price" json:"nightly_price"`
+	Discount     int       `db:"discount" json:"discount"`
+	DeliveryFee  int       `db:"delivery_fee" json:"delivery_fee"`
+	Total        int       `db:"total" json:"total"`
+	Currency     string    `db:"-" json:"currency"`
+	PromoCode    string    `db:"promo_code" json:"promo_code,omitempty"`
+	Created      time.Time `db:"created" json:"created"`
+	Updated      time.Time `db:"updated" json:"updated"`
+	Quote        *Quote    `db:"-" json:"quote,omitempty"`
+}
+
+type RentalEvent struct {
+	Id       int64     `db:"id" json:"id"`
+	RentalId int       `db:"rental_id" json:"rental_id"`
+	Kind     string    `db:"kind" json:"kind"`
+	Created  time.Time `db:"created" json:"created"`
+}
+
+// RentalChange is a rental changed since the token of a change feed page, or deleted if Rental is nil.
+type RentalChange struct {
+	RentalId int       `db:"id" json:"id"`
+	Updated  time.Time `db:"updated" json:"updated"`
+	Deleted  bool      `db:"deleted" json:"deleted"`
+	Rental   *Rental   `db:"-" json:"rental"`
+}
+
+type RentalChanges struct {
+	Changes []RentalChange `json:"changes"`
+	Next    string         `json:"next"`
+	HasMore bool           `json:"has_more"`
+}
+
+type Webhook struct {
+	Id         int            `db:"id" json:"id"`
+	URL        string         `db:"url" json:"url"`
+	EventTypes pq.StringArray `db:"event_types" json:"event_types"`
+	Secret     string         `db:"-" json:"secret,omitempty"`
+	Active     bool           `db:"active" json:"active"`
+	Created    time.Time      `db:"created" json:"created"`
+	Updated    time.Time      `db:"updated" json:"updated"`
+}
+
+type WebhookInput struct {
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+	Secret     string   `json:"secret"`
+	Active     *bool    `json:"active"`
+}
+
+type WebhookDelivery struct {
+	Id             int64           `db:"id" json:"id"`
+	WebhookId      int             `db:"webhook_id" json:"webhook_id"`
+	EventType      string          `db:"event_type" json:"event_type"`
+	Payload        json.RawMessage `db:"payload" json:"payload"`
+	Status         string          `db:"status" json:"status"`
+	Attempts       int             `db:"attempts" json:"attempts"`
+	NextAttempt    time.Time       `db:"next_attempt" json:"next_attempt"`
+	ResponseStatus *int            `db:"response_status" json:"response_status"`
+	Error          string          `db:"error" json:"error"`
+	Created        time.Time       `db:"created" json:"created"`
+	Updated        time.Time       `db:"updated" json:"updated"`
+}
+
+// pendingWebhookDelivery is a claimed delivery together with the endpoint it is sent to.
+type pendingWebhookDelivery struct {
+	Id        int64           `db:"id"`
+	WebhookId int             `db:"webhook_id"`
+	EventType string          `db:"event_type"`
+	Payload   json.RawMessage `db:"payload"`
+	Attempts  int             `db:"attempts"`
+	URL       string          `db:"url"`
+	Secret    string          `db:"secret"`
+}
+
+type rentalViewCount struct {
+	RentalId int       `db:"rental_id"`
+	Day      time.Time `db:"day"`
+	Count    int       `db:"count"`
+}
+
+type trendingScore struct {
+	RentalId int     `db:"rental_id"`
+	Views    int     `db:"views"`
+	Score    float64 `db:"score"`
+}
+
+type TrendingRental struct {
+	Rental Rental  `json:"rental"`
+	Views  int     `json:"views"`
+	Score  float64 `json:"score"`
+}
+
+type StatsMetrics struct {
+	BookedNights       int      `json:"booked_nights"`
+	AvailableNights    int      `json:"available_nights"`
+	OccupancyRate      float64  `json:"occupancy_rate"`
+	GrossRevenue       int      `json:"gross_revenue"`
+	AverageNightlyRate int      `json:"average_nightly_rate"`
+	Bookings           int      `json:"bookings"`
+	Cancellations      int      `json:"cancellations"`
+	CancellationRate   float64  `json:"cancellation_rate"`
+	Views              *int     `json:"views"`
+	ConversionRate     *float64 `json:"conversion_rate"`
+	nightlyRevenue     int
+}
+
+type StatsBucket struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	StatsMetrics
+}
+
+type RentalStats struct {
+	RentalId int           `db:"id" json:"rental_id"`
+	Name     string        `db:"name" json:"name"`
+	Totals   StatsMetrics  `db:"-" json:"totals"`
+	Buckets  []StatsBucket `db:"-" json:"buckets"`
+}
+
+type OwnerStats struct {
+	From        string        `json:"from"`
+	To          string        `json:"to"`
+	Granularity string        `json:"granularity"`
+	Currency    string        `json:"currency"`
+	Totals      StatsMetrics  `json:"totals"`
+	Buckets     []StatsBucket `json:"buckets"`
+	Rentals     []RentalStats `json:"rentals"`
+}
+
+type BookingInput struct {
+	UserId     int              `json:"user_id"`
+	From       string           `json:"from"`
+	To         string           `json:"to"`
+	Currency   string           `json:"currency"`
+	Units      string           `json:"units"`
+	PromoCode  string           `json:"promo_code"`
+	DeliveryTo string           `json:"delivery_to"`
+	Addons     []AddonSelection `json:"addons"`
+}
+
+type PriceSuggestion struct {
+	RentalId       int      `json:"rental_id"`
+	Currency       string   `json:"currency"`
+	Units          string   `json:"units"`
+	Radius         float64  `json:"radius"`
+	Price          int      `json:"price"`
+	Percentile     *float64 `json:"percentile"`
+	P25            *int     `json:"p25"`
+	Median         *int     `json:"median"`
+	P75            *int     `json:"p75"`
+	SuggestedPrice *int     `json:"suggested_price"`
+	Comparables    []Rental `json:"comparables"`
+}
+
+type rentalMileage struct {
+	IncludedMilesPerDay int     `db:"included_miles_per_day"`
+	OverageFee          int     `db:"mileage_overage_fee"`
+	Mpg                 float64 `db:"mpg"`
+}
+
+type TripEstimate struct {
+	RentalId      int         `json:"rental_id"`
+	Days          int         `json:"days"`
+	Currency      string      `json:"currency"`
+	Price         Price       `json:"price"`
+	Distance      float64     `json:"distance"`
+	IncludedMiles int         `json:"included_miles"`
+	OverageMiles  int         `json:"overage_miles"`
+	FuelGallons   float64     `json:"fuel_gallons"`
+	Lines         []QuoteLine `json:"lines"`
+	Total         int         `json:"total"`
 }
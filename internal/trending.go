@@ -0,0 +1,101 @@
+package internal
+
+import (
+	"fmt"
+	"github.com/lib/pq"
+	"math"
+	"net/url"
+	"outdoorsy-api/database"
+	"outdoorsy-api/utils"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultTrendingWindowDays = 7
+	defaultTrendingRadius     = 100
+	defaultTrendingLimit      = 20
+	maximumTrendingLimit      = 100
+)
+
+// GetTrendingRentals ranks the published rentals by their detail views within the window of days ("7d" by
+// default). Views are decayed - they lose half of their weight every half of the window. With a near point, only
+// rentals within the radius (100 by default, in the selected units) are ranked. At most limit rentals are returned,
+// 20 by default and 100 at most. If the parameters are not valid - failedValidation will be set to true and
+// descriptive validation error will be returned.
+func GetTrendingRentals(params url.Values) (trending []TrendingRental, failedValidation bool, err error) {
+	var (
+		scores            []trendingScore
+		rentals           []Rental
+		distanceCondition string
+		windowDays        = defaultTrendingWindowDays
+		limit             = defaultTrendingLimit
+	)
+
+	if err = utils.ValidateTrendingParameters(params); err != nil {
+		failedValidation = true
+		return
+	}
+
+	options, err := getRentalsQueryOptions(params)
+	if err != nil {
+		failedValidation = true
+		return
+	}
+
+	if window := params.Get("window"); window != "" {
+		windowDays, _ = strconv.Atoi(strings.TrimSuffix(window, "d"))
+	}
+	if params.Get("limit") != "" {
+		limit, _ = strconv.Atoi(params.Get("limit"))
+		limit = min(limit, maximumTrendingLimit)
+	}
+	if near := params.Get("near"); near != "" {
+		coordinates := strings.Split(near, ",")
+		radius := params.Get("radius")
+		if radius == "" {
+			radius = strconv.Itoa(defaultTrendingRadius)
+		}
+		distanceCondition = fmt.Sprintf("AND %s <= %s", options.units.sqlDistanceExpression(coordinates[0], coordinates[1]), radius)
+	}
+
+	query := fmt.Sprintf(trendingRentalScoresQuery, distanceCondition)
+	if err = database.GetMultipleRecords(&scores, query, windowDays, float64(windowDays)/2, limit); err != nil {
+		return
+	}
+
+	trending = make([]TrendingRental, 0, len(scores))
+	if len(scores) == 0 {
+		return
+	}
+
+	rentalIds := make([]int64, 0, len(scores))
+	for _, score := range scores {
+		rentalIds = append(rentalIds, int64(score.RentalId))
+	}
+	if err = database.GetMultipleRecords(&rentals, selectAllRentalsQuery+rentalsByIdsClause, pq.Int64Array(rentalIds)); err != nil {
+		return
+	}
+	if err = attachAmenities(rentals); err != nil {
+		return
+	}
+	convertRentalPrices(rentals, options.rate)
+	applyUnits(rentals, options.units, params.Get("near"))
+
+	byId := make(map[int]Rental, len(rentals))
+	for _, rental := range rentals {
+		byId[rental.IdRental] = rental
+	}
+	for _, score := range scores {
+		rental, found := byId[score.RentalId]
+		if !found {
+			continue
+		}
+		trending = append(trending, TrendingRental{
+			Rental: rental,
+			Views:  score.Views,
+			Score:  math.Round(score.Score*100) / 100,
+		})
+	}
+	return
+}
@@ -0,0 +1,138 @@
+package internal
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"outdoorsy-api/database"
+	"outdoorsy-api/utils"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	DefaultRoadFactor = 1.3
+	DefaultFuelPrice  = 400
+)
+
+// tripEstimateSettings holds the road factor which turns great-circle distances into road distances and the fuel
+// price in base currency cents per gallon.
+var tripEstimateSettings = struct {
+	sync.RWMutex
+	roadFactor float64
+	fuelPrice  int
+}{
+	roadFactor: DefaultRoadFactor,
+	fuelPrice:  DefaultFuelPrice,
+}
+
+// ConfigureTripEstimates replaces the road factor and the fuel price used by trip estimates. Values which are not
+// positive keep the defaults.
+func ConfigureTripEstimates(roadFactor float64, fuelPrice int) {
+	if roadFactor <= 0 {
+		roadFactor = DefaultRoadFactor
+	}
+	if fuelPrice <= 0 {
+		fuelPrice = DefaultFuelPrice
+	}
+
+	tripEstimateSettings.Lock()
+	defer tripEstimateSettings.Unlock()
+	tripEstimateSettings.roadFactor = roadFactor
+	tripEstimateSettings.fuelPrice = fuelPrice
+}
+
+// GetTripEstimate estimates the cost of a round trip with the rental from its home location to the destination and
+// back. The road distance is the great-circle distance times the road factor, the miles over the ones included
+// for the days of the trip are charged by the overage fee of the rental and the fuel is priced by the mpg of the
+// rental. If the parameters are not valid - failedValidation will be set to true and descriptive validation error
+// will be returned.
+func GetTripEstimate(id int, params url.Values) (estimate TripEstimate, failedValidation bool, err error) {
+	var mileage rentalMileage
+
+	if err = utils.ValidateTripEstimateParameters(params); err != nil {
+		failedValidation = true
+		return
+	}
+
+	rate, err := GetExchangeRate(params.Get("currency"))
+	if err != nil {
+		failedValidation = true
+		return
+	}
+
+	rental, err := GetASingleRental(id)
+	if err != nil {
+		return
+	}
+
+	err = database.GetSingleRecordNamedQuery(&mileage, selectRentalMileageQuery, map[string]interface{}{"id": id})
+	if err != nil {
+		return
+	}
+
+	coordinates := strings.Split(params.Get("to"), ",")
+	toLat, _ := strconv.ParseFloat(coordinates[0], 64)
+	toLng, _ := strconv.ParseFloat(coordinates[1], 64)
+	days, _ := strconv.Atoi(params.Get("days"))
+
+	tripEstimateSettings.RLock()
+	roadFactor, fuelPrice := tripEstimateSettings.roadFactor, tripEstimateSettings.fuelPrice
+	tripEstimateSettings.RUnlock()
+
+	estimate = newTripEstimate(rental, mileage, toLat, toLng, days, roadFactor, fuelPrice, rate)
+	return
+}
+
+func newTripEstimate(rental Rental, mileage rentalMileage, toLat float64, toLng float64, days int, roadFactor float64, fuelPrice int, rate ExchangeRate) (estimate TripEstimate) {
+	distance := 2 * roadFactor * utils.GreatCircleDistance(rental.Lat, rental.Lng, toLat, toLng, utils.EarthRadiusMiles)
+	includedMiles := mileage.IncludedMilesPerDay * days
+	overageMiles := int(math.Max(0, math.Ceil(distance-float64(includedMiles))))
+	fuelGallons := distance / mileage.Mpg
+	dayPrice := rate.Convert(rental.Price.Day)
+
+	estimate = TripEstimate{
+		RentalId:      rental.IdRental,
+		Days:          days,
+		Currency:      rate.Currency,
+		Price:         Price{Day: dayPrice, Currency: rate.Currency},
+		Distance:      math.Round(distance*100) / 100,
+		IncludedMiles: includedMiles,
+		OverageMiles:  overageMiles,
+		FuelGallons:   math.Round(fuelGallons*100) / 100,
+		Lines:         make([]QuoteLine, 0),
+	}
+
+	estimate.addLine(QuoteLine{
+		Type:        "daily",
+		Description: fmt.Sprintf("%d days", days),
+		Quantity:    days,
+		UnitAmount:  dayPrice,
+		Amount:      days * dayPrice,
+	})
+	if overageMiles > 0 {
+		overageFee := rate.Convert(mileage.OverageFee)
+		estimate.addLine(QuoteLine{
+			Type:        "mileage",
+			Description: fmt.Sprintf("%d miles over the %d included", overageMiles, includedMiles),
+			Quantity:    overageMiles,
+			UnitAmount:  overageFee,
+			Amount:      overageMiles * overageFee,
+		})
+	}
+	fuelCost := rate.Convert(int(math.Round(fuelGallons * float64(fuelPrice))))
+	estimate.addLine(QuoteLine{
+		Type:        "fuel",
+		Description: fmt.Sprintf("%.2f gallons at %.1f mpg", estimate.FuelGallons, mileage.Mpg),
+		Quantity:    1,
+		UnitAmount:  fuelCost,
+		Amount:      fuelCost,
+	})
+	return
+}
+
+func (estimate *TripEstimate) addLine(line QuoteLine) {
+	estimate.Lines = append(estimate.Lines, line)
+	estimate.Total += line.Amount
+}
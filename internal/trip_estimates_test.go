@@ -0,0 +1,34 @@
+package internal
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/url"
+	"testing"
+)
+
+func TestNewTripEstimateShouldItemizeDaysMileageAndFuel(test *testing.T) {
+	var (
+		rental  = Rental{IdRental: 1, Price: Price{Day: 10000}, Location: Location{Lat: 0, Lng: 0}}
+		mileage = rentalMileage{IncludedMilesPerDay: 100, OverageFee: 35, Mpg: 15}
+	)
+
+	estimate := newTripEstimate(rental, mileage, 0, 1, 1, 1, 400, newExchangeRate(BaseCurrency, 1))
+
+	assert.Equal(test, 138.19, estimate.Distance, "Expected the round trip of one degree on the equator")
+	assert.Equal(test, 39, estimate.OverageMiles, "Expected the miles over the included ones rounded up")
+	assert.Equal(test, 3, len(estimate.Lines), "Expected the days, mileage and fuel lines")
+	assert.Equal(test, 39*35, estimate.Lines[1].Amount, "Expected the overage to be charged per mile")
+	assert.Equal(test, 3685, estimate.Lines[2].Amount, "Expected 9.21 gallons at 4.00 USD")
+	assert.Equal(test, 10000+39*35+3685, estimate.Total, "Expected the total of every line")
+
+	estimate = newTripEstimate(rental, mileage, 0, 1, 2, 1, 400, newExchangeRate(BaseCurrency, 1))
+	assert.Equal(test, 2, len(estimate.Lines), "Expected no mileage line within the included miles")
+}
+
+func TestGetTripEstimateShouldReturnDescriptiveErrorInCaseOfInvalidDestination(test *testing.T) {
+	_, failedValidation, err := GetTripEstimate(1, url.Values{"to": {"95,10"}, "days": {"3"}})
+
+	assert.Error(test, err, "Estimating a trip to invalid coordinates should return error")
+	assert.True(test, failedValidation, "Failed validation is expected")
+	assert.Equal(test, "to must be a comma separated latitude and longitude pair", err.Error(), "Correct error message is expected in case of failed validation")
+}
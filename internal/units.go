@@ -0,0 +1,95 @@
+package internal
+
+import (
+	"fmt"
+	"math"
+	"outdoorsy-api/utils"
+	"strconv"
+	"strings"
+)
+
+const metersInFoot = 0.3048
+
+// unitSystem describes how vehicle lengths (stored in feet) and distances are presented to the client.
+type unitSystem struct {
+	name         string
+	lengthFactor float64
+	earthRadius  float64
+	distanceName string
+}
+
+var unitSystems = map[string]unitSystem{
+	utils.UnitsImperial: {name: utils.UnitsImperial, lengthFactor: 1, earthRadius: utils.EarthRadiusMiles, distanceName: "miles"},
+	utils.UnitsMetric:   {name: utils.UnitsMetric, lengthFactor: metersInFoot, earthRadius: utils.EarthRadiusKilometers, distanceName: "km"},
+}
+
+// getUnitSystem returns the unit system by its name, the imperial one if no name is provided. The name is
+// already validated by utils.ValidateParameters.
+func getUnitSystem(name string) unitSystem {
+	units, found := unitSystems[name]
+	if !found {
+		return unitSystems[utils.UnitsImperial]
+	}
+	return units
+}
+
+func (units unitSystem) convertLength(feet float64) float64 {
+	return math.Round(feet*units.lengthFactor*100) / 100
+}
+
+// convertDistance converts a distance in miles, like the delivery radius, to the unit system.
+func (units unitSystem) convertDistance(miles float64) float64 {
+	return math.Round(miles*units.earthRadius/utils.EarthRadiusMiles*100) / 100
+}
+
+// sqlLengthExpression returns the SQL expression of the vehicle length in the unit system, rounded the same way
+// as the lengths in the response.
+func (units unitSystem) sqlLengthExpression() string {
+	if units.lengthFactor == 1 {
+		return "vehicle_length"
+	}
+	return fmt.Sprintf("ROUND(vehicle_length * %s, 2)", strconv.FormatFloat(units.lengthFactor, 'f', -1, 64))
+}
+
+// sqlMilesExpression returns the SQL expression of the column holding a distance in miles, in the unit system.
+func (units unitSystem) sqlMilesExpression(column string) string {
+	if units.earthRadius == utils.EarthRadiusMiles {
+		return column
+	}
+	return fmt.Sprintf("%s * %s", column, strconv.FormatFloat(units.earthRadius/utils.EarthRadiusMiles, 'f', -1, 64))
+}
+
+// sqlDistanceExpression returns the SQL expression of the haversine distance between the rental and the point,
+// in the unit system. The coordinates are already validated by utils.ValidateParameters.
+func (units unitSystem) sqlDistanceExpression(lat string, lng string) string {
+	return fmt.Sprintf(
+		"(2 * %s * ASIN(LEAST(1, SQRT(POWER(SIN(RADIANS(lat - (%s)) / 2), 2) + COS(RADIANS(%s)) * COS(RADIANS(lat)) * POWER(SIN(RADIANS(lng - (%s)) / 2), 2)))))",
+		strconv.FormatFloat(units.earthRadius, 'f', -1, 64), lat, lat, lng,
+	)
+}
+
+// applyUnits converts the rental lengths and delivery radiuses to the unit system and, if a near point is
+// provided, sets the distance of every rental from it.
+func applyUnits(rentals []Rental, units unitSystem, near string) {
+	var (
+		nearLat, nearLng float64
+		hasNear          = near != ""
+	)
+
+	if hasNear {
+		coordinates := strings.Split(near, ",")
+		nearLat, _ = strconv.ParseFloat(coordinates[0], 64)
+		nearLng, _ = strconv.ParseFloat(coordinates[1], 64)
+	}
+
+	for position := range rentals {
+		rental := &rentals[position]
+		rental.Length = units.convertLength(rental.Length)
+		rental.Delivery.Radius = units.convertDistance(rental.Delivery.Radius)
+		rental.Units = units.name
+		if hasNear {
+			distance := math.Round(utils.GreatCircleDistance(nearLat, nearLng, rental.Lat, rental.Lng, units.earthRadius)*100) / 100
+			rental.Distance = &distance
+		}
+	}
+}
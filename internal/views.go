@@ -0,0 +1,194 @@
+package internal
+
+import (
+	"github.com/lib/pq"
+	log "github.com/sirupsen/logrus"
+	"outdoorsy-api/database"
+	"outdoorsy-api/utils"
+	"sync"
+	"time"
+)
+
+const (
+	ViewKindDetail     = "view"
+	ViewKindImpression = "impression"
+
+	DefaultViewsFlushInterval = 10 * time.Second
+	DefaultViewsBatchSize     = 1000
+
+	// maximumPendingViewCounts bounds the counts kept while the database is unavailable, newer counts are dropped.
+	maximumPendingViewCounts = 100000
+)
+
+type viewKey struct {
+	rentalId int
+	day      string
+	kind     string
+}
+
+// viewCollector buffers the view counts in memory, so recording a view doesn't hit the database. The counts are
+// aggregated per rental, day and kind and written in a single statement when the batch is full or on the interval.
+type viewCollector struct {
+	sync.Mutex
+	pending   map[viewKey]int
+	events    int
+	batchSize int
+	flush     chan struct{}
+	stop      chan struct{}
+	stopped   chan struct{}
+}
+
+// rentalViews is the collector of the process, nil until StartViewCollector is called. Views recorded before that
+// are not counted.
+var rentalViews *viewCollector
+
+func newViewCollector(batchSize int) *viewCollector {
+	return &viewCollector{
+		pending:   make(map[viewKey]int),
+		batchSize: batchSize,
+		flush:     make(chan struct{}, 1),
+		stop:      make(chan struct{}),
+		stopped:   make(chan struct{}),
+	}
+}
+
+// StartViewCollector starts counting rental views and impressions and flushing them to the database on the
+// interval or whenever batchSize views are buffered. Values which are not positive keep the defaults.
+func StartViewCollector(flushInterval time.Duration, batchSize int) {
+	if flushInterval <= 0 {
+		flushInterval = DefaultViewsFlushInterval
+	}
+	if batchSize <= 0 {
+		batchSize = DefaultViewsBatchSize
+	}
+
+	rentalViews = newViewCollector(batchSize)
+	go rentalViews.run(flushInterval)
+}
+
+// StopViewCollector writes the buffered counts to the database and stops the collector, views recorded afterwards
+// are not counted. Call it once the server doesn't take requests anymore.
+func StopViewCollector() {
+	if rentalViews == nil {
+		return
+	}
+	close(rentalViews.stop)
+	<-rentalViews.stopped
+}
+
+// RecordRentalView counts a view of the rental detail.
+func RecordRentalView(rentalId int) {
+	if rentalViews != nil {
+		rentalViews.record(time.Now(), ViewKindDetail, rentalId)
+	}
+}
+
+// RecordSearchImpressions counts an impression of every rental listed in search results.
+func RecordSearchImpressions(rentals []Rental) {
+	if rentalViews == nil || len(rentals) == 0 {
+		return
+	}
+
+	rentalIds := make([]int, 0, len(rentals))
+	for _, rental := range rentals {
+		rentalIds = append(rentalIds, rental.IdRental)
+	}
+	rentalViews.record(time.Now(), ViewKindImpression, rentalIds...)
+}
+
+func (collector *viewCollector) record(now time.Time, kind string, rentalIds ...int) {
+	day := now.UTC().Format(utils.DateFormat)
+
+	collector.Lock()
+	defer collector.Unlock()
+
+	for _, rentalId := range rentalIds {
+		key := viewKey{rentalId: rentalId, day: day, kind: kind}
+		if _, found := collector.pending[key]; !found && len(collector.pending) >= maximumPendingViewCounts {
+			continue
+		}
+		collector.pending[key]++
+		collector.events++
+	}
+
+	if collector.events >= collector.batchSize {
+		select {
+		case collector.flush <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// drain takes the buffered counts, leaving the collector empty.
+func (collector *viewCollector) drain() (counts map[viewKey]int) {
+	collector.Lock()
+	defer collector.Unlock()
+
+	counts = collector.pending
+	collector.pending = make(map[viewKey]int, len(counts))
+	collector.events = 0
+	return
+}
+
+// restore puts back the counts which failed to be written, so they are retried with the next batch.
+func (collector *viewCollector) restore(counts map[viewKey]int) {
+	collector.Lock()
+	defer collector.Unlock()
+
+	for key, count := range counts {
+		if _, found := collector.pending[key]; !found && len(collector.pending) >= maximumPendingViewCounts {
+			continue
+		}
+		collector.pending[key] += count
+	}
+}
+
+func (collector *viewCollector) run(flushInterval time.Duration) {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-collector.flush:
+		case <-collector.stop:
+			collector.write()
+			close(collector.stopped)
+			return
+		}
+
+		collector.write()
+	}
+}
+
+// write flushes the buffered counts to the database, the counts which failed to be written are kept for the next
+// flush.
+func (collector *viewCollector) write() {
+	counts := collector.drain()
+	if len(counts) == 0 {
+		return
+	}
+	if err := writeViewCounts(counts); err != nil {
+		utils.GetLogger().WithFields(log.Fields{"error": err.Error(), "counts": len(counts)}).Error("Error on flushing rental views")
+		collector.restore(counts)
+	}
+}
+
+// writeViewCounts adds the counts to the stored ones in a single statement. Counts of removed rentals are dropped.
+func writeViewCounts(counts map[viewKey]int) error {
+	var (
+		rentalIds = make(pq.Int64Array, 0, len(counts))
+		days      = make(pq.StringArray, 0, len(counts))
+		kinds     = make(pq.StringArray, 0, len(counts))
+		values    = make(pq.Int64Array, 0, len(counts))
+	)
+
+	for key, count := range counts {
+		rentalIds = append(rentalIds, int64(key.rentalId))
+		days = append(days, key.day)
+		kinds = append(kinds, key.kind)
+		values = append(values, int64(count))
+	}
+
+	return database.Exec(upsertRentalViewCountsQuery, rentalIds, days, kinds, values)
+}
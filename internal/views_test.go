@@ -0,0 +1,102 @@
+package internal
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/url"
+	"outdoorsy-api/database"
+	"outdoorsy-api/utils"
+	"testing"
+	"time"
+)
+
+func TestViewCollectorShouldAggregateCountsAndSignalAFullBatch(test *testing.T) {
+	var (
+		collector = newViewCollector(5)
+		now       = time.Date(2026, 6, 1, 23, 30, 0, 0, time.UTC)
+	)
+
+	collector.record(now, ViewKindDetail, 1)
+	collector.record(now, ViewKindDetail, 1)
+	collector.record(now, ViewKindImpression, 1, 2)
+	assert.Len(test, collector.flush, 0, "Expected no flush before the batch is full")
+
+	collector.record(now.Add(time.Hour), ViewKindDetail, 1)
+	assert.Len(test, collector.flush, 1, "Expected a flush once the batch is full")
+
+	counts := collector.drain()
+	assert.Equal(test, map[viewKey]int{
+		{rentalId: 1, day: "2026-06-01", kind: ViewKindDetail}:     2,
+		{rentalId: 1, day: "2026-06-01", kind: ViewKindImpression}: 1,
+		{rentalId: 2, day: "2026-06-01", kind: ViewKindImpression}: 1,
+		{rentalId: 1, day: "2026-06-02", kind: ViewKindDetail}:     1,
+	}, counts, "Expected the views aggregated per rental, day and kind")
+	assert.Empty(test, collector.pending, "Expected the collector to be empty after draining")
+
+	collector.record(now, ViewKindDetail, 1)
+	collector.restore(counts)
+	assert.Equal(test, 3, collector.pending[viewKey{rentalId: 1, day: "2026-06-01", kind: ViewKindDetail}], "Expected failed counts to be added back")
+}
+
+func TestStoppingTheViewCollectorShouldFlushTheBufferedViews(test *testing.T) {
+	defer setupTest(test)()
+
+	day := time.Date(2001, 1, 1, 12, 0, 0, 0, time.UTC)
+	test.Cleanup(func() {
+		_ = database.Exec("DELETE FROM rental_view_counts WHERE day = $1;", day.Format(utils.DateFormat))
+	})
+
+	collector := newViewCollector(DefaultViewsBatchSize)
+	go collector.run(time.Hour)
+	collector.record(day, ViewKindDetail, 1, 1)
+	close(collector.stop)
+	<-collector.stopped
+
+	var counts []int
+	err := database.GetMultipleRecords(&counts, "SELECT count FROM rental_view_counts WHERE rental_id = 1 AND day = $1 AND kind = $2;", day.Format(utils.DateFormat), ViewKindDetail)
+	if err != nil {
+		test.Fatalf("Error on getting view counts - %s", err.Error())
+	}
+	assert.Equal(test, []int{2}, counts, "Expected the buffered views to be written when the collector stops")
+}
+
+func TestGetTrendingRentalsShouldReturnDescriptiveErrorInCaseOfInvalidParameters(test *testing.T) {
+	testCases := map[string]url.Values{
+		"window should be a number of days from 1d to 90d": {"window": {"7h"}},
+		"radius can be used only together with near":       {"radius": {"10"}},
+	}
+	for message, params := range testCases {
+		_, failedValidation, err := GetTrendingRentals(params)
+		assert.True(test, failedValidation, "Invalid parameters should fail validation")
+		assert.Equal(test, message, err.Error(), "Correct error message is expected")
+	}
+}
+
+func TestGetTrendingRentalsShouldRankRentalsByViews(test *testing.T) {
+	defer setupTest(test)()
+
+	day := time.Now().UTC().Format(utils.DateFormat)
+	counts := map[viewKey]int{
+		{rentalId: 3, day: day, kind: ViewKindDetail}: 100000,
+		{rentalId: 5, day: day, kind: ViewKindDetail}: 50000,
+	}
+	if err := writeViewCounts(counts); err != nil {
+		test.Fatalf("Error on writing view counts - %s", err.Error())
+	}
+	// the views of the test are taken back, the rows which only had them are deleted
+	test.Cleanup(func() {
+		for key, count := range counts {
+			counts[key] = -count
+		}
+		_ = writeViewCounts(counts)
+		_ = database.Exec("DELETE FROM rental_view_counts WHERE rental_id IN (3, 5) AND day = $1 AND kind = $2 AND count = 0;", day, ViewKindDetail)
+	})
+
+	trending, _, err := GetTrendingRentals(url.Values{"window": {"1d"}, "limit": {"2"}})
+	if err != nil {
+		test.Fatalf("Error on getting trending rentals - %s", err.Error())
+	}
+
+	assert.Len(test, trending, 2, "Expected the requested number of rentals")
+	assert.Equal(test, 3, trending[0].Rental.IdRental, "Expected the most viewed rental first")
+	assert.GreaterOrEqual(test, trending[0].Score, trending[1].Score, "Expected rentals ordered by score")
+}
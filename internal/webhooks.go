@@ -0,0 +1,312 @@
+package internal
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+	"net/url"
+	"outdoorsy-api/database"
+	"outdoorsy-api/utils"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryDead      = "dead"
+
+	DefaultWebhooksPollInterval = 5 * time.Second
+
+	// maximumWebhookAttempts is how many times a delivery is sent before it is dead-lettered.
+	maximumWebhookAttempts = 10
+	webhookBackoffBase     = 30 * time.Second
+	webhookBackoffMaximum  = 6 * time.Hour
+	webhookTimeout         = 10 * time.Second
+	webhookBatchSize       = 50
+	// webhookLease is how long a claimed delivery is skipped by the other instances, longer than a batch takes.
+	webhookLease = 60 * time.Second
+
+	minimumWebhookSecretLength   = 16
+	defaultWebhookDeliveriesPage = 50
+	maximumWebhookDeliveriesPage = 100
+)
+
+// WebhookEventTypes are the events a webhook can subscribe to. They are queued by database triggers, see
+// sql-init.sql.
+var WebhookEventTypes = []string{"rental.created", "rental.updated", "rental.deleted", "booking.created", "booking.cancelled"}
+
+// CreateWebhook subscribes the url to the event types. Without a secret, a random one is generated. The secret is
+// returned only here, it signs every delivery. If the input is not valid - failedValidation will be set to true and
+// descriptive validation error will be returned.
+func CreateWebhook(input WebhookInput) (webhook Webhook, failedValidation bool, err error) {
+	var id int
+
+	if input.Secret == "" {
+		if input.Secret, err = generateWebhookSecret(); err != nil {
+			return
+		}
+	}
+	if err = validateWebhookInput(input); err != nil {
+		failedValidation = true
+		return
+	}
+
+	err = database.GetSingleRecordNamedQuery(&id, insertWebhookQuery, webhookArguments(0, input))
+	if err != nil {
+		return
+	}
+
+	webhook, err = GetWebhook(id)
+	webhook.Secret = input.Secret
+	return
+}
+
+func GetWebhooks() (webhooks []Webhook, err error) {
+	err = database.GetMultipleRecords(&webhooks, selectAllWebhooksQuery)
+	return
+}
+
+func GetWebhook(id int) (webhook Webhook, err error) {
+	err = database.GetSingleRecordNamedQuery(&webhook, selectWebhookQuery, map[string]interface{}{"id": id})
+	return
+}
+
+// UpdateWebhook replaces the url, event types and active flag of the webhook. The secret is rotated only if one is
+// given. Deliveries already queued are sent with the new url and secret.
+func UpdateWebhook(id int, input WebhookInput) (webhook Webhook, failedValidation bool, err error) {
+	var updatedId int
+
+	if err = validateWebhookInput(input); err != nil {
+		failedValidation = true
+		return
+	}
+
+	err = database.GetSingleRecordNamedQuery(&updatedId, updateWebhookQuery, webhookArguments(id, input))
+	if err != nil {
+		return
+	}
+
+	webhook, err = GetWebhook(id)
+	return
+}
+
+// DeleteWebhook removes the webhook together with its deliveries.
+func DeleteWebhook(id int) error {
+	deleted, err := database.ExecNamedQuery(deleteWebhookQuery, map[string]interface{}{"id": id})
+	if err == nil && deleted == 0 {
+		err = sql.ErrNoRows
+	}
+	return err
+}
+
+// GetWebhookDeliveries retrieves the deliveries of the webhook, newest first, optionally of a single status. If the
+// parameters are not valid - failedValidation will be set to true and descriptive validation error will be returned.
+func GetWebhookDeliveries(webhookId int, params url.Values) (deliveries []WebhookDelivery, failedValidation bool, err error) {
+	if err = utils.ValidateWebhookDeliveriesParameters(params); err != nil {
+		failedValidation = true
+		return
+	}
+
+	if _, err = GetWebhook(webhookId); err != nil {
+		return
+	}
+
+	limit, offset := defaultWebhookDeliveriesPage, 0
+	if value := params.Get("limit"); value != "" {
+		limit, _ = strconv.Atoi(value)
+		limit = min(limit, maximumWebhookDeliveriesPage)
+	}
+	if value := params.Get("offset"); value != "" {
+		offset, _ = strconv.Atoi(value)
+	}
+
+	err = database.GetMultipleRecords(&deliveries, selectWebhookDeliveriesQuery, webhookId, params.Get("status"), limit, offset)
+	return
+}
+
+// StartWebhookDispatcher sends the queued deliveries which are due every poll interval. Several instances can run
+// it together, each delivery is claimed by one of them. A value which is not positive keeps the default.
+func StartWebhookDispatcher(pollInterval time.Duration) {
+	if pollInterval <= 0 {
+		pollInterval = DefaultWebhooksPollInterval
+	}
+
+	go func() {
+		client := newWebhookClient()
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if _, err := dispatchWebhookDeliveries(client); err != nil {
+				utils.GetLogger().WithFields(log.Fields{"error": err.Error()}).Error("Error on dispatching webhook deliveries")
+			}
+		}
+	}()
+}
+
+// newWebhookClient returns the client which sends the deliveries. Redirects are not followed, they would send the
+// signed payload to a url which was never subscribed, so they fail like any other response which is not 2xx.
+func newWebhookClient() *http.Client {
+	return &http.Client{
+		Timeout: webhookTimeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// dispatchWebhookDeliveries claims a batch of due deliveries, sends them concurrently and records the outcomes. It
+// returns how many deliveries were sent.
+func dispatchWebhookDeliveries(client *http.Client) (int, error) {
+	var claimed []pendingWebhookDelivery
+
+	err := database.GetMultipleRecords(&claimed, claimWebhookDeliveriesQuery, webhookBatchSize, int(webhookLease.Seconds()))
+	if err != nil {
+		return 0, err
+	}
+
+	var group sync.WaitGroup
+	for _, delivery := range claimed {
+		group.Add(1)
+		go func(delivery pendingWebhookDelivery) {
+			defer group.Done()
+
+			responseStatus, sendErr := sendWebhookDelivery(client, delivery, time.Now())
+			err := recordWebhookAttempt(delivery, responseStatus, sendErr, time.Now())
+			if err != nil {
+				utils.GetLogger().WithFields(log.Fields{"error": err.Error(), "id": delivery.Id}).Error("Error on recording webhook delivery")
+			}
+		}(delivery)
+	}
+	group.Wait()
+	return len(claimed), nil
+}
+
+// sendWebhookDelivery posts the payload signed with the secret of the webhook. Responses other than 2xx fail.
+func sendWebhookDelivery(client *http.Client, delivery pendingWebhookDelivery, now time.Time) (responseStatus *int, err error) {
+	request, err := http.NewRequest(http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "outdoorsy-api-webhooks")
+	request.Header.Set("X-Webhook-Event", delivery.EventType)
+	request.Header.Set("X-Webhook-Delivery", strconv.FormatInt(delivery.Id, 10))
+	request.Header.Set("X-Signature", signWebhookPayload(delivery.Secret, now, delivery.Payload))
+
+	response, err := client.Do(request)
+	if err != nil {
+		return
+	}
+	_ = response.Body.Close()
+
+	responseStatus = &response.StatusCode
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		err = fmt.Errorf("unexpected response status %d", response.StatusCode)
+	}
+	return
+}
+
+// recordWebhookAttempt marks the delivery succeeded, or schedules its next attempt, or dead-letters it once it
+// failed maximumWebhookAttempts times.
+func recordWebhookAttempt(delivery pendingWebhookDelivery, responseStatus *int, sendErr error, now time.Time) error {
+	var (
+		attempts    = delivery.Attempts + 1
+		status      = WebhookDeliverySucceeded
+		nextAttempt = now
+		message     string
+	)
+
+	if sendErr != nil {
+		message = sendErr.Error()
+		status = WebhookDeliveryPending
+		nextAttempt = now.Add(webhookBackoff(attempts))
+		if attempts >= maximumWebhookAttempts {
+			status = WebhookDeliveryDead
+		}
+	}
+
+	_, err := database.ExecNamedQuery(updateWebhookDeliveryQuery, map[string]interface{}{
+		"id":              delivery.Id,
+		"status":          status,
+		"attempts":        attempts,
+		"next_attempt":    nextAttempt,
+		"response_status": responseStatus,
+		"error":           message,
+	})
+	return err
+}
+
+// webhookBackoff is the delay after the failed attempt, doubling from webhookBackoffBase up to
+// webhookBackoffMaximum.
+func webhookBackoff(attempts int) time.Duration {
+	delay := webhookBackoffBase
+	for i := 1; i < attempts && delay < webhookBackoffMaximum; i++ {
+		delay *= 2
+	}
+	return min(delay, webhookBackoffMaximum)
+}
+
+// signWebhookPayload returns the X-Signature header, t=<unix timestamp>,v1=<signature>. The signature is the hex
+// HMAC-SHA256 of "<timestamp>.<payload>" with the secret of the webhook, so receivers can reject replayed requests
+// by their timestamp.
+func signWebhookPayload(secret string, now time.Time, payload []byte) string {
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(payload)
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func generateWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+func validateWebhookInput(input WebhookInput) error {
+	if input.URL == "" {
+		return utils.NewLocalizedError("field_required", "url")
+	}
+	if endpoint, err := url.Parse(input.URL); err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return utils.NewLocalizedError("field_invalid", "url")
+	}
+	if len(input.EventTypes) == 0 {
+		return utils.NewLocalizedError("field_required", "event_types")
+	}
+	for _, eventType := range input.EventTypes {
+		if !containsFold(WebhookEventTypes, eventType) {
+			return utils.NewLocalizedError("field_invalid", "event_types")
+		}
+	}
+	if input.Secret != "" && len(input.Secret) < minimumWebhookSecretLength {
+		return utils.NewLocalizedError("field_invalid", "secret")
+	}
+	return nil
+}
+
+// webhookArguments normalizes the event types, webhooks are active unless told otherwise.
+func webhookArguments(id int, input WebhookInput) map[string]interface{} {
+	active := input.Active == nil || *input.Active
+	eventTypes := normalizeRestrictions(input.EventTypes, strings.ToLower)
+
+	return map[string]interface{}{
+		"id":          id,
+		"url":         input.URL,
+		"event_types": eventTypes,
+		"secret":      input.Secret,
+		"active":      active,
+	}
+}
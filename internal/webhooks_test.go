@@ -0,0 +1,168 @@
+package internal
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSignWebhookPayloadShouldSignTheTimestampAndThePayload(test *testing.T) {
+	var (
+		now     = time.Unix(1792368000, 0)
+		payload = []byte(`{"type":"rental.updated","data":{"id":1}}`)
+	)
+
+	mac := hmac.New(sha256.New, []byte("0123456789abcdef"))
+	mac.Write([]byte("1792368000." + string(payload)))
+	expected := "t=1792368000,v1=" + hex.EncodeToString(mac.Sum(nil))
+
+	assert.Equal(test, expected, signWebhookPayload("0123456789abcdef", now, payload), "Expected the HMAC-SHA256 of the timestamp and the payload")
+	assert.NotEqual(test, expected, signWebhookPayload("0123456789abcdef", now.Add(time.Second), payload), "Expected the signature to change with the timestamp")
+}
+
+func TestWebhookBackoffShouldDoubleUpToTheMaximum(test *testing.T) {
+	assert.Equal(test, 30*time.Second, webhookBackoff(1), "Expected the base delay after the first attempt")
+	assert.Equal(test, 4*time.Minute, webhookBackoff(4), "Expected the delay to double after every attempt")
+	assert.Equal(test, webhookBackoffMaximum, webhookBackoff(20), "Expected the delay to be capped")
+}
+
+func TestSendWebhookDeliveryShouldNotFollowRedirects(test *testing.T) {
+	var followed atomic.Bool
+	target := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		followed.Store(true)
+	}))
+	defer target.Close()
+	receiver := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusTemporaryRedirect))
+	defer receiver.Close()
+
+	delivery := pendingWebhookDelivery{Id: 1, EventType: "rental.created", Payload: []byte(`{"id": 1}`), URL: receiver.URL, Secret: "secret"}
+	responseStatus, err := sendWebhookDelivery(newWebhookClient(), delivery, time.Now())
+	assert.Error(test, err, "Expected a redirect to fail the delivery")
+	if assert.NotNil(test, responseStatus, "Expected the response status to be recorded") {
+		assert.Equal(test, http.StatusTemporaryRedirect, *responseStatus, "Expected the status of the redirect")
+	}
+	assert.False(test, followed.Load(), "Expected the signed payload not to be sent to the redirect location")
+}
+
+func TestCreateWebhookShouldReturnDescriptiveErrorInCaseOfInvalidInput(test *testing.T) {
+	valid := WebhookInput{URL: "https://partner.example.com/hooks", EventTypes: []string{"rental.updated"}}
+	testCases := map[string]func(input *WebhookInput){
+		"url is required":         func(input *WebhookInput) { input.URL = "" },
+		"url is invalid":          func(input *WebhookInput) { input.URL = "ftp://partner.example.com" },
+		"event_types is required": func(input *WebhookInput) { input.EventTypes = nil },
+		"event_types is invalid":  func(input *WebhookInput) { input.EventTypes = []string{"rental.viewed"} },
+		"secret is invalid":       func(input *WebhookInput) { input.Secret = "short" },
+	}
+	for message, change := range testCases {
+		input := valid
+		change(&input)
+		_, failedValidation, err := CreateWebhook(input)
+		assert.True(test, failedValidation, "Invalid input should fail validation")
+		assert.Equal(test, message, err.Error(), "Correct error message is expected")
+	}
+}
+
+func TestWebhookDeliveriesShouldBeSignedRetriedAndDeadLettered(test *testing.T) {
+	defer setupTest(test)()
+
+	var (
+		received = make(chan *http.Request, 10)
+		bodies   = make(chan []byte, 10)
+		status   atomic.Int32
+	)
+	receiver := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		body, _ := io.ReadAll(request.Body)
+		received <- request
+		bodies <- body
+		writer.WriteHeader(int(status.Load()))
+	}))
+	defer receiver.Close()
+	status.Store(http.StatusOK)
+
+	webhook, failedValidation, err := CreateWebhook(WebhookInput{URL: receiver.URL, EventTypes: []string{"rental.created"}})
+	if err != nil || failedValidation {
+		test.Fatalf("Error on creating webhook - %v", err)
+	}
+	defer func() {
+		_ = DeleteWebhook(webhook.Id)
+	}()
+	assert.Len(test, webhook.Secret, 64, "Expected a generated secret")
+
+	rental := createTestDraftRental(test, "Webhook van")
+
+	if _, err = dispatchWebhookDeliveries(receiver.Client()); err != nil {
+		test.Fatalf("Error on dispatching webhook deliveries - %s", err.Error())
+	}
+	request, body := <-received, <-bodies
+	assert.Equal(test, "rental.created", request.Header.Get("X-Webhook-Event"), "Expected the event type header")
+	assert.Contains(test, string(body), `"id": `+strconv.Itoa(rental.IdRental), "Expected the id of the rental in the payload")
+
+	signature := request.Header.Get("X-Signature")
+	timestamp := strings.TrimPrefix(strings.Split(signature, ",")[0], "t=")
+	mac := hmac.New(sha256.New, []byte(webhook.Secret))
+	mac.Write([]byte(timestamp + "." + string(body)))
+	assert.Equal(test, "t="+timestamp+",v1="+hex.EncodeToString(mac.Sum(nil)), signature, "Expected the payload signed with the secret")
+
+	deliveries, _, err := GetWebhookDeliveries(webhook.Id, url.Values{})
+	if err != nil {
+		test.Fatalf("Error on getting webhook deliveries - %s", err.Error())
+	}
+	if assert.Len(test, deliveries, 1, "Expected a single delivery") {
+		assert.Equal(test, WebhookDeliverySucceeded, deliveries[0].Status, "Expected the delivery to succeed")
+		assert.Equal(test, 1, deliveries[0].Attempts, "Expected a single attempt")
+	}
+
+	status.Store(http.StatusServiceUnavailable)
+	createTestDraftRental(test, "Webhook van")
+	if _, err = dispatchWebhookDeliveries(receiver.Client()); err != nil {
+		test.Fatalf("Error on dispatching webhook deliveries - %s", err.Error())
+	}
+	<-received
+
+	deliveries, _, err = GetWebhookDeliveries(webhook.Id, url.Values{"status": {WebhookDeliveryPending}})
+	if err != nil {
+		test.Fatalf("Error on getting webhook deliveries - %s", err.Error())
+	}
+	if assert.Len(test, deliveries, 1, "Expected the failed delivery to be retried") {
+		failed := deliveries[0]
+		assert.Equal(test, http.StatusServiceUnavailable, *failed.ResponseStatus, "Expected the response status to be recorded")
+		assert.True(test, failed.NextAttempt.After(time.Now().Add(20*time.Second)), "Expected the retry to be delayed")
+
+		err = recordWebhookAttempt(pendingWebhookDelivery{Id: failed.Id, Attempts: maximumWebhookAttempts - 1}, nil, errors.New("connection refused"), time.Now())
+		if err != nil {
+			test.Fatalf("Error on recording webhook attempt - %s", err.Error())
+		}
+	}
+
+	deliveries, _, _ = GetWebhookDeliveries(webhook.Id, url.Values{"status": {WebhookDeliveryDead}})
+	if assert.Len(test, deliveries, 1, "Expected the delivery to be dead-lettered after the last attempt") {
+		assert.Equal(test, "connection refused", deliveries[0].Error, "Expected the error of the last attempt")
+	}
+
+	createTestDraftRental(test, "Webhook van")
+	inactive := false
+	_, _, err = UpdateWebhook(webhook.Id, WebhookInput{URL: receiver.URL, EventTypes: []string{"rental.created"}, Active: &inactive})
+	if err != nil {
+		test.Fatalf("Error on deactivating webhook - %s", err.Error())
+	}
+	if _, err = dispatchWebhookDeliveries(receiver.Client()); err != nil {
+		test.Fatalf("Error on dispatching webhook deliveries - %s", err.Error())
+	}
+	assert.Empty(test, received, "Expected nothing to be sent to an inactive webhook")
+
+	deliveries, _, _ = GetWebhookDeliveries(webhook.Id, url.Values{"status": {WebhookDeliveryPending}})
+	if assert.Len(test, deliveries, 1, "Expected the delivery of the inactive webhook to stay pending") {
+		assert.Equal(test, 0, deliveries[0].Attempts, "Expected the delivery not to be attempted")
+	}
+}
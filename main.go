@@ -1,13 +1,31 @@
 package main
 
 import (
+	"flag"
 	log "github.com/sirupsen/logrus"
 	"outdoorsy-api/config"
 	"outdoorsy-api/database"
+	"outdoorsy-api/internal"
 	"outdoorsy-api/server"
 	"outdoorsy-api/utils"
+	"time"
 )
 
+var (
+	calendarPath   = flag.String("import-calendar", "", "path to an ICS file to import as blocked ranges instead of starting the server")
+	calendarRental = flag.Int("rental", 0, "id of the rental the imported calendar belongs to")
+	calendarOwner  = flag.Int("owner", 0, "id of the user who owns the rental of the imported calendar")
+	serverOptions  server.Options
+	workerOptions  workers
+)
+
+// workers holds the configuration of the background work of the server, which isn't started to import calendars.
+type workers struct {
+	viewsFlushInterval   time.Duration
+	viewsBatchSize       int
+	webhooksPollInterval time.Duration
+}
+
 func init() {
 	app, err := config.Init()
 	if err != nil {
@@ -18,8 +36,38 @@ func init() {
 		utils.PrettyPrint(app)
 	}
 	database.Init(app.DBHosts, app.DBUsername, app.DBPassword, app.DBPort, app.DBName)
+	unversionedSunset, _ := time.Parse(utils.DateFormat, app.UnversionedSunset)
+	serverOptions = server.Options{AdminToken: app.AdminToken, GRPCPort: app.GRPCPort, UnversionedSunset: unversionedSunset}
+	internal.ConfigureTripEstimates(app.RoadFactor, app.FuelPrice)
+	workerOptions = workers{
+		viewsFlushInterval:   time.Duration(app.ViewsFlushSeconds) * time.Second,
+		viewsBatchSize:       app.ViewsBatchSize,
+		webhooksPollInterval: time.Duration(app.WebhooksPollSeconds) * time.Second,
+	}
+	if err = internal.LoadExchangeRates(app.ExchangeRatesPath); err != nil {
+		utils.GetLogger().WithFields(log.Fields{"error": err.Error(), "path": app.ExchangeRatesPath}).Error("Error on loading exchange rates")
+	}
 }
 
 func main() {
-	server.Run()
+	flag.Parse()
+	if *calendarPath != "" {
+		importCalendar()
+		return
+	}
+
+	internal.StartViewCollector(workerOptions.viewsFlushInterval, workerOptions.viewsBatchSize)
+	internal.StartRentalEventListener()
+	internal.StartWebhookDispatcher(workerOptions.webhooksPollInterval)
+	server.Run(serverOptions)
+	internal.StopViewCollector()
+}
+
+func importCalendar() {
+	result, _, err := internal.ImportRentalCalendarFromFile(*calendarOwner, *calendarRental, *calendarPath)
+	if err != nil {
+		utils.GetLogger().WithFields(log.Fields{"error": err.Error(), "path": *calendarPath, "owner": *calendarOwner, "id": *calendarRental}).Error("Error on importing rental calendar")
+		return
+	}
+	utils.PrettyPrint(result)
 }
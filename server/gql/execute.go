@@ -0,0 +1,83 @@
+package gql
+
+import (
+	"context"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"net/url"
+	"outdoorsy-api/internal"
+	"outdoorsy-api/utils"
+)
+
+// Request is the body of a GraphQL POST request.
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// requestState is shared by the resolvers of one request: its language and units, whether it is made by an admin
+// and the loaders batching its lookups.
+type requestState struct {
+	language     string
+	units        string
+	admin        bool
+	users        *loader[internal.User]
+	ownerRentals *loader[[]internal.Rental]
+}
+
+type stateKey struct{}
+
+// The lookups batched by the loaders of a request.
+var (
+	getUsersByIds               = internal.GetUsersByIds
+	getPublishedRentalsByOwners = internal.GetPublishedRentalsByOwners
+)
+
+func newRequestState(language string, units string, admin bool) *requestState {
+	state := &requestState{language: language, units: units, admin: admin}
+	state.users = newLoader(func(ids []int) (map[int]internal.User, error) {
+		return getUsersByIds(ids)
+	})
+	state.ownerRentals = newLoader(func(ownerIds []int) (map[int][]internal.Rental, error) {
+		rentals, _, err := getPublishedRentalsByOwners(ownerIds, state.presentationParams())
+		return rentals, err
+	})
+	return state
+}
+
+func stateFromContext(ctx context.Context) *requestState {
+	return ctx.Value(stateKey{}).(*requestState)
+}
+
+// presentationParams returns the query string with the default units of the request.
+func (state *requestState) presentationParams() url.Values {
+	params := url.Values{}
+	if state.units != "" {
+		params.Set("units", state.units)
+	}
+	return params
+}
+
+// Execute runs the request against the rentals schema. Queries exceeding the depth or complexity limits are
+// rejected before any of their fields is resolved. Messages are in the language, units default to the given ones
+// (the ones of the API when empty). Only admins can query rentals which are not published.
+func Execute(ctx context.Context, request Request, language string, units string, admin bool) *graphql.Result {
+	document, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{Body: []byte(request.Query), Name: "GraphQL request"})})
+	if err == nil {
+		if err = checkQueryLimits(document, request.OperationName, request.Variables); err != nil {
+			localized := err.(utils.LocalizedError)
+			return &graphql.Result{Errors: []gqlerrors.FormattedError{gqlerrors.NewFormattedError(localized.Localize(language))}}
+		}
+	}
+
+	return graphql.Do(graphql.Params{
+		Schema:         schema,
+		RequestString:  request.Query,
+		VariableValues: request.Variables,
+		OperationName:  request.OperationName,
+		Context:        context.WithValue(ctx, stateKey{}, newRequestState(language, units, admin)),
+	})
+}
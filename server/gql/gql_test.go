@@ -0,0 +1,156 @@
+package gql
+
+import (
+	"context"
+	validator "github.com/asaskevich/govalidator"
+	"github.com/knadh/koanf/parsers/dotenv"
+	"github.com/knadh/koanf/providers/file"
+	"github.com/knadh/koanf/v2"
+	"github.com/stretchr/testify/assert"
+	"net/url"
+	"outdoorsy-api/database"
+	"outdoorsy-api/internal"
+	"testing"
+)
+
+type configurations struct {
+	DBHosts    string `json:"db_hosts" koanf:"DB_HOSTS" valid:"required"`
+	DBUsername string `json:"db_username" koanf:"DB_USERNAME" valid:"required"`
+	DBPassword string `json:"db_password" koanf:"DB_PASSWORD" valid:"required"`
+	DBPort     string `json:"db_port" koanf:"DB_PORT" valid:"required"`
+	DBName     string `json:"db_name" koanf:"DB_NAME" valid:"required"`
+}
+
+func setupTest(test *testing.T) func() {
+	var (
+		parser = koanf.New(".")
+		config configurations
+	)
+
+	err := parser.Load(file.Provider("../../config.env"), dotenv.Parser())
+	if err != nil {
+		test.Fatal(err.Error())
+	}
+
+	err = parser.Unmarshal("", &config)
+	if err != nil {
+		test.Fatal(err.Error())
+	}
+
+	_, err = validator.ValidateStruct(config)
+	if err != nil {
+		test.Fatal(err.Error())
+	}
+
+	database.Init(
+		config.DBHosts,
+		config.DBUsername,
+		config.DBPassword,
+		config.DBPort,
+		config.DBName,
+	)
+
+	return func() {
+	}
+}
+
+func TestLoaderShouldBatchTheIdsOfALevel(test *testing.T) {
+	var batches [][]int
+	loader := newLoader(func(ids []int) (map[int]string, error) {
+		batches = append(batches, ids)
+		names := make(map[int]string)
+		for _, id := range ids {
+			if id != 3 {
+				names[id] = "user"
+			}
+		}
+		return names, nil
+	})
+
+	first, second, missing := loader.load(1), loader.load(2), loader.load(3)
+	again := loader.load(1)
+
+	name, found, err := first()
+	assert.NoError(test, err, "Expected no error")
+	assert.True(test, found, "Expected the first user to be found")
+	assert.Equal(test, "user", name, "Expected the loaded value")
+	_, found, _ = second()
+	assert.True(test, found, "Expected the second user from the same batch")
+	_, found, _ = missing()
+	assert.False(test, found, "Expected the missing user not to be found")
+	_, found, _ = again()
+	assert.True(test, found, "Expected a repeated id to be found")
+
+	_, _, _ = loader.load(2)()
+	assert.Equal(test, [][]int{{1, 2, 3}}, batches, "Expected a single fetch of the distinct ids")
+}
+
+func TestExecuteShouldRejectQueriesOverTheLimits(test *testing.T) {
+	testCases := map[string]string{
+		"query depth 9 exceeds the maximum of 8": `{ rentals { edges { node { owner { rentals { owner { rentals { owner { id } } } } } } } } }`,
+		"query complexity 4401 exceeds the maximum of 1000": `query Rentals($first: Int) { rentals(first: $first) { edges { node { ...owner } } } }
+			fragment owner on Rental { owner { rentals { id name } } }`,
+	}
+
+	for expected, query := range testCases {
+		result := Execute(context.Background(), Request{Query: query, Variables: map[string]interface{}{"first": float64(100)}}, "en", "", false)
+		if assert.Len(test, result.Errors, 1, "Expected a single error") {
+			assert.Equal(test, expected, result.Errors[0].Message, "Expected the exceeded limit")
+		}
+		assert.Nil(test, result.Data, "Expected nothing to be resolved")
+	}
+}
+
+func TestExecuteShouldValidateThePaginationArguments(test *testing.T) {
+	result := Execute(context.Background(), Request{Query: `{ rentals(first: 500) { edges { cursor } } }`}, "de", "", false)
+	if assert.Len(test, result.Errors, 1, "Expected a single error") {
+		assert.Equal(test, "first muss eine Zahl von 1 bis 100 sein", result.Errors[0].Message, "Expected a localized error")
+	}
+
+	result = Execute(context.Background(), Request{Query: `{ rentals(after: "nope") { edges { cursor } } }`}, "en", "", false)
+	if assert.Len(test, result.Errors, 1, "Expected a single error") {
+		assert.Equal(test, "after is not a valid cursor", result.Errors[0].Message, "Expected an invalid cursor")
+	}
+
+	result = Execute(context.Background(), Request{Query: `{ rentals(filter: {status: ["draft"]}) { edges { cursor } } }`}, "en", "", false)
+	if assert.Len(test, result.Errors, 1, "Expected a single error") {
+		assert.Equal(test, "admin authorization is required to list rentals which are not published", result.Errors[0].Message, "Expected drafts to require the admin token")
+	}
+
+	offset, err := decodeCursor(encodeCursor(40))
+	assert.NoError(test, err, "Expected an encoded cursor to be valid")
+	assert.Equal(test, 40, offset, "Expected the offset of the cursor")
+}
+
+func TestExecuteShouldLookUpTheOwnersOfALevelOnce(test *testing.T) {
+	defer setupTest(test)()
+
+	var ownerBatches, rentalBatches [][]int
+	users, ownerRentals := getUsersByIds, getPublishedRentalsByOwners
+	getUsersByIds = func(ids []int) (map[int]internal.User, error) {
+		ownerBatches = append(ownerBatches, ids)
+		return users(ids)
+	}
+	getPublishedRentalsByOwners = func(ids []int, params url.Values) (map[int][]internal.Rental, bool, error) {
+		rentalBatches = append(rentalBatches, ids)
+		return ownerRentals(ids, params)
+	}
+	defer func() {
+		getUsersByIds, getPublishedRentalsByOwners = users, ownerRentals
+	}()
+
+	result := Execute(context.Background(), Request{Query: `{ rentals(first: 20) { edges { node { id owner { id firstName rentals { id } } } } } }`}, "en", "", false)
+	assert.Empty(test, result.Errors, "Expected no errors")
+
+	edges := result.Data.(map[string]interface{})["rentals"].(map[string]interface{})["edges"].([]interface{})
+	owners := make(map[int]bool)
+	for _, edge := range edges {
+		owner := edge.(map[string]interface{})["node"].(map[string]interface{})["owner"].(map[string]interface{})
+		owners[owner["id"].(int)] = true
+	}
+	assert.Len(test, edges, 20, "Expected the requested number of rentals")
+	if assert.Len(test, ownerBatches, 1, "Expected the owners of every rental to be looked up at once") {
+		assert.Len(test, ownerBatches[0], len(owners), "Expected every owner to be looked up once")
+	}
+	assert.Len(test, rentalBatches, 1, "Expected the rentals of every owner to be looked up at once")
+}
@@ -0,0 +1,113 @@
+package gql
+
+import (
+	"github.com/graphql-go/graphql/language/ast"
+	"outdoorsy-api/utils"
+	"strconv"
+	"strings"
+)
+
+const (
+	maximumQueryDepth      = 8
+	maximumQueryComplexity = 1000
+
+	// defaultListSize is the estimated length of the rentals lists which are not limited by a first argument.
+	defaultListSize = defaultFirst
+)
+
+// checkQueryLimits rejects operations nested deeper than maximumQueryDepth fields or whose complexity exceeds
+// maximumQueryComplexity. Every field costs one, the fields below a rentals list cost once per listed rental - the
+// first argument (at most maximumFirst) or defaultListSize. Introspection fields are not counted.
+func checkQueryLimits(document *ast.Document, operationName string, variables map[string]interface{}) error {
+	fragments := make(map[string]*ast.FragmentDefinition)
+	for _, definition := range document.Definitions {
+		if fragment, ok := definition.(*ast.FragmentDefinition); ok {
+			fragments[fragment.Name.Value] = fragment
+		}
+	}
+
+	for _, definition := range document.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+		if !ok || operationName != "" && (operation.Name == nil || operation.Name.Value != operationName) {
+			continue
+		}
+
+		measure := queryMeasure{fragments: fragments, variables: variables, visiting: make(map[string]bool)}
+		if depth := measure.depth(operation.SelectionSet); depth > maximumQueryDepth {
+			return utils.NewLocalizedError("graphql_query_too_deep", depth, maximumQueryDepth)
+		}
+		if complexity := measure.complexity(operation.SelectionSet); complexity > maximumQueryComplexity {
+			return utils.NewLocalizedError("graphql_query_too_complex", complexity, maximumQueryComplexity)
+		}
+	}
+	return nil
+}
+
+type queryMeasure struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+	visiting  map[string]bool
+}
+
+func (measure queryMeasure) depth(selectionSet *ast.SelectionSet) (depth int) {
+	measure.eachField(selectionSet, func(field *ast.Field) {
+		depth = max(depth, 1+measure.depth(field.SelectionSet))
+	})
+	return
+}
+
+func (measure queryMeasure) complexity(selectionSet *ast.SelectionSet) (complexity int) {
+	measure.eachField(selectionSet, func(field *ast.Field) {
+		complexity += 1 + measure.listSize(field)*measure.complexity(field.SelectionSet)
+	})
+	return
+}
+
+// eachField calls the function with the fields of the selection set, including the ones of its fragments. Fragment
+// cycles are left to the validation of the query.
+func (measure queryMeasure) eachField(selectionSet *ast.SelectionSet, function func(field *ast.Field)) {
+	if selectionSet == nil {
+		return
+	}
+
+	for _, selection := range selectionSet.Selections {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			if !strings.HasPrefix(selection.Name.Value, "__") {
+				function(selection)
+			}
+		case *ast.InlineFragment:
+			measure.eachField(selection.SelectionSet, function)
+		case *ast.FragmentSpread:
+			name := selection.Name.Value
+			if fragment, found := measure.fragments[name]; found && !measure.visiting[name] {
+				measure.visiting[name] = true
+				measure.eachField(fragment.SelectionSet, function)
+				delete(measure.visiting, name)
+			}
+		}
+	}
+}
+
+func (measure queryMeasure) listSize(field *ast.Field) int {
+	for _, argument := range field.Arguments {
+		if argument.Name.Value != "first" {
+			continue
+		}
+		switch value := argument.Value.(type) {
+		case *ast.IntValue:
+			if first, err := strconv.Atoi(value.Value); err == nil {
+				return min(max(first, 1), maximumFirst)
+			}
+		case *ast.Variable:
+			if first, ok := measure.variables[value.Name.Value].(float64); ok {
+				return min(max(int(first), 1), maximumFirst)
+			}
+		}
+	}
+
+	if field.Name.Value == "rentals" {
+		return defaultListSize
+	}
+	return 1
+}
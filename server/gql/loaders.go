@@ -0,0 +1,68 @@
+package gql
+
+import (
+	"sync"
+)
+
+// loader batches the lookups of one request by id. The ids requested while a level of the query is resolved are
+// queued and loaded with a single fetch as soon as the first of their thunks is called, which the executor does
+// only after the whole level is resolved. Every id is fetched at most once per request.
+type loader[V any] struct {
+	sync.Mutex
+	fetch   func(ids []int) (map[int]V, error)
+	pending []int
+	results map[int]loadResult[V]
+}
+
+type loadResult[V any] struct {
+	value V
+	found bool
+	err   error
+}
+
+func newLoader[V any](fetch func(ids []int) (map[int]V, error)) *loader[V] {
+	return &loader[V]{fetch: fetch, results: make(map[int]loadResult[V])}
+}
+
+// load queues the id and returns the thunk resolving it. found is false for ids the fetch didn't return.
+func (loader *loader[V]) load(id int) func() (value V, found bool, err error) {
+	loader.Lock()
+	if _, done := loader.results[id]; !done {
+		loader.pending = append(loader.pending, id)
+	}
+	loader.Unlock()
+
+	return func() (value V, found bool, err error) {
+		loader.Lock()
+		defer loader.Unlock()
+
+		if len(loader.pending) > 0 {
+			loader.flush()
+		}
+		result := loader.results[id]
+		return result.value, result.found, result.err
+	}
+}
+
+func (loader *loader[V]) flush() {
+	var (
+		ids  = make([]int, 0, len(loader.pending))
+		seen = make(map[int]bool, len(loader.pending))
+	)
+	for _, id := range loader.pending {
+		if _, done := loader.results[id]; !done && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	loader.pending = nil
+	if len(ids) == 0 {
+		return
+	}
+
+	values, err := loader.fetch(ids)
+	for _, id := range ids {
+		value, found := values[id]
+		loader.results[id] = loadResult[V]{value: value, found: found, err: err}
+	}
+}
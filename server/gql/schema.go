@@ -0,0 +1,415 @@
+package gql
+
+import (
+	"encoding/base64"
+	"errors"
+	"github.com/graphql-go/graphql"
+	log "github.com/sirupsen/logrus"
+	"net/url"
+	"outdoorsy-api/internal"
+	"outdoorsy-api/utils"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultFirst = 20
+	maximumFirst = 100
+
+	cursorPrefix = "offset:"
+)
+
+var priceType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Price",
+	Fields: graphql.Fields{
+		"day":      &graphql.Field{Type: graphql.Int, Description: "Price per day in the minor unit of the currency."},
+		"currency": &graphql.Field{Type: graphql.String},
+	},
+})
+
+var locationType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Location",
+	Fields: graphql.Fields{
+		"city":    &graphql.Field{Type: graphql.String},
+		"state":   &graphql.Field{Type: graphql.String},
+		"zip":     &graphql.Field{Type: graphql.String},
+		"country": &graphql.Field{Type: graphql.String},
+		"lat":     &graphql.Field{Type: graphql.Float},
+		"lng":     &graphql.Field{Type: graphql.Float},
+	},
+})
+
+// userType and rentalType refer to each other, so their fields are added once both exist.
+var userType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "User",
+	Fields: graphql.Fields{
+		"id":        &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		"firstName": &graphql.Field{Type: graphql.String},
+		"lastName":  &graphql.Field{Type: graphql.String},
+	},
+})
+
+var rentalType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Rental",
+	Fields: graphql.Fields{
+		"id":              &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		"name":            &graphql.Field{Type: graphql.String},
+		"description":     &graphql.Field{Type: graphql.String},
+		"type":            &graphql.Field{Type: graphql.String},
+		"make":            &graphql.Field{Type: graphql.String},
+		"model":           &graphql.Field{Type: graphql.String},
+		"year":            &graphql.Field{Type: graphql.Int},
+		"length":          &graphql.Field{Type: graphql.Float},
+		"sleeps":          &graphql.Field{Type: graphql.Int},
+		"primaryImageUrl": &graphql.Field{Type: graphql.String},
+		"status":          &graphql.Field{Type: graphql.String},
+		"created":         &graphql.Field{Type: graphql.DateTime},
+		"updated":         &graphql.Field{Type: graphql.DateTime},
+		"price":           &graphql.Field{Type: priceType},
+		"location":        &graphql.Field{Type: locationType},
+		"distance":        &graphql.Field{Type: graphql.Float},
+		"units":           &graphql.Field{Type: graphql.String},
+		"amenities": &graphql.Field{
+			Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String))),
+			Description: "Amenity keys.",
+			Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+				rental := params.Source.(internal.Rental)
+				keys := make([]string, 0, len(rental.Amenities))
+				for _, amenity := range rental.Amenities {
+					keys = append(keys, amenity.Key)
+				}
+				return keys, nil
+			},
+		},
+	},
+})
+
+var sortType = graphql.NewEnum(graphql.EnumConfig{
+	Name: "RentalSort",
+	Values: graphql.EnumValueConfigMap{
+		"ID":     &graphql.EnumValueConfig{Value: "id"},
+		"PRICE":  &graphql.EnumValueConfig{Value: "price"},
+		"NAME":   &graphql.EnumValueConfig{Value: "name"},
+		"NEWEST": &graphql.EnumValueConfig{Value: "newest"},
+	},
+})
+
+var latLngType = graphql.NewInputObject(graphql.InputObjectConfig{
+	Name: "LatLng",
+	Fields: graphql.InputObjectConfigFieldMap{
+		"lat": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.Float)},
+		"lng": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.Float)},
+	},
+})
+
+// filterType has the filters of the GET /rentals query string.
+var filterType = graphql.NewInputObject(graphql.InputObjectConfig{
+	Name: "RentalFilter",
+	Fields: graphql.InputObjectConfigFieldMap{
+		"priceMin":     &graphql.InputObjectFieldConfig{Type: graphql.Float},
+		"priceMax":     &graphql.InputObjectFieldConfig{Type: graphql.Float},
+		"ids":          &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.Int))},
+		"near":         &graphql.InputObjectFieldConfig{Type: latLngType},
+		"radius":       &graphql.InputObjectFieldConfig{Type: graphql.Float},
+		"amenities":    &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+		"currency":     &graphql.InputObjectFieldConfig{Type: graphql.String},
+		"units":        &graphql.InputObjectFieldConfig{Type: graphql.String},
+		"lengthMin":    &graphql.InputObjectFieldConfig{Type: graphql.Float},
+		"deliveryTo":   &graphql.InputObjectFieldConfig{Type: latLngType},
+		"from":         &graphql.InputObjectFieldConfig{Type: graphql.String},
+		"to":           &graphql.InputObjectFieldConfig{Type: graphql.String},
+		"status":       &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+		"createdAfter": &graphql.InputObjectFieldConfig{Type: graphql.String},
+		"updatedAfter": &graphql.InputObjectFieldConfig{Type: graphql.String},
+	},
+})
+
+var edgeType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "RentalEdge",
+	Fields: graphql.Fields{
+		"cursor": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"node":   &graphql.Field{Type: graphql.NewNonNull(rentalType)},
+	},
+})
+
+var pageInfoType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "PageInfo",
+	Fields: graphql.Fields{
+		"hasNextPage": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+		"endCursor":   &graphql.Field{Type: graphql.String},
+	},
+})
+
+var connectionType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "RentalConnection",
+	Fields: graphql.Fields{
+		"edges":    &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(edgeType)))},
+		"pageInfo": &graphql.Field{Type: graphql.NewNonNull(pageInfoType)},
+	},
+})
+
+type rentalEdge struct {
+	Cursor string          `json:"cursor"`
+	Node   internal.Rental `json:"node"`
+}
+
+type pageInfo struct {
+	HasNextPage bool    `json:"hasNextPage"`
+	EndCursor   *string `json:"endCursor"`
+}
+
+type rentalConnection struct {
+	Edges    []rentalEdge `json:"edges"`
+	PageInfo pageInfo     `json:"pageInfo"`
+}
+
+var schema = newSchema()
+
+func newSchema() graphql.Schema {
+	userType.AddFieldConfig("rentals", &graphql.Field{
+		Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(rentalType))),
+		Description: "Published rentals of the user.",
+		Resolve:     resolveUserRentals,
+	})
+	rentalType.AddFieldConfig("owner", &graphql.Field{
+		Type:    graphql.NewNonNull(userType),
+		Resolve: resolveOwner,
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"rental": &graphql.Field{
+				Type: rentalType,
+				Args: graphql.FieldConfigArgument{
+					"id":       &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+					"currency": &graphql.ArgumentConfig{Type: graphql.String},
+					"units":    &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: resolveRental,
+			},
+			"rentals": &graphql.Field{
+				Type: graphql.NewNonNull(connectionType),
+				Args: graphql.FieldConfigArgument{
+					"filter": &graphql.ArgumentConfig{Type: filterType},
+					"sort":   &graphql.ArgumentConfig{Type: sortType, DefaultValue: "id"},
+					"first":  &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultFirst},
+					"after":  &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: resolveRentals,
+			},
+			"user": &graphql.Field{
+				Type: userType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+				},
+				Resolve: resolveUser,
+			},
+		},
+	})
+
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{Query: query})
+	if err != nil {
+		panic(err)
+	}
+	return schema
+}
+
+func resolveRental(params graphql.ResolveParams) (interface{}, error) {
+	state := stateFromContext(params.Context)
+	query := state.presentationParams()
+	setParam(query, "currency", params.Args["currency"])
+	setParam(query, "units", params.Args["units"])
+
+	rental, failedValidation, err := internal.GetASingleRentalWithParameters(params.Args["id"].(int), query, state.admin)
+	if err != nil {
+		if err.Error() == "sql: no rows in result set" {
+			return nil, nil
+		}
+		return nil, state.fail(err, failedValidation, "Error on getting single rental from the database")
+	}
+
+	internal.RecordRentalView(rental.IdRental)
+	return rental, nil
+}
+
+// resolveRentals pages through the rentals of the filter. Cursors are opaque offsets, one more rental than
+// requested is read to tell whether there is a next page.
+func resolveRentals(params graphql.ResolveParams) (interface{}, error) {
+	state := stateFromContext(params.Context)
+
+	first := params.Args["first"].(int)
+	if first < 1 || first > maximumFirst {
+		return nil, state.fail(utils.NewLocalizedError("graphql_first_invalid", maximumFirst), true, "")
+	}
+	offset := 0
+	if after, found := params.Args["after"].(string); found {
+		var err error
+		if offset, err = decodeCursor(after); err != nil {
+			return nil, state.fail(err, true, "")
+		}
+	}
+
+	query := filterParams(state.presentationParams(), params.Args["filter"])
+	if !state.admin {
+		if err := utils.ValidatePublicStatuses(query.Get("status")); err != nil {
+			return nil, state.fail(err, true, "")
+		}
+	}
+	query.Set("sort", params.Args["sort"].(string))
+	query.Set("limit", strconv.Itoa(first+1))
+	query.Set("offset", strconv.Itoa(offset))
+
+	rentals, failedValidation, err := internal.GetMultipleRentals(query)
+	if err != nil && err.Error() != "sql: no rows in result set" {
+		return nil, state.fail(err, failedValidation, "Error on getting all rentals from the database")
+	}
+
+	connection := rentalConnection{Edges: make([]rentalEdge, 0, first)}
+	if len(rentals) > first {
+		rentals = rentals[:first]
+		connection.PageInfo.HasNextPage = true
+	}
+	for position, rental := range rentals {
+		connection.Edges = append(connection.Edges, rentalEdge{Cursor: encodeCursor(offset + position + 1), Node: rental})
+	}
+	if len(connection.Edges) > 0 {
+		connection.PageInfo.EndCursor = &connection.Edges[len(connection.Edges)-1].Cursor
+	}
+
+	internal.RecordSearchImpressions(rentals)
+	return connection, nil
+}
+
+func resolveUser(params graphql.ResolveParams) (interface{}, error) {
+	state := stateFromContext(params.Context)
+	thunk := state.users.load(params.Args["id"].(int))
+
+	return func() (interface{}, error) {
+		user, found, err := thunk()
+		if err != nil {
+			return nil, state.fail(err, false, "Error on getting users from the database")
+		}
+		if !found {
+			return nil, nil
+		}
+		return user, nil
+	}, nil
+}
+
+// resolveOwner loads the owners of all the rentals of a level with a single lookup. An owner which is not found
+// keeps the name read with the rental.
+func resolveOwner(params graphql.ResolveParams) (interface{}, error) {
+	var (
+		state  = stateFromContext(params.Context)
+		rental = params.Source.(internal.Rental)
+		thunk  = state.users.load(rental.User.Id)
+	)
+
+	return func() (interface{}, error) {
+		user, found, err := thunk()
+		if err != nil {
+			return nil, state.fail(err, false, "Error on getting owners from the database")
+		}
+		if !found {
+			return rental.User, nil
+		}
+		return user, nil
+	}, nil
+}
+
+func resolveUserRentals(params graphql.ResolveParams) (interface{}, error) {
+	state := stateFromContext(params.Context)
+	thunk := state.ownerRentals.load(params.Source.(internal.User).Id)
+
+	return func() (interface{}, error) {
+		rentals, _, err := thunk()
+		if err != nil {
+			return nil, state.fail(err, false, "Error on getting owner rentals from the database")
+		}
+		if rentals == nil {
+			rentals = make([]internal.Rental, 0)
+		}
+		return rentals, nil
+	}, nil
+}
+
+// filterParams builds the GET /rentals query string of the filter argument, so it is validated the same way.
+func filterParams(query url.Values, filter interface{}) url.Values {
+	fields, _ := filter.(map[string]interface{})
+	for field, param := range map[string]string{
+		"priceMin":     "price_min",
+		"priceMax":     "price_max",
+		"ids":          "ids",
+		"near":         "near",
+		"radius":       "radius",
+		"amenities":    "amenities",
+		"currency":     "currency",
+		"units":        "units",
+		"lengthMin":    "length_min",
+		"deliveryTo":   "delivery_to",
+		"from":         "from",
+		"to":           "to",
+		"status":       "status",
+		"createdAfter": "created_after",
+		"updatedAfter": "updated_after",
+	} {
+		setParam(query, param, fields[field])
+	}
+	return query
+}
+
+func setParam(query url.Values, param string, value interface{}) {
+	switch value := value.(type) {
+	case string:
+		if value != "" {
+			query.Set(param, value)
+		}
+	case int:
+		query.Set(param, strconv.Itoa(value))
+	case float64:
+		query.Set(param, strconv.FormatFloat(value, 'f', -1, 64))
+	case map[string]interface{}:
+		query.Set(param, strconv.FormatFloat(value["lat"].(float64), 'f', -1, 64)+","+strconv.FormatFloat(value["lng"].(float64), 'f', -1, 64))
+	case []interface{}:
+		values := make([]string, 0, len(value))
+		for _, element := range value {
+			single := url.Values{}
+			setParam(single, param, element)
+			values = append(values, single.Get(param))
+		}
+		if len(values) > 0 {
+			query.Set(param, strings.Join(values, ","))
+		}
+	}
+}
+
+func encodeCursor(offset int) string {
+	return base64.StdEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(offset)))
+}
+
+func decodeCursor(cursor string) (offset int, err error) {
+	decoded, err := base64.StdEncoding.DecodeString(cursor)
+	if err == nil {
+		offset, err = strconv.Atoi(strings.TrimPrefix(string(decoded), cursorPrefix))
+	}
+	if err != nil || !strings.HasPrefix(string(decoded), cursorPrefix) || offset < 0 {
+		return 0, utils.NewLocalizedError("graphql_cursor_invalid")
+	}
+	return
+}
+
+// fail returns the validation and localized errors in the language of the request and logs the others, which are
+// returned without their details.
+func (state *requestState) fail(err error, failedValidation bool, message string) error {
+	var localized utils.LocalizedError
+	if errors.As(err, &localized) {
+		return errors.New(localized.Localize(state.language))
+	}
+	if failedValidation {
+		return err
+	}
+
+	utils.GetLogger().WithFields(log.Fields{"error": err.Error()}).Error(message)
+	return errors.New("internal error")
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+	"outdoorsy-api/internal"
+	"outdoorsy-api/utils"
+	"strconv"
+)
+
+func CreateAddonHandler(ginCtx *gin.Context) {
+	var input internal.AddonInput
+
+	ownerId, rentalId, ok := ownerRentalIds(ginCtx)
+	if !ok {
+		return
+	}
+
+	if err := ginCtx.ShouldBindJSON(&input); err != nil {
+		ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, utils.NewLocalizedError("request_body_invalid")))
+		return
+	}
+
+	addon, failedValidation, err := internal.CreateAddon(ownerId, rentalId, input)
+	if err != nil {
+		if failedValidation {
+			ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, err))
+			return
+		}
+
+		if err.Error() == "sql: no rows in result set" {
+			ginCtx.JSON(http.StatusNotFound, errorResponse(ginCtx, utils.NewLocalizedError("rental_not_found")))
+			return
+		}
+
+		utils.GetLogger().WithFields(log.Fields{"error": err.Error(), "id": rentalId}).Error("Error on creating add-on")
+		ginCtx.JSON(http.StatusInternalServerError, addon)
+		return
+	}
+
+	ginCtx.JSON(http.StatusCreated, addon)
+}
+
+func UpdateAddonHandler(ginCtx *gin.Context) {
+	var input internal.AddonInput
+
+	ownerId, rentalId, ok := ownerRentalIds(ginCtx)
+	if !ok {
+		return
+	}
+
+	addonId, err := strconv.Atoi(ginCtx.Param("addon_id"))
+	if err != nil {
+		ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, utils.NewLocalizedError("id_not_integer")))
+		return
+	}
+
+	if err = ginCtx.ShouldBindJSON(&input); err != nil {
+		ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, utils.NewLocalizedError("request_body_invalid")))
+		return
+	}
+
+	addon, failedValidation, err := internal.UpdateAddon(ownerId, rentalId, addonId, input)
+	if err != nil {
+		if failedValidation {
+			ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, err))
+			return
+		}
+
+		if err.Error() == "sql: no rows in result set" {
+			ginCtx.JSON(http.StatusNotFound, errorResponse(ginCtx, utils.NewLocalizedError("addon_not_found")))
+			return
+		}
+
+		utils.GetLogger().WithFields(log.Fields{"error": err.Error(), "id": addonId}).Error("Error on updating add-on")
+		ginCtx.JSON(http.StatusInternalServerError, addon)
+		return
+	}
+
+	ginCtx.JSON(http.StatusOK, addon)
+}
+
+func DeleteAddonHandler(ginCtx *gin.Context) {
+	ownerId, rentalId, ok := ownerRentalIds(ginCtx)
+	if !ok {
+		return
+	}
+
+	addonId, err := strconv.Atoi(ginCtx.Param("addon_id"))
+	if err != nil {
+		ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, utils.NewLocalizedError("id_not_integer")))
+		return
+	}
+
+	err = internal.DeleteAddon(ownerId, rentalId, addonId)
+	if err != nil {
+		if err.Error() == "sql: no rows in result set" {
+			ginCtx.JSON(http.StatusNotFound, errorResponse(ginCtx, utils.NewLocalizedError("addon_not_found")))
+			return
+		}
+
+		utils.GetLogger().WithFields(log.Fields{"error": err.Error(), "id": addonId}).Error("Error on deleting add-on")
+		ginCtx.Status(http.StatusInternalServerError)
+		return
+	}
+
+	ginCtx.Status(http.StatusNoContent)
+}
+
+// ownerRentalIds parses the owner and rental ids of the path, responding with 400 if any of them is invalid.
+func ownerRentalIds(ginCtx *gin.Context) (ownerId int, rentalId int, ok bool) {
+	ownerId, err := strconv.Atoi(ginCtx.Param("id"))
+	if err != nil {
+		ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, utils.NewLocalizedError("id_not_integer")))
+		return
+	}
+
+	rentalId, err = strconv.Atoi(ginCtx.Param("rental_id"))
+	if err != nil {
+		ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, utils.NewLocalizedError("id_not_integer")))
+		return
+	}
+	return ownerId, rentalId, true
+}
@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+	"outdoorsy-api/internal"
+	"outdoorsy-api/utils"
+)
+
+func AmenitiesHandler(ginCtx *gin.Context) {
+	amenities, err := internal.GetAmenities()
+	if err != nil {
+		utils.GetLogger().WithFields(log.Fields{"error": err.Error()}).Error("Error on getting amenities from the database")
+		ginCtx.JSON(http.StatusInternalServerError, amenities)
+		return
+	}
+
+	ginCtx.JSON(http.StatusOK, amenities)
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+	"outdoorsy-api/internal"
+	"outdoorsy-api/utils"
+	"strconv"
+)
+
+func CreateBookingHandler(ginCtx *gin.Context) {
+	var (
+		input     internal.BookingInput
+		localized utils.LocalizedError
+	)
+
+	rentalId, err := strconv.Atoi(ginCtx.Param("id"))
+	if err != nil {
+		ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, utils.NewLocalizedError("id_not_integer")))
+		return
+	}
+
+	if err = ginCtx.ShouldBindJSON(&input); err != nil {
+		ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, utils.NewLocalizedError("request_body_invalid")))
+		return
+	}
+
+	if input.Units == "" && ginCtx.GetHeader("Accept-Language") != "" {
+		input.Units = utils.UnitsForLanguage(ginCtx.GetHeader("Accept-Language"))
+	}
+
+	booking, failedValidation, err := internal.CreateBooking(rentalId, input)
+	if err != nil {
+		if failedValidation {
+			ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, err))
+			return
+		}
+
+		if err.Error() == "sql: no rows in result set" {
+			ginCtx.JSON(http.StatusNotFound, errorResponse(ginCtx, utils.NewLocalizedError("rental_not_found")))
+			return
+		}
+
+		if errors.As(err, &localized) {
+			switch localized.Code {
+			case "user_not_found":
+				ginCtx.JSON(http.StatusNotFound, errorResponse(ginCtx, err))
+				return
+			case "rental_not_bookable", "rental_unavailable":
+				ginCtx.JSON(http.StatusConflict, errorResponse(ginCtx, err))
+				return
+			}
+		}
+
+		utils.GetLogger().WithFields(log.Fields{"error": err.Error(), "id": rentalId}).Error("Error on booking rental")
+		ginCtx.JSON(http.StatusInternalServerError, booking)
+		return
+	}
+
+	ginCtx.JSON(http.StatusCreated, booking)
+}
+
+func SingleBookingHandler(ginCtx *gin.Context) {
+	id, err := strconv.Atoi(ginCtx.Param("id"))
+	if err != nil {
+		ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, utils.NewLocalizedError("id_not_integer")))
+		return
+	}
+
+	booking, err := internal.GetBooking(id)
+	if err != nil {
+		if err.Error() == "sql: no rows in result set" {
+			ginCtx.JSON(http.StatusNoContent, booking)
+			return
+		}
+
+		utils.GetLogger().WithFields(log.Fields{"error": err.Error(), "id": id}).Error("Error on getting booking")
+		ginCtx.JSON(http.StatusInternalServerError, booking)
+		return
+	}
+
+	ginCtx.JSON(http.StatusOK, booking)
+}
+
+func CancelBookingHandler(ginCtx *gin.Context) {
+	var localized utils.LocalizedError
+
+	userId, err := strconv.Atoi(ginCtx.Param("id"))
+	if err != nil {
+		ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, utils.NewLocalizedError("id_not_integer")))
+		return
+	}
+
+	bookingId, err := strconv.Atoi(ginCtx.Param("booking_id"))
+	if err != nil {
+		ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, utils.NewLocalizedError("id_not_integer")))
+		return
+	}
+
+	booking, err := internal.CancelBooking(userId, bookingId)
+	if err != nil {
+		if err.Error() == "sql: no rows in result set" {
+			ginCtx.JSON(http.StatusNotFound, errorResponse(ginCtx, utils.NewLocalizedError("booking_not_found")))
+			return
+		}
+
+		if errors.As(err, &localized) && localized.Code == "booking_not_cancellable" {
+			ginCtx.JSON(http.StatusConflict, errorResponse(ginCtx, err))
+			return
+		}
+
+		utils.GetLogger().WithFields(log.Fields{"error": err.Error(), "id": bookingId}).Error("Error on cancelling booking")
+		ginCtx.JSON(http.StatusInternalServerError, booking)
+		return
+	}
+
+	ginCtx.JSON(http.StatusOK, booking)
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"io"
+	"net/http"
+	"outdoorsy-api/internal"
+	"outdoorsy-api/utils"
+	"strconv"
+	"strings"
+)
+
+const maxCalendarSize = 5 << 20
+
+func RentalCalendarExportHandler(ginCtx *gin.Context) {
+	idAsString, _ := ginCtx.Params.Get("id")
+
+	id, err := strconv.Atoi(idAsString)
+	if err != nil {
+		ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, utils.NewLocalizedError("id_not_integer")))
+		return
+	}
+
+	calendar, err := internal.ExportRentalCalendar(id)
+	if err != nil {
+		if err.Error() == "sql: no rows in result set" {
+			ginCtx.Status(http.StatusNoContent)
+			return
+		}
+		utils.GetLogger().WithFields(log.Fields{"error": err.Error(), "id": id}).Error("Error on exporting rental calendar")
+		ginCtx.Status(http.StatusInternalServerError)
+		return
+	}
+
+	ginCtx.Header("Content-Disposition", fmt.Sprintf("inline; filename=\"rental-%d.ics\"", id))
+	ginCtx.Data(http.StatusOK, "text/calendar; charset=utf-8", calendar)
+}
+
+// RentalCalendarImportHandler imports into the owner's rental the ICS feed sent either as a multipart "file" field
+// or as the raw request body.
+func RentalCalendarImportHandler(ginCtx *gin.Context) {
+	ownerId, rentalId, ok := ownerRentalIds(ginCtx)
+	if !ok {
+		return
+	}
+
+	var calendar io.Reader
+	ginCtx.Request.Body = http.MaxBytesReader(ginCtx.Writer, ginCtx.Request.Body, maxCalendarSize)
+	if strings.HasPrefix(ginCtx.ContentType(), "multipart/") {
+		file, err := ginCtx.FormFile("file")
+		if err != nil {
+			if calendarTooLarge(ginCtx, err) {
+				return
+			}
+			ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, utils.NewLocalizedError("calendar_file_missing")))
+			return
+		}
+		opened, err := file.Open()
+		if err != nil {
+			ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, err))
+			return
+		}
+		defer opened.Close()
+		calendar = opened
+	} else {
+		body, err := io.ReadAll(ginCtx.Request.Body)
+		if err != nil {
+			if calendarTooLarge(ginCtx, err) {
+				return
+			}
+			ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, err))
+			return
+		}
+		calendar = bytes.NewReader(body)
+	}
+
+	result, failedValidation, err := internal.ImportRentalCalendar(ownerId, rentalId, calendar)
+	if err != nil {
+		if failedValidation {
+			ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, err))
+			return
+		}
+
+		if err.Error() == "sql: no rows in result set" {
+			ginCtx.JSON(http.StatusNotFound, errorResponse(ginCtx, utils.NewLocalizedError("rental_not_found")))
+			return
+		}
+
+		utils.GetLogger().WithFields(log.Fields{"error": err.Error(), "id": rentalId}).Error("Error on importing rental calendar")
+		ginCtx.JSON(http.StatusInternalServerError, result)
+		return
+	}
+
+	ginCtx.JSON(http.StatusOK, result)
+}
+
+// calendarTooLarge responds with 413 if reading the uploaded calendar failed because it is over maxCalendarSize.
+func calendarTooLarge(ginCtx *gin.Context, err error) bool {
+	var tooLarge *http.MaxBytesError
+	if !errors.As(err, &tooLarge) {
+		return false
+	}
+	ginCtx.JSON(http.StatusRequestEntityTooLarge, errorResponse(ginCtx, utils.NewLocalizedError("calendar_too_large", maxCalendarSize>>20)))
+	return true
+}
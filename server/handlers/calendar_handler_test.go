@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRentalCalendarImportHandlerShouldRejectCalendarsOverTheLimit(test *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/users/:id/rentals/:rental_id/calendar", RentalCalendarImportHandler)
+
+	calendar := "BEGIN:VCALENDAR\r\n" + strings.Repeat("X-FILLER:"+strings.Repeat("x", 100)+"\r\n", maxCalendarSize/100) + "END:VCALENDAR\r\n"
+
+	var multipartBody bytes.Buffer
+	writer := multipart.NewWriter(&multipartBody)
+	part, _ := writer.CreateFormFile("file", "feed.ics")
+	_, _ = part.Write([]byte(calendar))
+	_ = writer.Close()
+
+	uploads := []struct {
+		contentType string
+		body        []byte
+		message     string
+	}{
+		{"text/calendar", []byte(calendar), "Expected a raw body over the limit to be rejected"},
+		{writer.FormDataContentType(), multipartBody.Bytes(), "Expected a multipart file over the limit to be rejected"},
+	}
+	for _, upload := range uploads {
+		var response map[string]string
+
+		request := httptest.NewRequest(http.MethodPost, "/users/1/rentals/1/calendar", bytes.NewReader(upload.body))
+		request.Header.Set("Content-Type", upload.contentType)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(test, http.StatusRequestEntityTooLarge, recorder.Code, upload.message)
+		if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+			test.Fatalf("Error on decoding response - %s", err.Error())
+		}
+		assert.Equal(test, "calendar_too_large", response["code"], upload.message)
+		assert.Equal(test, "calendar can be at most 5 MB", response["error"], upload.message)
+	}
+}
@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"outdoorsy-api/utils"
+)
+
+// errorResponse builds the error body in the language negotiated from the Accept-Language header. Errors without
+// a code are returned as they are, under the generic "error" code.
+func errorResponse(ginCtx *gin.Context, err error) map[string]string {
+	var (
+		language  = utils.NegotiateLanguage(ginCtx.GetHeader("Accept-Language"))
+		localized utils.LocalizedError
+	)
+
+	ginCtx.Header("Content-Language", language)
+	if errors.As(err, &localized) {
+		return map[string]string{"error": localized.Localize(language), "code": localized.Code}
+	}
+	return map[string]string{"error": err.Error(), "code": "error"}
+}
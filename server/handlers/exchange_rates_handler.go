@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+	"outdoorsy-api/internal"
+	"outdoorsy-api/utils"
+)
+
+func ExchangeRatesHandler(ginCtx *gin.Context) {
+	ginCtx.JSON(http.StatusOK, internal.GetExchangeRates())
+}
+
+// RefreshExchangeRatesHandler reloads the exchange-rate table from its file. The previous table is kept if the
+// file can not be loaded.
+func RefreshExchangeRatesHandler(ginCtx *gin.Context) {
+	rates, err := internal.RefreshExchangeRates()
+	if err != nil {
+		utils.GetLogger().WithFields(log.Fields{"error": err.Error()}).Error("Error on refreshing exchange rates")
+		ginCtx.JSON(http.StatusInternalServerError, errorResponse(ginCtx, err))
+		return
+	}
+
+	ginCtx.JSON(http.StatusOK, rates)
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+	"outdoorsy-api/internal"
+	"outdoorsy-api/utils"
+	"strconv"
+)
+
+func CreateBlackoutHandler(ginCtx *gin.Context) {
+	var (
+		input     internal.BlackoutInput
+		localized utils.LocalizedError
+	)
+
+	ownerId, rentalId, ok := ownerRentalIds(ginCtx)
+	if !ok {
+		return
+	}
+
+	if err := ginCtx.ShouldBindJSON(&input); err != nil {
+		ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, utils.NewLocalizedError("request_body_invalid")))
+		return
+	}
+
+	blackout, failedValidation, err := internal.CreateRentalBlackout(ownerId, rentalId, input)
+	if err != nil {
+		if failedValidation {
+			ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, err))
+			return
+		}
+
+		if err.Error() == "sql: no rows in result set" {
+			ginCtx.JSON(http.StatusNotFound, errorResponse(ginCtx, utils.NewLocalizedError("rental_not_found")))
+			return
+		}
+
+		if errors.As(err, &localized) {
+			ginCtx.JSON(http.StatusConflict, errorResponse(ginCtx, err))
+			return
+		}
+
+		utils.GetLogger().WithFields(log.Fields{"error": err.Error(), "id": rentalId}).Error("Error on creating rental blackout")
+		ginCtx.JSON(http.StatusInternalServerError, blackout)
+		return
+	}
+
+	ginCtx.JSON(http.StatusCreated, blackout)
+}
+
+func DeleteBlackoutHandler(ginCtx *gin.Context) {
+	ownerId, rentalId, ok := ownerRentalIds(ginCtx)
+	if !ok {
+		return
+	}
+
+	blackoutId, err := strconv.Atoi(ginCtx.Param("blackout_id"))
+	if err != nil {
+		ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, utils.NewLocalizedError("id_not_integer")))
+		return
+	}
+
+	err = internal.DeleteRentalBlackout(ownerId, rentalId, blackoutId)
+	if err != nil {
+		if err.Error() == "sql: no rows in result set" {
+			ginCtx.JSON(http.StatusNotFound, errorResponse(ginCtx, utils.NewLocalizedError("blackout_not_found")))
+			return
+		}
+
+		utils.GetLogger().WithFields(log.Fields{"error": err.Error(), "id": blackoutId}).Error("Error on deleting rental blackout")
+		ginCtx.Status(http.StatusInternalServerError)
+		return
+	}
+
+	ginCtx.Status(http.StatusNoContent)
+}
+
+func BulkRentalsHandler(ginCtx *gin.Context) {
+	var request internal.BulkRequest
+
+	ownerId, err := strconv.Atoi(ginCtx.Param("id"))
+	if err != nil {
+		ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, utils.NewLocalizedError("id_not_integer")))
+		return
+	}
+
+	if err = ginCtx.ShouldBindJSON(&request); err != nil {
+		ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, utils.NewLocalizedError("request_body_invalid")))
+		return
+	}
+
+	report, failedValidation, err := internal.ApplyBulkOperations(ownerId, request)
+	if err != nil {
+		if failedValidation {
+			ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, err))
+			return
+		}
+
+		utils.GetLogger().WithFields(log.Fields{"error": err.Error(), "id": ownerId}).Error("Error on applying bulk rental operations")
+		ginCtx.JSON(http.StatusInternalServerError, report)
+		return
+	}
+
+	for index := range report.Rentals {
+		if failure := report.Rentals[index].Failure(); failure != nil {
+			report.Rentals[index].Error = errorResponse(ginCtx, failure)
+		}
+	}
+
+	if !report.Applied {
+		ginCtx.JSON(http.StatusConflict, report)
+		return
+	}
+	ginCtx.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"outdoorsy-api/server/gql"
+	"outdoorsy-api/server/middlewares"
+	"outdoorsy-api/utils"
+)
+
+// GraphQLHandler executes a GraphQL query. Like other GraphQL servers, it answers 200 with the errors in the
+// result once the request body is valid.
+func GraphQLHandler(ginCtx *gin.Context) {
+	var request gql.Request
+	if err := ginCtx.ShouldBindJSON(&request); err != nil || request.Query == "" {
+		ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, utils.NewLocalizedError("request_body_invalid")))
+		return
+	}
+
+	language := utils.NegotiateLanguage(ginCtx.GetHeader("Accept-Language"))
+	ginCtx.Header("Content-Language", language)
+	ginCtx.JSON(http.StatusOK, gql.Execute(ginCtx.Request.Context(), request, language, withDefaultUnits(ginCtx).Get("units"), ginCtx.GetBool(middlewares.AdminKey)))
+}
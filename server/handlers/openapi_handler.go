@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"outdoorsy-api/server/openapi"
+)
+
+// OpenAPIHandler returns the OpenAPI document of the REST API.
+func OpenAPIHandler(ginCtx *gin.Context) {
+	ginCtx.Data(http.StatusOK, "application/json; charset=utf-8", openapi.JSON())
+}
+
+// DocsHandler returns the Swagger UI page of the OpenAPI document.
+func DocsHandler(ginCtx *gin.Context) {
+	ginCtx.Data(http.StatusOK, "text/html; charset=utf-8", openapi.DocsPage())
+}
+
+// DocsAssetHandler returns the scripts, styles and icons of the Swagger UI page.
+func DocsAssetHandler(ginCtx *gin.Context) {
+	content, contentType, found := openapi.DocsAsset(ginCtx.Param("file"))
+	if !found {
+		ginCtx.Status(http.StatusNotFound)
+		return
+	}
+	ginCtx.Header("Cache-Control", "public, max-age=86400")
+	ginCtx.Data(http.StatusOK, contentType, content)
+}
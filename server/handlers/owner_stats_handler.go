@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+	"outdoorsy-api/internal"
+	"outdoorsy-api/utils"
+	"strconv"
+)
+
+func OwnerStatsHandler(ginCtx *gin.Context) {
+	id, err := strconv.Atoi(ginCtx.Param("id"))
+	if err != nil {
+		ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, utils.NewLocalizedError("id_not_integer")))
+		return
+	}
+
+	stats, failedValidation, err := internal.GetOwnerStats(id, ginCtx.Request.URL.Query())
+	if err != nil {
+		if failedValidation {
+			ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, err))
+			return
+		}
+
+		if err.Error() == "sql: no rows in result set" {
+			ginCtx.JSON(http.StatusNotFound, errorResponse(ginCtx, utils.NewLocalizedError("user_not_found")))
+			return
+		}
+
+		utils.GetLogger().WithFields(log.Fields{"error": err.Error(), "id": id}).Error("Error on building owner stats")
+		ginCtx.JSON(http.StatusInternalServerError, stats)
+		return
+	}
+
+	ginCtx.JSON(http.StatusOK, stats)
+}
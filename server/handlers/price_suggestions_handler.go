@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+	"outdoorsy-api/internal"
+	"outdoorsy-api/utils"
+	"strconv"
+)
+
+func PriceSuggestionHandler(ginCtx *gin.Context) {
+	id, err := strconv.Atoi(ginCtx.Param("id"))
+	if err != nil {
+		ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, utils.NewLocalizedError("id_not_integer")))
+		return
+	}
+
+	suggestion, failedValidation, err := internal.GetPriceSuggestion(id, withDefaultUnits(ginCtx))
+	if err != nil {
+		if failedValidation {
+			ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, err))
+			return
+		}
+
+		if err.Error() == "sql: no rows in result set" {
+			ginCtx.JSON(http.StatusNoContent, suggestion)
+			return
+		}
+
+		utils.GetLogger().WithFields(log.Fields{"error": err.Error(), "id": id}).Error("Error on suggesting rental price")
+		ginCtx.JSON(http.StatusInternalServerError, suggestion)
+		return
+	}
+
+	ginCtx.JSON(http.StatusOK, suggestion)
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+	"outdoorsy-api/internal"
+	"outdoorsy-api/utils"
+)
+
+func CreatePromoCodeHandler(ginCtx *gin.Context) {
+	var (
+		input     internal.PromoCode
+		localized utils.LocalizedError
+	)
+
+	if err := ginCtx.ShouldBindJSON(&input); err != nil {
+		ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, utils.NewLocalizedError("request_body_invalid")))
+		return
+	}
+
+	promoCode, failedValidation, err := internal.CreatePromoCode(input)
+	if err != nil {
+		if failedValidation {
+			ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, err))
+			return
+		}
+
+		if errors.As(err, &localized) && localized.Code == "promo_code_exists" {
+			ginCtx.JSON(http.StatusConflict, errorResponse(ginCtx, err))
+			return
+		}
+
+		utils.GetLogger().WithFields(log.Fields{"error": err.Error(), "code": input.Code}).Error("Error on creating promo code")
+		ginCtx.JSON(http.StatusInternalServerError, promoCode)
+		return
+	}
+
+	ginCtx.JSON(http.StatusCreated, promoCode)
+}
+
+func PromoCodesHandler(ginCtx *gin.Context) {
+	promoCodes, err := internal.GetPromoCodes()
+	if err != nil {
+		utils.GetLogger().WithFields(log.Fields{"error": err.Error()}).Error("Error on getting promo codes")
+		ginCtx.JSON(http.StatusInternalServerError, promoCodes)
+		return
+	}
+
+	ginCtx.JSON(http.StatusOK, promoCodes)
+}
+
+func SinglePromoCodeHandler(ginCtx *gin.Context) {
+	code := ginCtx.Param("code")
+
+	promoCode, err := internal.GetPromoCode(code)
+	if err != nil {
+		if err.Error() == "sql: no rows in result set" {
+			ginCtx.JSON(http.StatusNotFound, errorResponse(ginCtx, utils.NewLocalizedError("promo_code_unknown", code)))
+			return
+		}
+
+		utils.GetLogger().WithFields(log.Fields{"error": err.Error(), "code": code}).Error("Error on getting promo code")
+		ginCtx.JSON(http.StatusInternalServerError, promoCode)
+		return
+	}
+
+	ginCtx.JSON(http.StatusOK, promoCode)
+}
+
+func DeactivatePromoCodeHandler(ginCtx *gin.Context) {
+	code := ginCtx.Param("code")
+
+	promoCode, err := internal.DeactivatePromoCode(code)
+	if err != nil {
+		if err.Error() == "sql: no rows in result set" {
+			ginCtx.JSON(http.StatusNotFound, errorResponse(ginCtx, utils.NewLocalizedError("promo_code_unknown", code)))
+			return
+		}
+
+		utils.GetLogger().WithFields(log.Fields{"error": err.Error(), "code": code}).Error("Error on deactivating promo code")
+		ginCtx.JSON(http.StatusInternalServerError, promoCode)
+		return
+	}
+
+	ginCtx.JSON(http.StatusOK, promoCode)
+}
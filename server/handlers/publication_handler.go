@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+	"outdoorsy-api/internal"
+	v1 "outdoorsy-api/server/v1"
+	"outdoorsy-api/utils"
+	"strconv"
+)
+
+func CreateRentalHandler(ginCtx *gin.Context) {
+	ownerId, err := strconv.Atoi(ginCtx.Param("id"))
+	if err != nil {
+		ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, utils.NewLocalizedError("id_not_integer")))
+		return
+	}
+
+	var input internal.RentalInput
+	if err = ginCtx.ShouldBindJSON(&input); err != nil {
+		ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, utils.NewLocalizedError("request_body_invalid")))
+		return
+	}
+
+	rental, failedValidation, err := internal.CreateDraftRental(ownerId, input)
+	if err != nil {
+		if failedValidation {
+			ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, err))
+			return
+		}
+
+		if err.Error() == "sql: no rows in result set" {
+			ginCtx.JSON(http.StatusNotFound, errorResponse(ginCtx, utils.NewLocalizedError("user_not_found")))
+			return
+		}
+
+		utils.GetLogger().WithFields(log.Fields{"error": err.Error(), "user_id": ownerId}).Error("Error on creating rental")
+		ginCtx.JSON(http.StatusInternalServerError, rental)
+		return
+	}
+
+	ginCtx.JSON(http.StatusCreated, v1.NewRental(rental))
+}
+
+// OwnerRentalTransitionHandler applies the owner moderation action from the path - submit, unlist or relist.
+func OwnerRentalTransitionHandler(ginCtx *gin.Context) {
+	ownerId, err := strconv.Atoi(ginCtx.Param("id"))
+	if err != nil {
+		ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, utils.NewLocalizedError("id_not_integer")))
+		return
+	}
+
+	rentalId, err := strconv.Atoi(ginCtx.Param("rental_id"))
+	if err != nil {
+		ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, utils.NewLocalizedError("id_not_integer")))
+		return
+	}
+
+	handleRentalTransition(ginCtx, rentalId, ginCtx.Param("action"), ownerId, "")
+}
+
+// AdminRentalTransitionHandler applies the admin moderation action from the path - approve or reject. Rejections
+// require a reason in the JSON body.
+func AdminRentalTransitionHandler(ginCtx *gin.Context) {
+	var decision struct {
+		Reason string `json:"reason"`
+	}
+
+	rentalId, err := strconv.Atoi(ginCtx.Param("id"))
+	if err != nil {
+		ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, utils.NewLocalizedError("id_not_integer")))
+		return
+	}
+
+	if ginCtx.Request.ContentLength != 0 {
+		if err = ginCtx.ShouldBindJSON(&decision); err != nil {
+			ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, utils.NewLocalizedError("request_body_invalid")))
+			return
+		}
+	}
+
+	handleRentalTransition(ginCtx, rentalId, ginCtx.Param("action"), 0, decision.Reason)
+}
+
+func handleRentalTransition(ginCtx *gin.Context, rentalId int, action string, ownerId int, reason string) {
+	var localized utils.LocalizedError
+
+	rental, failedValidation, err := internal.TransitionRentalStatus(rentalId, action, ownerId, reason)
+	if err != nil {
+		if failedValidation {
+			ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, err))
+			return
+		}
+
+		if err.Error() == "sql: no rows in result set" {
+			ginCtx.JSON(http.StatusNotFound, errorResponse(ginCtx, utils.NewLocalizedError("rental_not_found")))
+			return
+		}
+
+		if errors.As(err, &localized) && localized.Code == "rental_transition_invalid" {
+			ginCtx.JSON(http.StatusConflict, errorResponse(ginCtx, err))
+			return
+		}
+
+		utils.GetLogger().WithFields(log.Fields{"error": err.Error(), "id": rentalId, "action": action}).Error("Error on rental status transition")
+		ginCtx.JSON(http.StatusInternalServerError, rental)
+		return
+	}
+
+	ginCtx.JSON(http.StatusOK, v1.NewRental(rental))
+}
+
+func ModerationQueueHandler(ginCtx *gin.Context) {
+	rentals, err := internal.GetModerationQueue()
+	if err != nil {
+		utils.GetLogger().WithFields(log.Fields{"error": err.Error()}).Error("Error on getting the moderation queue")
+		ginCtx.JSON(http.StatusInternalServerError, rentals)
+		return
+	}
+
+	ginCtx.JSON(http.StatusOK, v1.NewRentals(rentals))
+}
+
+func RentalStatusTransitionsHandler(ginCtx *gin.Context) {
+	rentalId, err := strconv.Atoi(ginCtx.Param("id"))
+	if err != nil {
+		ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, utils.NewLocalizedError("id_not_integer")))
+		return
+	}
+
+	transitions, err := internal.GetRentalStatusTransitions(rentalId)
+	if err != nil {
+		utils.GetLogger().WithFields(log.Fields{"error": err.Error(), "id": rentalId}).Error("Error on getting rental status transitions")
+		ginCtx.JSON(http.StatusInternalServerError, transitions)
+		return
+	}
+
+	ginCtx.JSON(http.StatusOK, transitions)
+}
+
+// RentalDeliveryHandler replaces the delivery settings of the owner's rental.
+func RentalDeliveryHandler(ginCtx *gin.Context) {
+	var settings internal.Delivery
+
+	ownerId, err := strconv.Atoi(ginCtx.Param("id"))
+	if err != nil {
+		ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, utils.NewLocalizedError("id_not_integer")))
+		return
+	}
+
+	rentalId, err := strconv.Atoi(ginCtx.Param("rental_id"))
+	if err != nil {
+		ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, utils.NewLocalizedError("id_not_integer")))
+		return
+	}
+
+	if err = ginCtx.ShouldBindJSON(&settings); err != nil {
+		ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, utils.NewLocalizedError("request_body_invalid")))
+		return
+	}
+
+	rental, failedValidation, err := internal.UpdateRentalDelivery(ownerId, rentalId, settings)
+	if err != nil {
+		if failedValidation {
+			ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, err))
+			return
+		}
+
+		if err.Error() == "sql: no rows in result set" {
+			ginCtx.JSON(http.StatusNotFound, errorResponse(ginCtx, utils.NewLocalizedError("rental_not_found")))
+			return
+		}
+
+		utils.GetLogger().WithFields(log.Fields{"error": err.Error(), "id": rentalId}).Error("Error on updating rental delivery")
+		ginCtx.JSON(http.StatusInternalServerError, rental)
+		return
+	}
+
+	ginCtx.JSON(http.StatusOK, v1.NewRental(rental))
+}
+
+func RentalBookingRulesHandler(ginCtx *gin.Context) {
+	var rules internal.BookingRules
+
+	ownerId, rentalId, ok := ownerRentalIds(ginCtx)
+	if !ok {
+		return
+	}
+
+	if err := ginCtx.ShouldBindJSON(&rules); err != nil {
+		ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, utils.NewLocalizedError("request_body_invalid")))
+		return
+	}
+
+	rental, failedValidation, err := internal.UpdateRentalBookingRules(ownerId, rentalId, rules)
+	if err != nil {
+		if failedValidation {
+			ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, err))
+			return
+		}
+
+		if err.Error() == "sql: no rows in result set" {
+			ginCtx.JSON(http.StatusNotFound, errorResponse(ginCtx, utils.NewLocalizedError("rental_not_found")))
+			return
+		}
+
+		utils.GetLogger().WithFields(log.Fields{"error": err.Error(), "id": rentalId}).Error("Error on updating rental booking rules")
+		ginCtx.JSON(http.StatusInternalServerError, rental)
+		return
+	}
+
+	ginCtx.JSON(http.StatusOK, v1.NewRental(rental))
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+	"outdoorsy-api/internal"
+	"outdoorsy-api/utils"
+	"strconv"
+)
+
+func QuoteHandler(ginCtx *gin.Context) {
+	idAsString, _ := ginCtx.Params.Get("id")
+
+	id, err := strconv.Atoi(idAsString)
+	if err != nil {
+		ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, utils.NewLocalizedError("id_not_integer")))
+		return
+	}
+
+	quote, failedValidation, err := internal.GetQuote(id, withDefaultUnits(ginCtx))
+	if err != nil {
+		if failedValidation {
+			ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, err))
+			return
+		}
+
+		if err.Error() == "sql: no rows in result set" {
+			ginCtx.JSON(http.StatusNoContent, quote)
+			return
+		}
+
+		utils.GetLogger().WithFields(log.Fields{"error": err.Error(), "id": id}).Error("Error on quoting rental")
+		ginCtx.JSON(http.StatusInternalServerError, quote)
+		return
+	}
+
+	if rejection := quote.PromoCodeError(); rejection != nil {
+		quote.PromoCodeRejection = errorResponse(ginCtx, rejection)
+	}
+	ginCtx.JSON(http.StatusOK, quote)
+}
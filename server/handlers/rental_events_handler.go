@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+	"net/url"
+	"outdoorsy-api/internal"
+	v1 "outdoorsy-api/server/v1"
+	"outdoorsy-api/utils"
+	"strconv"
+	"time"
+)
+
+// rentalEventsHeartbeat is how often a comment is sent to keep the stream alive.
+var rentalEventsHeartbeat = 15 * time.Second
+
+const (
+	// rentalEventsRetry is how long clients wait before reconnecting, in milliseconds.
+	rentalEventsRetry = 3000
+	// rentalEventReset tells the client that events were missed, so it should reload the rentals.
+	rentalEventReset = "reset"
+)
+
+// RentalEventsHandler streams the created, updated and deleted rentals as server-sent events, filtered by the
+// parameters of MultipleRentalsHandler. Clients reconnecting with the Last-Event-ID header get the events they
+// missed first, or a reset event if the event log doesn't go back that far anymore.
+func RentalEventsHandler(ginCtx *gin.Context) {
+	params := withDefaultUnits(ginCtx)
+	if !authorizeStatuses(ginCtx, params) {
+		return
+	}
+
+	failedValidation, err := internal.ValidateRentalEventsParameters(params)
+	if err != nil {
+		if failedValidation {
+			ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, err))
+			return
+		}
+
+		utils.GetLogger().WithFields(log.Fields{"error": err.Error()}).Error("Error on validating rental event filters")
+		ginCtx.Status(http.StatusInternalServerError)
+		return
+	}
+
+	if !internal.RentalEventsAvailable() {
+		ginCtx.JSON(http.StatusServiceUnavailable, errorResponse(ginCtx, utils.NewLocalizedError("rental_events_unavailable")))
+		return
+	}
+
+	events, unsubscribe := internal.SubscribeRentalEvents()
+	defer unsubscribe()
+
+	ginCtx.Header("Content-Type", "text/event-stream")
+	ginCtx.Header("Cache-Control", "no-cache")
+	ginCtx.Header("Connection", "keep-alive")
+	ginCtx.Header("X-Accel-Buffering", "no")
+	ginCtx.Status(http.StatusOK)
+	_, _ = ginCtx.Writer.WriteString("retry: " + strconv.Itoa(rentalEventsRetry) + "\n\n")
+
+	// Events committed while the missed ones are read are both replayed and published, they are sent once.
+	replayed := make(map[int64]bool)
+	if lastEventId, err := strconv.ParseInt(ginCtx.GetHeader("Last-Event-ID"), 10, 64); err == nil {
+		missed, complete, err := internal.GetRentalEventsAfter(lastEventId)
+		if err != nil {
+			utils.GetLogger().WithFields(log.Fields{"error": err.Error(), "id": lastEventId}).Error("Error on getting missed rental events")
+			return
+		}
+
+		if !complete {
+			_ = sse.Encode(ginCtx.Writer, sse.Event{Event: rentalEventReset, Data: map[string]int64{"last_event_id": lastEventId}})
+		}
+		for _, event := range missed {
+			replayed[event.Id] = true
+			if !writeRentalEvent(ginCtx, event, params) {
+				return
+			}
+		}
+	}
+	ginCtx.Writer.Flush()
+
+	heartbeat := time.NewTicker(rentalEventsHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-ginCtx.Request.Context().Done():
+			return
+		case <-heartbeat.C:
+			_, _ = ginCtx.Writer.WriteString(": heartbeat\n\n")
+			ginCtx.Writer.Flush()
+		case event, open := <-events:
+			if !open {
+				// The stream fell behind, the client resumes with the last event it got.
+				return
+			}
+			if !replayed[event.Id] && !writeRentalEvent(ginCtx, event, params) {
+				return
+			}
+		}
+	}
+}
+
+// writeRentalEvent sends the event if it concerns the filters, with the rental as it is now. Deleted rentals and
+// rentals removed from the filters are sent as their id.
+func writeRentalEvent(ginCtx *gin.Context, event internal.RentalEvent, params url.Values) bool {
+	rental, kind, err := internal.FilterRentalEvent(event, params)
+	if err != nil {
+		utils.GetLogger().WithFields(log.Fields{"error": err.Error(), "id": event.RentalId}).Error("Error on getting the rental of an event")
+		return false
+	}
+	if kind == "" {
+		return true
+	}
+
+	var data interface{} = map[string]int{"id": event.RentalId}
+	if kind == internal.RentalEventCreated || kind == internal.RentalEventUpdated {
+		data = v1.NewRental(rental)
+	}
+
+	err = sse.Encode(ginCtx.Writer, sse.Event{Id: strconv.FormatInt(event.Id, 10), Event: kind, Data: data})
+	if err != nil {
+		utils.GetLogger().WithFields(log.Fields{"error": err.Error(), "id": event.RentalId}).Error("Error on encoding a rental event")
+		return false
+	}
+	ginCtx.Writer.Flush()
+	return true
+}
@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	validator "github.com/asaskevich/govalidator"
+	"github.com/gin-gonic/gin"
+	"github.com/knadh/koanf/parsers/dotenv"
+	"github.com/knadh/koanf/providers/file"
+	"github.com/knadh/koanf/v2"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"outdoorsy-api/database"
+	"outdoorsy-api/internal"
+	"outdoorsy-api/server/middlewares"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type configurations struct {
+	DBHosts    string `json:"db_hosts" koanf:"DB_HOSTS" valid:"required"`
+	DBUsername string `json:"db_username" koanf:"DB_USERNAME" valid:"required"`
+	DBPassword string `json:"db_password" koanf:"DB_PASSWORD" valid:"required"`
+	DBPort     string `json:"db_port" koanf:"DB_PORT" valid:"required"`
+	DBName     string `json:"db_name" koanf:"DB_NAME" valid:"required"`
+}
+
+func setupTest(test *testing.T) func() {
+	var (
+		parser = koanf.New(".")
+		config configurations
+	)
+
+	err := parser.Load(file.Provider("../../config.env"), dotenv.Parser())
+	if err != nil {
+		test.Fatal(err.Error())
+	}
+
+	err = parser.Unmarshal("", &config)
+	if err != nil {
+		test.Fatal(err.Error())
+	}
+
+	_, err = validator.ValidateStruct(config)
+	if err != nil {
+		test.Fatal(err.Error())
+	}
+
+	database.Init(
+		config.DBHosts,
+		config.DBUsername,
+		config.DBPassword,
+		config.DBPort,
+		config.DBName,
+	)
+
+	return func() {
+	}
+}
+
+// streamMessage is an event or a comment of an event stream.
+type streamMessage struct {
+	id      string
+	event   string
+	data    string
+	comment string
+}
+
+// readEventStream sends the messages of the stream to the channel until the stream ends.
+func readEventStream(body io.Reader, messages chan<- streamMessage) {
+	defer close(messages)
+
+	var (
+		scanner = bufio.NewScanner(body)
+		message streamMessage
+	)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			if message != (streamMessage{}) {
+				messages <- message
+			}
+			message = streamMessage{}
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		switch field {
+		case "":
+			message.comment = strings.TrimSpace(value)
+		case "id":
+			message.id = value
+		case "event":
+			message.event = value
+		case "data":
+			message.data = value
+		}
+	}
+}
+
+// nextRentalEvent returns the next event of the stream, counting the heartbeats sent before it.
+func nextRentalEvent(test *testing.T, messages <-chan streamMessage, heartbeats *int) streamMessage {
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case message, open := <-messages:
+			if !open {
+				test.Fatal("Expected the stream to stay open")
+			}
+			if message.comment == "heartbeat" {
+				*heartbeats++
+				continue
+			}
+			return message
+		case <-timeout:
+			test.Fatal("Expected an event within 10 seconds")
+		}
+	}
+}
+
+func TestRentalEventsHandlerShouldReplayMissedEventsOnceAndSendHeartbeats(test *testing.T) {
+	defer setupTest(test)()
+
+	internal.StartRentalEventListener()
+	for deadline := time.Now().Add(10 * time.Second); !internal.RentalEventsAvailable(); time.Sleep(50 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			test.Fatal("Expected the rental events to be listened to")
+		}
+	}
+
+	heartbeat := rentalEventsHeartbeat
+	rentalEventsHeartbeat = 100 * time.Millisecond
+	defer func() {
+		rentalEventsHeartbeat = heartbeat
+	}()
+
+	input := internal.RentalInput{
+		Name:     "Event stream van",
+		Type:     "camper-van",
+		Sleeps:   2,
+		Price:    internal.Price{Day: 9900},
+		Location: internal.Location{City: "Denver", State: "CO", Country: "US", Lat: 39.67, Lng: -104.92},
+	}
+	rental, _, err := internal.CreateDraftRental(1, input)
+	if err != nil {
+		test.Fatalf("Error on creating draft rental - %s", err.Error())
+	}
+	test.Cleanup(func() {
+		_ = database.Exec("DELETE FROM rentals WHERE rentals.id = $1;", rental.IdRental)
+	})
+	if _, _, err = internal.TransitionRentalStatus(rental.IdRental, "submit", 1, ""); err != nil {
+		test.Fatalf("Error on submitting rental - %s", err.Error())
+	}
+
+	logged, _, err := internal.GetRentalEventsAfter(0)
+	if err != nil {
+		test.Fatalf("Error on getting rental events - %s", err.Error())
+	}
+	var created, submitted internal.RentalEvent
+	for _, event := range logged {
+		if event.RentalId == rental.IdRental && event.Kind == internal.RentalEventCreated {
+			created = event
+		} else if event.RentalId == rental.IdRental && event.Kind == internal.RentalEventUpdated {
+			submitted = event
+		}
+	}
+
+	engine := gin.New()
+	engine.GET("/rentals/events", func(ginCtx *gin.Context) {
+		ginCtx.Set(middlewares.AdminKey, true)
+	}, RentalEventsHandler)
+	// the server waits for the streams, which are closed by the cleanups registered after this one
+	server := httptest.NewServer(engine)
+	test.Cleanup(server.Close)
+
+	open := func(lastEventId int64) <-chan streamMessage {
+		request, _ := http.NewRequest(http.MethodGet, server.URL+"/rentals/events?status=draft,pending_review&ids="+strconv.Itoa(rental.IdRental), nil)
+		request.Header.Set("Last-Event-ID", strconv.FormatInt(lastEventId, 10))
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			test.Fatalf("Error on opening the event stream - %s", err.Error())
+		}
+		test.Cleanup(func() {
+			_ = response.Body.Close()
+		})
+		assert.Equal(test, http.StatusOK, response.StatusCode, "Expected the stream to open")
+
+		messages := make(chan streamMessage, 16)
+		go readEventStream(response.Body, messages)
+		return messages
+	}
+
+	var (
+		heartbeats int
+		messages   = open(created.Id)
+	)
+	replayed := nextRentalEvent(test, messages, &heartbeats)
+	assert.Equal(test, strconv.FormatInt(submitted.Id, 10), replayed.id, "Expected the event missed after Last-Event-ID")
+	assert.Equal(test, internal.RentalEventUpdated, replayed.event, "Expected the missed update")
+	assert.Contains(test, replayed.data, `"status":"pending_review"`, "Expected the rental as it is now")
+
+	// the replayed event is notified again, as if it was committed while the log was read
+	payload, _ := json.Marshal(submitted)
+	if err = database.Exec("SELECT pg_notify('rental_events', $1);", string(payload)); err != nil {
+		test.Fatalf("Error on notifying rental event - %s", err.Error())
+	}
+	if err = database.Exec("DELETE FROM rentals WHERE rentals.id = $1;", rental.IdRental); err != nil {
+		test.Fatalf("Error on deleting rental - %s", err.Error())
+	}
+
+	deleted := nextRentalEvent(test, messages, &heartbeats)
+	assert.Equal(test, internal.RentalEventDeleted, deleted.event, "Expected the replayed event not to be sent again before the deletion")
+	assert.JSONEq(test, `{"id": `+strconv.Itoa(rental.IdRental)+`}`, deleted.data, "Expected the id of the deleted rental")
+
+	for deadline := time.Now().Add(time.Second); heartbeats == 0 && time.Now().Before(deadline); {
+		select {
+		case message := <-messages:
+			if message.comment == "heartbeat" {
+				heartbeats++
+			}
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	assert.Positive(test, heartbeats, "Expected heartbeats between the events")
+
+	messages = open(-1)
+	reset := nextRentalEvent(test, messages, &heartbeats)
+	assert.Equal(test, rentalEventReset, reset.event, "Expected a reset when the log doesn't go back to Last-Event-ID")
+	assert.JSONEq(test, `{"last_event_id": -1}`, reset.data, "Expected the Last-Event-ID of the client")
+
+	removed := nextRentalEvent(test, messages, &heartbeats)
+	assert.Equal(test, internal.RentalEventRemoved, removed.event, "Expected the update of a rental which is gone to remove it")
+	assert.Equal(test, internal.RentalEventDeleted, nextRentalEvent(test, messages, &heartbeats).event, "Expected the deletion to be replayed")
+}
@@ -1,25 +1,66 @@
 package handlers
 
 import (
+	"encoding/csv"
+	"encoding/json"
 	"github.com/gin-gonic/gin"
 	log "github.com/sirupsen/logrus"
 	"net/http"
+	"net/url"
 	"outdoorsy-api/internal"
+	"outdoorsy-api/server/middlewares"
+	v1 "outdoorsy-api/server/v1"
 	"outdoorsy-api/utils"
 	"strconv"
 )
 
+const (
+	exportJSON    = "json"
+	exportCSV     = "csv"
+	exportNDJSON  = "ndjson"
+	exportGeoJSON = "geojson"
+
+	mimeCSV     = "text/csv"
+	mimeNDJSON  = "application/x-ndjson"
+	mimeGeoJSON = "application/geo+json"
+
+	exportFlushRows = 100
+)
+
+// exportFormats maps the media types of the Accept header to the formats of the format parameter.
+var exportFormats = map[string]string{
+	gin.MIMEJSON: exportJSON,
+	mimeCSV:      exportCSV,
+	mimeNDJSON:   exportNDJSON,
+	mimeGeoJSON:  exportGeoJSON,
+}
+
 func SingleRentalHandler(ginCtx *gin.Context) {
 	idAsString, _ := ginCtx.Params.Get("id")
 
 	id, err := strconv.Atoi(idAsString)
 	if err != nil {
-		ginCtx.JSON(http.StatusBadRequest, map[string]string{"error": "provided parameter should be of type int"})
+		ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, utils.NewLocalizedError("id_not_integer")))
+		return
+	}
+
+	params := withDefaultUnits(ginCtx)
+	format := params.Get("format")
+	if format == "" {
+		format = exportFormats[ginCtx.NegotiateFormat(gin.MIMEJSON, mimeGeoJSON)]
+	}
+	if format != "" && format != exportJSON && format != exportGeoJSON {
+		ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, utils.NewLocalizedError("format_invalid")))
 		return
 	}
 
-	rental, err := internal.GetASingleRental(id)
+	rental, failedValidation, err := internal.GetASingleRentalWithParameters(id, params, ginCtx.GetBool(middlewares.AdminKey))
 	if err != nil {
+		if failedValidation {
+			ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, err))
+			return
+		}
+
 		if err.Error() == "sql: no rows in result set" {
 			ginCtx.JSON(http.StatusNoContent, rental)
 			return
@@ -29,11 +70,37 @@ func SingleRentalHandler(ginCtx *gin.Context) {
 		return
 	}
 
-	ginCtx.JSON(http.StatusOK, rental)
+	internal.RecordRentalView(rental.IdRental)
+	if format == exportGeoJSON {
+		ginCtx.Header("Content-Type", mimeGeoJSON)
+		ginCtx.JSON(http.StatusOK, v1.NewRentalFeature(rental))
+		return
+	}
+	ginCtx.JSON(http.StatusOK, v1.NewRental(rental))
 }
 
 func MultipleRentalsHandler(ginCtx *gin.Context) {
-	rental, failedValidation, err := internal.GetMultipleRentals(ginCtx.Request.URL.Query())
+	params := withDefaultUnits(ginCtx)
+	if !authorizeStatuses(ginCtx, params) {
+		return
+	}
+	format := params.Get("format")
+	params.Del("format")
+
+	if format == "" {
+		format = exportFormats[ginCtx.NegotiateFormat(gin.MIMEJSON, mimeCSV, mimeNDJSON, mimeGeoJSON)]
+	}
+	switch format {
+	case "", exportJSON, exportGeoJSON:
+	case exportCSV, exportNDJSON:
+		streamRentals(ginCtx, params, format)
+		return
+	default:
+		ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, utils.NewLocalizedError("format_invalid")))
+		return
+	}
+
+	rental, failedValidation, err := internal.GetMultipleRentals(params)
 	if err != nil {
 		if err.Error() == "sql: no rows in result set" {
 			ginCtx.JSON(http.StatusNoContent, rental)
@@ -41,7 +108,7 @@ func MultipleRentalsHandler(ginCtx *gin.Context) {
 		}
 
 		if failedValidation {
-			ginCtx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+			ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, err))
 			return
 		}
 
@@ -50,5 +117,134 @@ func MultipleRentalsHandler(ginCtx *gin.Context) {
 		return
 	}
 
-	ginCtx.JSON(http.StatusOK, rental)
+	internal.RecordSearchImpressions(rental)
+	if format == exportGeoJSON {
+		ginCtx.Header("Content-Type", mimeGeoJSON)
+		ginCtx.JSON(http.StatusOK, v1.NewRentalFeatureCollection(rental))
+		return
+	}
+	ginCtx.JSON(http.StatusOK, v1.NewRentals(rental))
+}
+
+// streamRentals writes the rentals as CSV or NDJSON while they are read from the database, flushing every few rows.
+// The status and the headers are sent with the first row, so invalid parameters still get a JSON error response.
+func streamRentals(ginCtx *gin.Context, params url.Values, format string) {
+	var (
+		started    bool
+		written    int
+		csvWriter  = csv.NewWriter(ginCtx.Writer)
+		jsonWriter = json.NewEncoder(ginCtx.Writer)
+	)
+
+	start := func() error {
+		started = true
+		ginCtx.Status(http.StatusOK)
+		if format == exportNDJSON {
+			ginCtx.Header("Content-Type", mimeNDJSON+"; charset=utf-8")
+			return nil
+		}
+		ginCtx.Header("Content-Type", mimeCSV+"; charset=utf-8")
+		ginCtx.Header("Content-Disposition", "attachment; filename=\"rentals.csv\"")
+		return csvWriter.Write(internal.RentalRecordColumns())
+	}
+
+	failedValidation, err := internal.StreamMultipleRentals(ginCtx.Request.Context(), params, func(rental internal.Rental) (err error) {
+		if !started {
+			if err = start(); err != nil {
+				return
+			}
+		}
+
+		record := internal.NewRentalRecord(rental)
+		if format == exportNDJSON {
+			err = jsonWriter.Encode(record)
+		} else {
+			err = csvWriter.Write(record.Values())
+		}
+
+		if written++; written%exportFlushRows == 0 {
+			csvWriter.Flush()
+			ginCtx.Writer.Flush()
+		}
+		return
+	})
+	if err == nil && !started {
+		err = start()
+	}
+	csvWriter.Flush()
+
+	if err != nil {
+		if started {
+			// a client which went away stopped the export, there is nothing to report
+			if ginCtx.Request.Context().Err() == nil {
+				utils.GetLogger().WithFields(log.Fields{"error": err.Error(), "written": written}).Error("Error on streaming rentals")
+			}
+			return
+		}
+
+		if failedValidation {
+			ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, err))
+			return
+		}
+
+		utils.GetLogger().WithFields(log.Fields{"error": err.Error()}).Error("Error on streaming rentals from the database")
+		ginCtx.Status(http.StatusInternalServerError)
+	}
+}
+
+func TrendingRentalsHandler(ginCtx *gin.Context) {
+	trending, failedValidation, err := internal.GetTrendingRentals(withDefaultUnits(ginCtx))
+	if err != nil {
+		if failedValidation {
+			ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, err))
+			return
+		}
+
+		utils.GetLogger().WithFields(log.Fields{"error": err.Error()}).Error("Error on getting trending rentals from the database")
+		ginCtx.JSON(http.StatusInternalServerError, trending)
+		return
+	}
+
+	ginCtx.JSON(http.StatusOK, v1.NewTrendingRentals(trending))
+}
+
+// RentalChangesHandler returns a page of the rental change feed. The page is returned even without changes, so its
+// next token can be polled.
+func RentalChangesHandler(ginCtx *gin.Context) {
+	changes, failedValidation, err := internal.GetRentalChanges(ginCtx.Request.URL.Query())
+	if err != nil {
+		if failedValidation {
+			ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, err))
+			return
+		}
+
+		utils.GetLogger().WithFields(log.Fields{"error": err.Error(), "since": ginCtx.Query("since")}).Error("Error on getting rental changes from the database")
+		ginCtx.Status(http.StatusInternalServerError)
+		return
+	}
+
+	ginCtx.JSON(http.StatusOK, v1.NewRentalChanges(changes))
+}
+
+// withDefaultUnits returns the query parameters of the request, with the units derived from the Accept-Language
+// header if they were not requested explicitly.
+func withDefaultUnits(ginCtx *gin.Context) url.Values {
+	params := ginCtx.Request.URL.Query()
+	if !params.Has("units") && ginCtx.GetHeader("Accept-Language") != "" {
+		params.Set("units", utils.UnitsForLanguage(ginCtx.GetHeader("Accept-Language")))
+	}
+	return params
+}
+
+// authorizeStatuses rejects listing rentals which are not published without the admin token. It returns whether
+// the request may go on.
+func authorizeStatuses(ginCtx *gin.Context, params url.Values) bool {
+	if ginCtx.GetBool(middlewares.AdminKey) {
+		return true
+	}
+	if err := utils.ValidatePublicStatuses(params.Get("status")); err != nil {
+		ginCtx.JSON(http.StatusUnauthorized, errorResponse(ginCtx, err))
+		return false
+	}
+	return true
 }
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+	"outdoorsy-api/internal"
+	"outdoorsy-api/utils"
+	"strconv"
+)
+
+func CreateWebhookHandler(ginCtx *gin.Context) {
+	var input internal.WebhookInput
+
+	if err := ginCtx.ShouldBindJSON(&input); err != nil {
+		ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, utils.NewLocalizedError("request_body_invalid")))
+		return
+	}
+
+	webhook, failedValidation, err := internal.CreateWebhook(input)
+	if err != nil {
+		if failedValidation {
+			ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, err))
+			return
+		}
+
+		utils.GetLogger().WithFields(log.Fields{"error": err.Error(), "url": input.URL}).Error("Error on creating webhook")
+		ginCtx.JSON(http.StatusInternalServerError, webhook)
+		return
+	}
+
+	ginCtx.JSON(http.StatusCreated, webhook)
+}
+
+func WebhooksHandler(ginCtx *gin.Context) {
+	webhooks, err := internal.GetWebhooks()
+	if err != nil {
+		utils.GetLogger().WithFields(log.Fields{"error": err.Error()}).Error("Error on getting webhooks")
+		ginCtx.JSON(http.StatusInternalServerError, webhooks)
+		return
+	}
+
+	ginCtx.JSON(http.StatusOK, webhooks)
+}
+
+func SingleWebhookHandler(ginCtx *gin.Context) {
+	id, err := strconv.Atoi(ginCtx.Param("id"))
+	if err != nil {
+		ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, utils.NewLocalizedError("id_not_integer")))
+		return
+	}
+
+	webhook, err := internal.GetWebhook(id)
+	if err != nil {
+		if err.Error() == "sql: no rows in result set" {
+			ginCtx.JSON(http.StatusNotFound, errorResponse(ginCtx, utils.NewLocalizedError("webhook_not_found")))
+			return
+		}
+
+		utils.GetLogger().WithFields(log.Fields{"error": err.Error(), "id": id}).Error("Error on getting webhook")
+		ginCtx.JSON(http.StatusInternalServerError, webhook)
+		return
+	}
+
+	ginCtx.JSON(http.StatusOK, webhook)
+}
+
+func UpdateWebhookHandler(ginCtx *gin.Context) {
+	var input internal.WebhookInput
+
+	id, err := strconv.Atoi(ginCtx.Param("id"))
+	if err != nil {
+		ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, utils.NewLocalizedError("id_not_integer")))
+		return
+	}
+
+	if err = ginCtx.ShouldBindJSON(&input); err != nil {
+		ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, utils.NewLocalizedError("request_body_invalid")))
+		return
+	}
+
+	webhook, failedValidation, err := internal.UpdateWebhook(id, input)
+	if err != nil {
+		if failedValidation {
+			ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, err))
+			return
+		}
+
+		if err.Error() == "sql: no rows in result set" {
+			ginCtx.JSON(http.StatusNotFound, errorResponse(ginCtx, utils.NewLocalizedError("webhook_not_found")))
+			return
+		}
+
+		utils.GetLogger().WithFields(log.Fields{"error": err.Error(), "id": id}).Error("Error on updating webhook")
+		ginCtx.JSON(http.StatusInternalServerError, webhook)
+		return
+	}
+
+	ginCtx.JSON(http.StatusOK, webhook)
+}
+
+func DeleteWebhookHandler(ginCtx *gin.Context) {
+	id, err := strconv.Atoi(ginCtx.Param("id"))
+	if err != nil {
+		ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, utils.NewLocalizedError("id_not_integer")))
+		return
+	}
+
+	err = internal.DeleteWebhook(id)
+	if err != nil {
+		if err.Error() == "sql: no rows in result set" {
+			ginCtx.JSON(http.StatusNotFound, errorResponse(ginCtx, utils.NewLocalizedError("webhook_not_found")))
+			return
+		}
+
+		utils.GetLogger().WithFields(log.Fields{"error": err.Error(), "id": id}).Error("Error on deleting webhook")
+		ginCtx.Status(http.StatusInternalServerError)
+		return
+	}
+
+	ginCtx.Status(http.StatusNoContent)
+}
+
+func WebhookDeliveriesHandler(ginCtx *gin.Context) {
+	id, err := strconv.Atoi(ginCtx.Param("id"))
+	if err != nil {
+		ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, utils.NewLocalizedError("id_not_integer")))
+		return
+	}
+
+	deliveries, failedValidation, err := internal.GetWebhookDeliveries(id, ginCtx.Request.URL.Query())
+	if err != nil {
+		if failedValidation {
+			ginCtx.JSON(http.StatusBadRequest, errorResponse(ginCtx, err))
+			return
+		}
+
+		if err.Error() == "sql: no rows in result set" {
+			ginCtx.JSON(http.StatusNotFound, errorResponse(ginCtx, utils.NewLocalizedError("webhook_not_found")))
+			return
+		}
+
+		utils.GetLogger().WithFields(log.Fields{"error": err.Error(), "id": id}).Error("Error on getting webhook deliveries")
+		ginCtx.JSON(http.StatusInternalServerError, deliveries)
+		return
+	}
+
+	if len(deliveries) == 0 {
+		ginCtx.Status(http.StatusNoContent)
+		return
+	}
+	ginCtx.JSON(http.StatusOK, deliveries)
+}
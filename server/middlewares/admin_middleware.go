@@ -0,0 +1,44 @@
+package middlewares
+
+import (
+	"crypto/subtle"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"outdoorsy-api/utils"
+	"strings"
+)
+
+// AdminKey is set to true in the context of the requests authorized with the admin token.
+const AdminKey = "admin"
+
+// AdminAuthorization allows only requests with the admin token as a bearer token. If no token is configured
+// every request is rejected.
+func AdminAuthorization(adminToken string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !hasAdminToken(c, adminToken) {
+			language := utils.NegotiateLanguage(c.GetHeader("Accept-Language"))
+			err := utils.NewLocalizedError("admin_unauthorized")
+			c.Header("Content-Language", language)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, map[string]string{"error": err.Localize(language), "code": err.Code})
+			return
+		}
+		c.Set(AdminKey, true)
+		c.Next()
+	}
+}
+
+// AdminIdentification marks the requests with the admin token under AdminKey and lets every request through, for
+// the routes serving admins more than others.
+func AdminIdentification(adminToken string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if hasAdminToken(c, adminToken) {
+			c.Set(AdminKey, true)
+		}
+		c.Next()
+	}
+}
+
+func hasAdminToken(c *gin.Context, adminToken string) bool {
+	token, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	return adminToken != "" && found && subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) == 1
+}
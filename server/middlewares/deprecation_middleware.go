@@ -0,0 +1,47 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var deprecatedCalls = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "api_deprecated_calls_total",
+	Help: "Calls to deprecated versions of the REST API, by version and route.",
+}, []string{"version", "method", "route"})
+
+// Deprecation describes a deprecated version of the REST API: when it was deprecated, when it will be removed and
+// the path prefix of the version replacing it.
+type Deprecation struct {
+	Version    string
+	Deprecated time.Time
+	Sunset     time.Time
+	Successor  string
+}
+
+// Deprecated signals the deprecation on every response of the version with the Deprecation (RFC 9745), Sunset
+// (RFC 8594) and successor-version Link headers, and counts the calls, so the version can be removed once no
+// client calls it anymore.
+func Deprecated(deprecation Deprecation) gin.HandlerFunc {
+	deprecated := "@" + strconv.FormatInt(deprecation.Deprecated.Unix(), 10)
+	sunset := deprecation.Sunset.UTC().Format(http.TimeFormat)
+
+	return func(c *gin.Context) {
+		c.Header("Deprecation", deprecated)
+		c.Header("Sunset", sunset)
+		if deprecation.Successor != "" {
+			successor := deprecation.Successor + c.Request.URL.Path
+			if c.Request.URL.RawQuery != "" {
+				successor += "?" + c.Request.URL.RawQuery
+			}
+			c.Header("Link", "<"+successor+`>; rel="successor-version"`)
+		}
+
+		deprecatedCalls.WithLabelValues(deprecation.Version, c.Request.Method, c.FullPath()).Inc()
+		c.Next()
+	}
+}
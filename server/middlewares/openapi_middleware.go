@@ -0,0 +1,36 @@
+package middlewares
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"outdoorsy-api/server/openapi"
+	"outdoorsy-api/utils"
+)
+
+// RequestValidation rejects the requests which don't match the OpenAPI document of their route, with the error
+// their handler would have returned. Routes missing from the document are not validated.
+func RequestValidation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.FullPath() == "" {
+			c.Next()
+			return
+		}
+
+		pathParams := make(map[string]string, len(c.Params))
+		for _, param := range c.Params {
+			pathParams[param.Key] = param.Value
+		}
+
+		err := openapi.ValidateRequest(c.Request, c.FullPath(), pathParams)
+		if err != nil {
+			var localized utils.LocalizedError
+			errors.As(err, &localized)
+			language := utils.NegotiateLanguage(c.GetHeader("Accept-Language"))
+			c.Header("Content-Language", language)
+			c.AbortWithStatusJSON(http.StatusBadRequest, map[string]string{"error": localized.Localize(language), "code": localized.Code})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,37 @@
+package openapi
+
+import (
+	_ "embed"
+	swaggerFiles "github.com/swaggo/files"
+)
+
+//go:embed docs.html
+var docsPage []byte
+
+// docsAssets are the Swagger UI files the docs page loads, by their content type.
+var docsAssets = map[string]string{
+	"swagger-ui.css":                  "text/css; charset=utf-8",
+	"swagger-ui-bundle.js":            "application/javascript; charset=utf-8",
+	"swagger-ui-standalone-preset.js": "application/javascript; charset=utf-8",
+	"favicon-16x16.png":               "image/png",
+	"favicon-32x32.png":               "image/png",
+}
+
+// DocsPage returns the Swagger UI page of the document.
+func DocsPage() []byte {
+	return docsPage
+}
+
+// DocsAsset returns a Swagger UI file with its content type. Only the files of the docs page are served.
+func DocsAsset(name string) (content []byte, contentType string, found bool) {
+	contentType, found = docsAssets[name]
+	if !found {
+		return
+	}
+
+	content, err := swaggerFiles.ReadFile("/" + name)
+	if err != nil {
+		return nil, "", false
+	}
+	return
+}
@@ -0,0 +1,48 @@
+package openapi
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+//go:embed openapi.yaml
+var specification []byte
+
+var (
+	document     = mustLoad()
+	documentJSON = mustMarshal(document)
+)
+
+// Document returns the OpenAPI document of the REST API. It must not be modified.
+func Document() *openapi3.T {
+	return document
+}
+
+// JSON returns the OpenAPI document of the REST API as JSON.
+func JSON() []byte {
+	return documentJSON
+}
+
+// mustLoad loads the embedded document. It is part of the binary, so an invalid document is a programming error.
+func mustLoad() *openapi3.T {
+	loader := openapi3.NewLoader()
+	loaded, err := loader.LoadFromData(specification)
+	if err != nil {
+		panic("openapi: unable to load the specification: " + err.Error())
+	}
+
+	if err = loaded.Validate(context.Background()); err != nil {
+		panic("openapi: invalid specification: " + err.Error())
+	}
+	return loaded
+}
+
+func mustMarshal(loaded *openapi3.T) []byte {
+	content, err := json.Marshal(loaded)
+	if err != nil {
+		panic("openapi: unable to marshal the specification: " + err.Error())
+	}
+	return content
+}
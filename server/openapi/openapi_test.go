@@ -0,0 +1,119 @@
+package openapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"outdoorsy-api/internal"
+	"outdoorsy-api/utils"
+	"testing"
+)
+
+func TestDocumentShouldBeValid(test *testing.T) {
+	assert.NoError(test, Document().Validate(context.Background()), "Expected a valid document")
+	assert.Contains(test, string(JSON()), `"openapi":"3.0.3"`, "Expected the document as JSON")
+}
+
+// The document may accept what the handlers reject with rules across parameters, but whenever it rejects a
+// request, it must be with the error of the handler.
+func TestValidateRequestShouldNotDriftFromTheHandlers(test *testing.T) {
+	validators := map[string]func(url.Values) error{
+		"/rentals": func(params url.Values) error {
+			if err := utils.ValidateParameters(params); err != nil {
+				return err
+			}
+			return internal.ValidateSort(params.Get("sort"))
+		},
+		"/rentals/trending":             utils.ValidateTrendingParameters,
+		"/rentals/changes":              utils.ValidateRentalChangesParameters,
+		"/rentals/:id/quote":            utils.ValidateQuoteParameters,
+		"/rentals/:id/trip-estimate":    utils.ValidateTripEstimateParameters,
+		"/rentals/:id/price-suggestion": utils.ValidatePriceSuggestionParameters,
+		"/users/:id/stats":              utils.ValidateStatsParameters,
+		"/webhooks/:id/deliveries":      utils.ValidateWebhookDeliveriesParameters,
+	}
+	testCases := map[string][]string{
+		"/rentals": {
+			"", "price_min=10&price_max=100", "price_min=-1", "price_max=abc", "price_min=", "limit=0", "limit=1.5",
+			"limit=5", "offset=-2", "ids=1,2,3", "ids=1,a", "ids=0", "ids=3,-1", "near=33.6", "near=1,2,3",
+			"near=a,b", "near=33.6,-117.9", "near=33.6,-117.9&radius=0", "near=33.6,-117.9&radius=10", "radius=0",
+			"amenities=wifi,,tv", "amenities=Wifi", "amenities=wifi,hot_tub", "currency=EU", "currency=eur",
+			"currency=E1R", "units=metric", "units=parsecs", "length_min=0", "length_min=20", "length_min=x",
+			"delivery_to=33.6", "delivery_to=33.6,-117.9", "from=2024-13&to=2024-06-10",
+			"from=2024-06-01&to=2024-06-10", "status=draft,published", "status=archived", "sort=price",
+			"sort=newest", "sort=", "sort=price_per_day", "sort=rentals.id%3BDROP%20TABLE%20rentals", "created_after=2024-06-01", "created_after=2024-06-01T10:00:00Z", "updated_after=yesterday",
+		},
+		"/rentals/trending": {
+			"", "window=7d", "window=7", "window=weeks", "limit=0", "near=1", "radius=0", "currency=US", "units=metric",
+		},
+		"/rentals/changes": {
+			"", "since=", "since=token", "limit=500", "limit=0", "limit=many",
+		},
+		"/rentals/:id/quote": {
+			"", "from=2024-06-01", "from=2024-06-01&to=2024-06-10", "from=x&to=2024-06-10",
+			"from=2024-06-01&to=2024-06-10&user_id=0", "from=2024-06-01&to=2024-06-10&user_id=a",
+			"from=2024-06-01&to=2024-06-10&delivery_to=1", "from=2024-06-01&to=2024-06-10&addons=1:2,3",
+			"from=2024-06-01&to=2024-06-10&addons=1;2", "from=2024-06-01&to=2024-06-10&currency=dollars",
+			"from=2024-06-01&to=2024-06-10&units=metric", "from=2024-06-01&to=2024-06-10&units=parsecs",
+		},
+		"/rentals/:id/trip-estimate": {
+			"", "to=33.6,-117.9", "to=33.6,-117.9&days=3", "to=33.6&days=3", "to=33.6,-117.9&days=0",
+			"to=33.6,-117.9&days=three", "days=3",
+		},
+		"/rentals/:id/price-suggestion": {
+			"", "radius=50", "radius=-5", "radius=far", "currency=usd", "units=miles",
+		},
+		"/users/:id/stats": {
+			"", "from=2024-01-01&to=2024-02-01", "to=2024-02-01", "from=2024-01-01&to=2024-02-01&granularity=year",
+			"from=2024-01-01&to=2024-02-01&granularity=week", "from=2024/01/01&to=2024-02-01",
+		},
+		"/webhooks/:id/deliveries": {
+			"", "status=dead", "status=failed", "status=", "limit=10&offset=20", "limit=0", "offset=x",
+		},
+	}
+
+	for routePath, queries := range testCases {
+		for _, query := range queries {
+			params, _ := url.ParseQuery(query)
+			request := httptest.NewRequest(http.MethodGet, SpecificationPath(routePath)+"?"+query, nil)
+
+			err := ValidateRequest(request, routePath, map[string]string{"id": "1"})
+			if err == nil {
+				continue
+			}
+
+			var expected, actual utils.LocalizedError
+			if assert.Error(test, validators[routePath](params), "Expected %s?%s to be rejected by the handler", routePath, query) {
+				errors.As(validators[routePath](params), &expected)
+				errors.As(err, &actual)
+				assert.Equal(test, expected, actual, "Expected the error of the handler for %s?%s", routePath, query)
+			}
+		}
+	}
+}
+
+func TestValidateRequestShouldRejectInvalidRequests(test *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "/rentals/0.5", nil)
+	assert.Equal(test, utils.NewLocalizedError("id_not_integer"), ValidateRequest(request, "/rentals/:id", map[string]string{"id": "0.5"}), "Expected a path parameter of the wrong type")
+
+	request = httptest.NewRequest(http.MethodGet, "/rentals/trending?window=7", nil)
+	assert.Equal(test, "window should be a number of days from 1d to 90d", ValidateRequest(request, "/rentals/trending", nil).Error(), "Expected the arguments of the error")
+
+	request = httptest.NewRequest(http.MethodGet, "/rentals?sort=price_per_day%20DESC", nil)
+	assert.Equal(test, utils.NewLocalizedError("sort_invalid"), ValidateRequest(request, "/rentals", nil), "Expected an unknown sort")
+
+	request = httptest.NewRequest(http.MethodPost, "/rentals/1/bookings", bytes.NewBufferString(`{"user_id": "one"}`))
+	request.Header.Set("Content-Type", "application/json")
+	assert.Equal(test, utils.NewLocalizedError("request_body_invalid"), ValidateRequest(request, "/rentals/:id/bookings", map[string]string{"id": "1"}), "Expected an invalid body")
+
+	request = httptest.NewRequest(http.MethodPost, "/users/1/rentals/1/calendar", bytes.NewBufferString("BEGIN:VCALENDAR"))
+	request.Header.Set("Content-Type", "text/calendar")
+	assert.NoError(test, ValidateRequest(request, "/users/:id/rentals/:rental_id/calendar", map[string]string{"id": "1", "rental_id": "1"}), "Expected other bodies to be left to the handler")
+
+	request = httptest.NewRequest(http.MethodGet, "/unknown?limit=0", nil)
+	assert.NoError(test, ValidateRequest(request, "/unknown", nil), "Expected undocumented routes to pass")
+}
@@ -0,0 +1,123 @@
+package openapi
+
+import (
+	"errors"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"math"
+	"mime"
+	"net/http"
+	"outdoorsy-api/utils"
+	"regexp"
+	"strings"
+)
+
+var versionPrefix = regexp.MustCompile(`^/v[0-9]+/`)
+
+// ValidateRequest validates the request against the operation of the route, given as a gin path like
+// /rentals/:id. Requests of routes missing from the document pass. The error is localized with the
+// x-error-code extension of the innermost failing schema or else of the parameter, so it is the error the
+// handler would have returned.
+func ValidateRequest(request *http.Request, routePath string, pathParams map[string]string) error {
+	path := SpecificationPath(routePath)
+	pathItem := document.Paths.Find(path)
+	if pathItem == nil {
+		return nil
+	}
+	operation := pathItem.GetOperation(request.Method)
+	if operation == nil {
+		return nil
+	}
+
+	input := &openapi3filter.RequestValidationInput{
+		Request:    request,
+		PathParams: pathParams,
+		Route: &routers.Route{
+			Spec:      document,
+			Path:      path,
+			PathItem:  pathItem,
+			Method:    request.Method,
+			Operation: operation,
+		},
+		Options: &openapi3filter.Options{
+			AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+			ExcludeRequestBody: !isJSON(request),
+		},
+	}
+
+	if err := openapi3filter.ValidateRequest(request.Context(), input); err != nil {
+		return localizeError(err)
+	}
+	return nil
+}
+
+// SpecificationPath converts a gin path to the path of the document, /rentals/:id to /rentals/{id}. The versions
+// are described once, so /v1/rentals/:id is /rentals/{id} too.
+func SpecificationPath(routePath string) string {
+	routePath = versionPrefix.ReplaceAllString(routePath, "/")
+	segments := strings.Split(routePath, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// isJSON reports whether the body is JSON. Other bodies, like the calendar feeds, are read by their handlers.
+func isJSON(request *http.Request) bool {
+	mediaType, _, _ := mime.ParseMediaType(request.Header.Get("Content-Type"))
+	return mediaType == "application/json"
+}
+
+func localizeError(err error) error {
+	var requestErr *openapi3filter.RequestError
+	if !errors.As(err, &requestErr) || requestErr.Parameter == nil {
+		return utils.NewLocalizedError("request_body_invalid")
+	}
+
+	parameter := requestErr.Parameter
+	if errors.Is(err, openapi3filter.ErrInvalidRequired) || errors.Is(err, openapi3filter.ErrInvalidEmptyValue) {
+		if code, ok := parameter.Extensions["x-required-error-code"].(string); ok {
+			return utils.NewLocalizedError(code)
+		}
+		return utils.NewLocalizedError("field_required", parameter.Name)
+	}
+
+	localized, found := errorCode(parameter.Extensions)
+	for cause := requestErr.Err; ; {
+		var schemaErr *openapi3.SchemaError
+		if !errors.As(cause, &schemaErr) {
+			break
+		}
+		if code, ok := errorCode(schemaErr.Schema.Extensions); ok {
+			localized, found = code, true
+		}
+		cause = schemaErr.Origin
+	}
+
+	if !found {
+		return utils.NewLocalizedError("parameter_invalid", parameter.Name)
+	}
+	return localized
+}
+
+// errorCode builds the error of the x-error-code and x-error-args extensions.
+func errorCode(extensions map[string]interface{}) (utils.LocalizedError, bool) {
+	code, ok := extensions["x-error-code"].(string)
+	if !ok {
+		return utils.LocalizedError{}, false
+	}
+
+	var args []interface{}
+	values, _ := extensions["x-error-args"].([]interface{})
+	for _, value := range values {
+		// JSON numbers are decoded as float64, while the messages format whole numbers as integers.
+		if number, isNumber := value.(float64); isNumber && number == math.Trunc(number) {
+			value = int(number)
+		}
+		args = append(args, value)
+	}
+	return utils.NewLocalizedError(code, args...), true
+}
@@ -0,0 +1,246 @@
+package rpc
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"net/url"
+	"outdoorsy-api/internal"
+	"outdoorsy-api/server/rpc/rentalspb"
+	"outdoorsy-api/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rentalsService serves the rentals over gRPC with the same internal functions as the REST handlers, so both APIs
+// filter, convert and validate alike.
+type rentalsService struct {
+	rentalspb.UnimplementedRentalsServiceServer
+	adminToken string
+}
+
+func (service rentalsService) GetRental(ctx context.Context, request *rentalspb.GetRentalRequest) (*rentalspb.Rental, error) {
+	params := withDefaultUnits(ctx, url.Values{})
+	setParam(params, "currency", request.GetCurrency())
+	setParam(params, "units", request.GetUnits())
+
+	rental, failedValidation, err := internal.GetASingleRentalWithParameters(int(request.GetId()), params, service.isAdmin(ctx))
+	if err != nil {
+		if err.Error() == "sql: no rows in result set" {
+			return nil, statusError(ctx, codes.NotFound, utils.NewLocalizedError("rental_not_found"))
+		}
+
+		if failedValidation {
+			return nil, statusError(ctx, codes.InvalidArgument, err)
+		}
+
+		utils.GetLogger().WithFields(log.Fields{"error": err.Error(), "id": request.GetId()}).Error("Error on getting single rental from the database")
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	internal.RecordRentalView(rental.IdRental)
+	return newRental(rental), nil
+}
+
+func (service rentalsService) ListRentals(ctx context.Context, request *rentalspb.ListRentalsRequest) (*rentalspb.ListRentalsResponse, error) {
+	if err := service.authorizeStatuses(ctx, request); err != nil {
+		return nil, err
+	}
+
+	rentals, failedValidation, err := internal.GetMultipleRentals(listRentalsParams(ctx, request))
+	if err != nil {
+		if err.Error() == "sql: no rows in result set" {
+			return &rentalspb.ListRentalsResponse{}, nil
+		}
+
+		if failedValidation {
+			return nil, statusError(ctx, codes.InvalidArgument, err)
+		}
+
+		utils.GetLogger().WithFields(log.Fields{"error": err.Error()}).Error("Error on getting all rentals from the database")
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	internal.RecordSearchImpressions(rentals)
+	response := &rentalspb.ListRentalsResponse{Rentals: make([]*rentalspb.Rental, 0, len(rentals))}
+	for _, rental := range rentals {
+		response.Rentals = append(response.Rentals, newRental(rental))
+	}
+	return response, nil
+}
+
+func (service rentalsService) StreamRentals(request *rentalspb.ListRentalsRequest, stream rentalspb.RentalsService_StreamRentalsServer) error {
+	ctx := stream.Context()
+	if err := service.authorizeStatuses(ctx, request); err != nil {
+		return err
+	}
+
+	failedValidation, err := internal.StreamMultipleRentals(ctx, listRentalsParams(ctx, request), func(rental internal.Rental) error {
+		return stream.Send(newRental(rental))
+	})
+	if err != nil {
+		if failedValidation {
+			return statusError(ctx, codes.InvalidArgument, err)
+		}
+
+		if ctx.Err() != nil {
+			return status.FromContextError(ctx.Err()).Err()
+		}
+
+		utils.GetLogger().WithFields(log.Fields{"error": err.Error()}).Error("Error on streaming rentals from the database")
+		return status.Error(codes.Internal, "internal error")
+	}
+	return nil
+}
+
+// authorizeStatuses rejects listing rentals which are not published without the admin token in the authorization
+// metadata, like the REST API does.
+func (service rentalsService) authorizeStatuses(ctx context.Context, request *rentalspb.ListRentalsRequest) error {
+	if service.isAdmin(ctx) {
+		return nil
+	}
+	if err := utils.ValidatePublicStatuses(strings.Join(request.GetStatus(), ",")); err != nil {
+		return statusError(ctx, codes.Unauthenticated, err)
+	}
+	return nil
+}
+
+// isAdmin tells whether the call carries the admin token in the authorization metadata.
+func (service rentalsService) isAdmin(ctx context.Context) bool {
+	values := metadata.ValueFromIncomingContext(ctx, "authorization")
+	return len(values) > 0 && service.adminToken != "" && subtle.ConstantTimeCompare([]byte(values[0]), []byte("Bearer "+service.adminToken)) == 1
+}
+
+// listRentalsParams builds the query string of GET /rentals from the request, so it is validated the same way.
+func listRentalsParams(ctx context.Context, request *rentalspb.ListRentalsRequest) url.Values {
+	params := withDefaultUnits(ctx, url.Values{})
+
+	if request.PriceMin != nil {
+		params.Set("price_min", formatFloat(request.GetPriceMin()))
+	}
+	if request.PriceMax != nil {
+		params.Set("price_max", formatFloat(request.GetPriceMax()))
+	}
+	if request.Limit != nil {
+		params.Set("limit", strconv.Itoa(int(request.GetLimit())))
+	}
+	if request.Offset != nil {
+		params.Set("offset", strconv.Itoa(int(request.GetOffset())))
+	}
+	if request.LengthMin != nil {
+		params.Set("length_min", formatFloat(request.GetLengthMin()))
+	}
+	if request.Radius != nil {
+		params.Set("radius", formatFloat(request.GetRadius()))
+	}
+	if request.Near != nil {
+		params.Set("near", formatLatLng(request.GetNear()))
+	}
+	if request.DeliveryTo != nil {
+		params.Set("delivery_to", formatLatLng(request.GetDeliveryTo()))
+	}
+	if request.CreatedAfter != nil {
+		params.Set("created_after", formatTimestamp(request.GetCreatedAfter()))
+	}
+	if request.UpdatedAfter != nil {
+		params.Set("updated_after", formatTimestamp(request.GetUpdatedAfter()))
+	}
+
+	ids := make([]string, 0, len(request.GetIds()))
+	for _, id := range request.GetIds() {
+		ids = append(ids, strconv.FormatInt(id, 10))
+	}
+	setParam(params, "ids", strings.Join(ids, ","))
+	setParam(params, "amenities", strings.Join(request.GetAmenities(), ","))
+	setParam(params, "status", strings.Join(request.GetStatus(), ","))
+	setParam(params, "sort", request.GetSort())
+	setParam(params, "currency", request.GetCurrency())
+	setParam(params, "units", request.GetUnits())
+	setParam(params, "from", request.GetFrom())
+	setParam(params, "to", request.GetTo())
+	return params
+}
+
+func newRental(rental internal.Rental) *rentalspb.Rental {
+	amenities := make([]string, 0, len(rental.Amenities))
+	for _, amenity := range rental.Amenities {
+		amenities = append(amenities, amenity.Key)
+	}
+
+	return &rentalspb.Rental{
+		Id:              int64(rental.IdRental),
+		Name:            rental.Name,
+		Description:     rental.Description,
+		Type:            rental.Type,
+		Make:            rental.Make,
+		Model:           rental.Model,
+		Year:            int32(rental.Year),
+		Length:          rental.Length,
+		Sleeps:          int32(rental.Sleeps),
+		PrimaryImageUrl: rental.PrimaryImageURL,
+		Status:          rental.Status,
+		Price:           &rentalspb.Price{Day: int64(rental.Price.Day), Currency: rental.Price.Currency},
+		Location: &rentalspb.Location{
+			City:    rental.Location.City,
+			State:   rental.Location.State,
+			Zip:     rental.Location.Zip,
+			Country: rental.Location.Country,
+			Lat:     rental.Location.Lat,
+			Lng:     rental.Location.Lng,
+		},
+		User:      &rentalspb.User{Id: int64(rental.User.Id), FirstName: rental.User.FirstName, LastName: rental.User.LastName},
+		Amenities: amenities,
+		Distance:  rental.Distance,
+		Units:     rental.Units,
+		Created:   timestamppb.New(rental.Created),
+		Updated:   timestamppb.New(rental.Updated),
+	}
+}
+
+// withDefaultUnits picks the units of the accept-language metadata, like the REST API does with the header.
+func withDefaultUnits(ctx context.Context, params url.Values) url.Values {
+	if language := acceptLanguage(ctx); language != "" {
+		params.Set("units", utils.UnitsForLanguage(language))
+	}
+	return params
+}
+
+// statusError returns the error with the code, localized by the accept-language metadata.
+func statusError(ctx context.Context, code codes.Code, err error) error {
+	var localized utils.LocalizedError
+	if errors.As(err, &localized) {
+		return status.Error(code, localized.Localize(utils.NegotiateLanguage(acceptLanguage(ctx))))
+	}
+	return status.Error(code, err.Error())
+}
+
+func acceptLanguage(ctx context.Context) string {
+	if values := metadata.ValueFromIncomingContext(ctx, "accept-language"); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func setParam(params url.Values, key string, value string) {
+	if value != "" {
+		params.Set(key, value)
+	}
+}
+
+func formatLatLng(point *rentalspb.LatLng) string {
+	return formatFloat(point.GetLat()) + "," + formatFloat(point.GetLng())
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func formatTimestamp(timestamp *timestamppb.Timestamp) string {
+	return timestamp.AsTime().Format(time.RFC3339Nano)
+}
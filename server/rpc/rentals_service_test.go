@@ -0,0 +1,103 @@
+package rpc
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"net"
+	"net/url"
+	"outdoorsy-api/internal"
+	"outdoorsy-api/server/rpc/rentalspb"
+	"testing"
+	"time"
+)
+
+func TestListRentalsParamsShouldMatchTheQueryString(test *testing.T) {
+	var (
+		priceMin     = 50.5
+		limit        = int32(10)
+		radius       = 25.0
+		createdAfter = time.Date(2024, 5, 1, 12, 30, 0, 500, time.UTC)
+	)
+	request := &rentalspb.ListRentalsRequest{
+		PriceMin:     &priceMin,
+		Limit:        &limit,
+		Ids:          []int64{1, 2},
+		Near:         &rentalspb.LatLng{Lat: 33.64, Lng: -117.93},
+		Radius:       &radius,
+		Amenities:    []string{"ac", "wifi"},
+		Sort:         "price",
+		Currency:     "EUR",
+		CreatedAfter: timestamppb.New(createdAfter),
+	}
+
+	params := listRentalsParams(context.Background(), request)
+	assert.Equal(test, url.Values{
+		"price_min":     {"50.5"},
+		"limit":         {"10"},
+		"ids":           {"1,2"},
+		"near":          {"33.64,-117.93"},
+		"radius":        {"25"},
+		"amenities":     {"ac,wifi"},
+		"sort":          {"price"},
+		"currency":      {"EUR"},
+		"created_after": {"2024-05-01T12:30:00.0000005Z"},
+	}, params, "Expected the same parameters as GET /rentals")
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("accept-language", "de-DE"))
+	assert.Equal(test, "metric", listRentalsParams(ctx, &rentalspb.ListRentalsRequest{}).Get("units"), "Expected the units of the accept-language metadata")
+}
+
+func TestNewRentalShouldHaveTheTimestampsOfTheRental(test *testing.T) {
+	var (
+		created = time.Date(2023, 3, 14, 9, 0, 0, 0, time.UTC)
+		updated = time.Date(2024, 1, 2, 18, 45, 30, 0, time.UTC)
+	)
+
+	rental := newRental(internal.Rental{IdRental: 1, Created: created, Updated: updated})
+	assert.True(test, created.Equal(rental.GetCreated().AsTime()), "Expected the creation time of the rental")
+	assert.True(test, updated.Equal(rental.GetUpdated().AsTime()), "Expected the last update time of the rental")
+}
+
+func TestRentalsServiceShouldRejectInvalidFiltersBeforeQuerying(test *testing.T) {
+	listener := bufconn.Listen(1024 * 1024)
+	server := NewServer("")
+	go server.Serve(listener)
+	defer server.Stop()
+
+	connection, err := grpc.Dial("bufnet", grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+		return listener.Dial()
+	}), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(test, err, "Expected to connect to the gRPC server")
+	defer connection.Close()
+
+	var (
+		client = rentalspb.NewRentalsServiceClient(connection)
+		limit  = int32(-1)
+	)
+
+	_, err = client.ListRentals(context.Background(), &rentalspb.ListRentalsRequest{Limit: &limit})
+	assert.Equal(test, codes.InvalidArgument, status.Code(err), "Expected an invalid argument for a negative limit")
+
+	stream, err := client.StreamRentals(context.Background(), &rentalspb.ListRentalsRequest{Currency: "XYZ"})
+	assert.NoError(test, err, "Expected the stream to open")
+	_, err = stream.Recv()
+	assert.Equal(test, codes.InvalidArgument, status.Code(err), "Expected an invalid argument for an unknown currency")
+
+	_, err = client.ListRentals(context.Background(), &rentalspb.ListRentalsRequest{Sort: "rentals.id; DROP TABLE rentals"})
+	assert.Equal(test, codes.InvalidArgument, status.Code(err), "Expected an invalid argument for an unknown sort")
+
+	_, err = client.ListRentals(context.Background(), &rentalspb.ListRentalsRequest{Status: []string{"draft"}})
+	assert.Equal(test, codes.Unauthenticated, status.Code(err), "Expected drafts to require the admin token")
+
+	stream, err = client.StreamRentals(context.Background(), &rentalspb.ListRentalsRequest{Status: []string{"published", "unlisted"}})
+	assert.NoError(test, err, "Expected the stream to open")
+	_, err = stream.Recv()
+	assert.Equal(test, codes.Unauthenticated, status.Code(err), "Expected unlisted rentals to require the admin token")
+}
@@ -0,0 +1,213 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v4.25.1
+// source: rentals.proto
+
+package rentalspb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	RentalsService_GetRental_FullMethodName     = "/outdoorsy.rentals.v1.RentalsService/GetRental"
+	RentalsService_ListRentals_FullMethodName   = "/outdoorsy.rentals.v1.RentalsService/ListRentals"
+	RentalsService_StreamRentals_FullMethodName = "/outdoorsy.rentals.v1.RentalsService/StreamRentals"
+)
+
+// RentalsServiceClient is the client API for RentalsService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type RentalsServiceClient interface {
+	GetRental(ctx context.Context, in *GetRentalRequest, opts ...grpc.CallOption) (*Rental, error)
+	ListRentals(ctx context.Context, in *ListRentalsRequest, opts ...grpc.CallOption) (*ListRentalsResponse, error)
+	// StreamRentals sends the rentals of ListRentals one by one while they are read from the database.
+	StreamRentals(ctx context.Context, in *ListRentalsRequest, opts ...grpc.CallOption) (RentalsService_StreamRentalsClient, error)
+}
+
+type rentalsServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRentalsServiceClient(cc grpc.ClientConnInterface) RentalsServiceClient {
+	return &rentalsServiceClient{cc}
+}
+
+func (c *rentalsServiceClient) GetRental(ctx context.Context, in *GetRentalRequest, opts ...grpc.CallOption) (*Rental, error) {
+	out := new(Rental)
+	err := c.cc.Invoke(ctx, RentalsService_GetRental_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rentalsServiceClient) ListRentals(ctx context.Context, in *ListRentalsRequest, opts ...grpc.CallOption) (*ListRentalsResponse, error) {
+	out := new(ListRentalsResponse)
+	err := c.cc.Invoke(ctx, RentalsService_ListRentals_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rentalsServiceClient) StreamRentals(ctx context.Context, in *ListRentalsRequest, opts ...grpc.CallOption) (RentalsService_StreamRentalsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &RentalsService_ServiceDesc.Streams[0], RentalsService_StreamRentals_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &rentalsServiceStreamRentalsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type RentalsService_StreamRentalsClient interface {
+	Recv() (*Rental, error)
+	grpc.ClientStream
+}
+
+type rentalsServiceStreamRentalsClient struct {
+	grpc.ClientStream
+}
+
+func (x *rentalsServiceStreamRentalsClient) Recv() (*Rental, error) {
+	m := new(Rental)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// RentalsServiceServer is the server API for RentalsService service.
+// All implementations must embed UnimplementedRentalsServiceServer
+// for forward compatibility
+type RentalsServiceServer interface {
+	GetRental(context.Context, *GetRentalRequest) (*Rental, error)
+	ListRentals(context.Context, *ListRentalsRequest) (*ListRentalsResponse, error)
+	// StreamRentals sends the rentals of ListRentals one by one while they are read from the database.
+	StreamRentals(*ListRentalsRequest, RentalsService_StreamRentalsServer) error
+	mustEmbedUnimplementedRentalsServiceServer()
+}
+
+// UnimplementedRentalsServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedRentalsServiceServer struct {
+}
+
+func (UnimplementedRentalsServiceServer) GetRental(context.Context, *GetRentalRequest) (*Rental, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRental not implemented")
+}
+func (UnimplementedRentalsServiceServer) ListRentals(context.Context, *ListRentalsRequest) (*ListRentalsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRentals not implemented")
+}
+func (UnimplementedRentalsServiceServer) StreamRentals(*ListRentalsRequest, RentalsService_StreamRentalsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamRentals not implemented")
+}
+func (UnimplementedRentalsServiceServer) mustEmbedUnimplementedRentalsServiceServer() {}
+
+// UnsafeRentalsServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RentalsServiceServer will
+// result in compilation errors.
+type UnsafeRentalsServiceServer interface {
+	mustEmbedUnimplementedRentalsServiceServer()
+}
+
+func RegisterRentalsServiceServer(s grpc.ServiceRegistrar, srv RentalsServiceServer) {
+	s.RegisterService(&RentalsService_ServiceDesc, srv)
+}
+
+func _RentalsService_GetRental_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRentalRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RentalsServiceServer).GetRental(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RentalsService_GetRental_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RentalsServiceServer).GetRental(ctx, req.(*GetRentalRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RentalsService_ListRentals_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRentalsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RentalsServiceServer).ListRentals(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RentalsService_ListRentals_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RentalsServiceServer).ListRentals(ctx, req.(*ListRentalsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RentalsService_StreamRentals_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListRentalsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RentalsServiceServer).StreamRentals(m, &rentalsServiceStreamRentalsServer{stream})
+}
+
+type RentalsService_StreamRentalsServer interface {
+	Send(*Rental) error
+	grpc.ServerStream
+}
+
+type rentalsServiceStreamRentalsServer struct {
+	grpc.ServerStream
+}
+
+func (x *rentalsServiceStreamRentalsServer) Send(m *Rental) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// RentalsService_ServiceDesc is the grpc.ServiceDesc for RentalsService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var RentalsService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "outdoorsy.rentals.v1.RentalsService",
+	HandlerType: (*RentalsServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetRental",
+			Handler:    _RentalsService_GetRental_Handler,
+		},
+		{
+			MethodName: "ListRentals",
+			Handler:    _RentalsService_ListRentals_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamRentals",
+			Handler:       _RentalsService_StreamRentals_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "rentals.proto",
+}
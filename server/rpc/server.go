@@ -0,0 +1,64 @@
+package rpc
+
+import (
+	"context"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"net"
+	"outdoorsy-api/database"
+	"outdoorsy-api/server/rpc/rentalspb"
+	"outdoorsy-api/utils"
+	"time"
+)
+
+const healthCheckInterval = 10 * time.Second
+
+// NewServer returns the gRPC server with the rentals, health and reflection services registered. Calls with the
+// admin token as bearer authorization metadata can list rentals which are not published.
+func NewServer(adminToken string) *grpc.Server {
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(logErrors))
+	rentalspb.RegisterRentalsServiceServer(server, rentalsService{adminToken: adminToken})
+	reflection.Register(server)
+	return server
+}
+
+// Serve serves the gRPC API on the port until the listener fails. The health service reports the rentals service
+// as serving while the database responds to pings.
+func Serve(port string, adminToken string) error {
+	listener, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		return err
+	}
+
+	server := NewServer(adminToken)
+	healthServer := health.NewServer()
+	grpc_health_v1.RegisterHealthServer(server, healthServer)
+	go watchDatabase(healthServer)
+
+	utils.GetLogger().WithFields(log.Fields{"port": port}).Debug("gRPC server started ...")
+	return server.Serve(listener)
+}
+
+func watchDatabase(healthServer *health.Server) {
+	for {
+		servingStatus := grpc_health_v1.HealthCheckResponse_SERVING
+		if database.Ping() != nil {
+			servingStatus = grpc_health_v1.HealthCheckResponse_NOT_SERVING
+		}
+		healthServer.SetServingStatus("", servingStatus)
+		healthServer.SetServingStatus(rentalspb.RentalsService_ServiceDesc.ServiceName, servingStatus)
+		time.Sleep(healthCheckInterval)
+	}
+}
+
+func logErrors(ctx context.Context, request interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	response, err := handler(ctx, request)
+	if err != nil {
+		utils.GetLogger().WithFields(log.Fields{"method": info.FullMethod, "error": err.Error(), "latency": time.Since(start).String()}).Info("gRPC request failed")
+	}
+	return response, err
+}
@@ -1,14 +1,24 @@
 package server
 
 import (
+	"context"
 	"github.com/gin-gonic/gin"
 	log "github.com/sirupsen/logrus"
+	"net/http"
+	"os"
+	"os/signal"
 	"outdoorsy-api/server/handlers"
 	"outdoorsy-api/server/interceptors"
 	"outdoorsy-api/server/middlewares"
+	"outdoorsy-api/server/rpc"
 	"outdoorsy-api/utils"
+	"syscall"
+	"time"
 )
 
+// shutdownTimeout is how long the requests in progress may take to finish once the server is asked to stop.
+const shutdownTimeout = 10 * time.Second
+
 func setupRouter() (router *gin.Engine) {
 	gin.SetMode(gin.ReleaseMode)
 	router = gin.New()
@@ -17,16 +27,135 @@ func setupRouter() (router *gin.Engine) {
 	return
 }
 
-func Run() {
+// Options holds the configuration the web server needs besides the database.
+type Options struct {
+	AdminToken string
+	GRPCPort   string
+	// UnversionedSunset is when the unversioned aliases of the /v1 routes will be removed.
+	UnversionedSunset time.Time
+}
+
+// unversionedDeprecation is when /v1 was introduced, from when the unversioned routes are only kept for the
+// clients which haven't moved to /v1 yet.
+var unversionedDeprecation = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+
+// registerRoutes registers the REST API. Every route must be described in server/openapi/openapi.yaml, the
+// versioned routes without their version prefix, and is validated against it. Deprecated routes are signaled
+// before, so even rejected calls are counted.
+func registerRoutes(router *gin.Engine, options Options) {
+	router.Use(middlewares.AdminIdentification(options.AdminToken))
+
+	service := router.Group("", middlewares.RequestValidation())
+	service.GET("/healths", handlers.HealthCheck)
+	service.GET("/metrics", handlers.Metrics)
+	service.GET("/openapi.json", handlers.OpenAPIHandler)
+	service.GET("/docs", handlers.DocsHandler)
+	service.GET("/docs/:file", handlers.DocsAssetHandler)
+	service.POST("/graphql", handlers.GraphQLHandler)
+
+	registerV1Routes(router.Group("/v1", middlewares.RequestValidation()), options)
+	registerV1Routes(router.Group("", middlewares.Deprecated(middlewares.Deprecation{
+		Version:    "unversioned",
+		Deprecated: unversionedDeprecation,
+		Sunset:     options.UnversionedSunset,
+		Successor:  "/v1",
+	}), middlewares.RequestValidation()), options)
+}
+
+// registerV1Routes registers version 1 of the REST API, whose responses are the bodies of the v1 package.
+func registerV1Routes(group *gin.RouterGroup, options Options) {
+	group.GET("/rentals/:id", handlers.SingleRentalHandler)
+	group.GET("/rentals", handlers.MultipleRentalsHandler)
+	group.GET("/rentals/trending", handlers.TrendingRentalsHandler)
+	group.GET("/rentals/events", handlers.RentalEventsHandler)
+	group.GET("/rentals/changes", middlewares.AdminAuthorization(options.AdminToken), handlers.RentalChangesHandler)
+	group.GET("/rentals/:id/calendar.ics", handlers.RentalCalendarExportHandler)
+	group.GET("/rentals/:id/quote", handlers.QuoteHandler)
+	group.GET("/rentals/:id/price-suggestion", handlers.PriceSuggestionHandler)
+	group.GET("/rentals/:id/trip-estimate", handlers.TripEstimateHandler)
+	group.GET("/exchange-rates", handlers.ExchangeRatesHandler)
+	group.POST("/users/:id/rentals", handlers.CreateRentalHandler)
+	group.POST("/users/:id/rentals/:rental_id/:action", handlers.OwnerRentalTransitionHandler)
+	group.PUT("/users/:id/rentals/:rental_id/delivery", handlers.RentalDeliveryHandler)
+	group.PUT("/users/:id/rentals/:rental_id/booking-rules", handlers.RentalBookingRulesHandler)
+	group.POST("/users/:id/rentals/:rental_id/addons", handlers.CreateAddonHandler)
+	group.PUT("/users/:id/rentals/:rental_id/addons/:addon_id", handlers.UpdateAddonHandler)
+	group.DELETE("/users/:id/rentals/:rental_id/addons/:addon_id", handlers.DeleteAddonHandler)
+	group.POST("/users/:id/rentals/:rental_id/blackouts", handlers.CreateBlackoutHandler)
+	group.DELETE("/users/:id/rentals/:rental_id/blackouts/:blackout_id", handlers.DeleteBlackoutHandler)
+	group.POST("/users/:id/rentals/:rental_id/calendar", handlers.RentalCalendarImportHandler)
+	group.POST("/users/:id/rentals/bulk", handlers.BulkRentalsHandler)
+	group.GET("/users/:id/stats", handlers.OwnerStatsHandler)
+	group.POST("/rentals/:id/bookings", handlers.CreateBookingHandler)
+	group.GET("/bookings/:id", handlers.SingleBookingHandler)
+	group.POST("/users/:id/bookings/:booking_id/cancel", handlers.CancelBookingHandler)
+	group.GET("/amenities", handlers.AmenitiesHandler)
+
+	webhooks := group.Group("/webhooks", middlewares.AdminAuthorization(options.AdminToken))
+	webhooks.POST("", handlers.CreateWebhookHandler)
+	webhooks.GET("", handlers.WebhooksHandler)
+	webhooks.GET("/:id", handlers.SingleWebhookHandler)
+	webhooks.PUT("/:id", handlers.UpdateWebhookHandler)
+	webhooks.DELETE("/:id", handlers.DeleteWebhookHandler)
+	webhooks.GET("/:id/deliveries", handlers.WebhookDeliveriesHandler)
+
+	admin := group.Group("/admin", middlewares.AdminAuthorization(options.AdminToken))
+	admin.GET("/moderation-queue", handlers.ModerationQueueHandler)
+	admin.GET("/rentals/:id/transitions", handlers.RentalStatusTransitionsHandler)
+	admin.POST("/rentals/:id/:action", handlers.AdminRentalTransitionHandler)
+	admin.POST("/promo-codes", handlers.CreatePromoCodeHandler)
+	admin.GET("/promo-codes", handlers.PromoCodesHandler)
+	admin.GET("/promo-codes/:code", handlers.SinglePromoCodeHandler)
+	admin.DELETE("/promo-codes/:code", handlers.DeactivatePromoCodeHandler)
+	admin.POST("/exchange-rates/refresh", handlers.RefreshExchangeRatesHandler)
+}
+
+// Run serves the API until the process is asked to stop with SIGINT or SIGTERM. The server then stops taking new
+// requests and gives the ones in progress shutdownTimeout to finish before it returns.
+func Run(options Options) {
 	router := setupRouter()
-	router.GET("/healths", handlers.HealthCheck)
-	router.GET("/metrics", handlers.Metrics)
-	router.GET("/rentals/:id", handlers.SingleRentalHandler)
-	router.GET("/rentals", handlers.MultipleRentalsHandler)
+	registerRoutes(router, options)
 
-	err := router.Run()
-	if err != nil {
-		utils.GetLogger().WithFields(log.Fields{"error": err.Error()}).Error("Unable to start web server")
+	if options.GRPCPort != "" {
+		go func() {
+			if err := rpc.Serve(options.GRPCPort, options.AdminToken); err != nil {
+				utils.GetLogger().WithFields(log.Fields{"error": err.Error(), "port": options.GRPCPort}).Error("Unable to start gRPC server")
+			}
+		}()
 	}
+
+	httpServer := &http.Server{Addr: address(), Handler: router}
+	failed := make(chan error, 1)
+	go func() {
+		failed <- httpServer.ListenAndServe()
+	}()
 	utils.GetLogger().Debug("Web server started ...")
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(stop)
+
+	select {
+	case err := <-failed:
+		utils.GetLogger().WithFields(log.Fields{"error": err.Error()}).Error("Unable to start web server")
+		return
+	case <-stop:
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(ctx); err != nil {
+		// event streams never finish on their own, they are cut once the timeout is over
+		utils.GetLogger().WithFields(log.Fields{"error": err.Error()}).Info("Closing the requests left after the shutdown timeout")
+		_ = httpServer.Close()
+	}
+	utils.GetLogger().Debug("Web server stopped ...")
+}
+
+// address is the address the web server listens on, the PORT environment variable or 8080 like gin does.
+func address() string {
+	if port := os.Getenv("PORT"); port != "" {
+		return ":" + port
+	}
+	return ":8080"
 }
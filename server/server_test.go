@@ -10,8 +10,12 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"outdoorsy-api/database"
-	"outdoorsy-api/server/handlers"
+	"outdoorsy-api/internal"
+	"outdoorsy-api/server/openapi"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 )
 
 type configurations struct {
@@ -24,6 +28,10 @@ type configurations struct {
 
 var router *gin.Engine
 
+const adminToken = "test-admin-token"
+
+var sunset = time.Date(2027, time.April, 19, 0, 0, 0, 0, time.UTC)
+
 func setupTest(test *testing.T) func() {
 	var (
 		parser = koanf.New(".")
@@ -54,10 +62,7 @@ func setupTest(test *testing.T) func() {
 	)
 
 	router = gin.New()
-	router.GET("/healths", handlers.HealthCheck)
-	router.GET("/metrics", handlers.Metrics)
-	router.GET("/rentals/:id", handlers.SingleRentalHandler)
-	router.GET("/rentals", handlers.MultipleRentalsHandler)
+	registerRoutes(router, Options{AdminToken: adminToken, UnversionedSunset: sunset})
 
 	return func() {
 	}
@@ -105,3 +110,222 @@ func TestSingleRentalHandler(test *testing.T) {
 		assert.Equal(t, http.StatusBadRequest, responseRecorder.Code)
 	})
 }
+
+func TestAmenitiesHandler(test *testing.T) {
+	defer setupTest(test)()
+
+	test.Run("SuccessfulRequest", func(t *testing.T) {
+		request, err := http.NewRequest("GET", "/amenities", nil)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		responseRecorder := httptest.NewRecorder()
+
+		router.ServeHTTP(responseRecorder, request)
+
+		assert.Equal(t, http.StatusOK, responseRecorder.Code)
+	})
+
+	test.Run("UnknownAmenityFilter", func(t *testing.T) {
+		request, err := http.NewRequest("GET", "/rentals?amenities=hot_tub", nil)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		responseRecorder := httptest.NewRecorder()
+
+		router.ServeHTTP(responseRecorder, request)
+
+		assert.Equal(t, http.StatusBadRequest, responseRecorder.Code)
+	})
+}
+
+func TestLocalizedValidationErrors(test *testing.T) {
+	defer setupTest(test)()
+
+	test.Run("NegotiatedLanguage", func(t *testing.T) {
+		request, err := http.NewRequest("GET", "/rentals?price_min=-1", nil)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		request.Header.Set("Accept-Language", "it-IT, de-DE;q=0.9, en;q=0.8")
+
+		responseRecorder := httptest.NewRecorder()
+
+		router.ServeHTTP(responseRecorder, request)
+
+		assert.Equal(t, http.StatusBadRequest, responseRecorder.Code)
+		assert.Equal(t, "de", responseRecorder.Header().Get("Content-Language"))
+		assert.JSONEq(t, `{"error": "der Preis muss eine positive Zahl sein", "code": "price_not_positive"}`, responseRecorder.Body.String())
+	})
+
+	test.Run("DefaultLanguage", func(t *testing.T) {
+		request, err := http.NewRequest("GET", "/rentals/0.5", nil)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		responseRecorder := httptest.NewRecorder()
+
+		router.ServeHTTP(responseRecorder, request)
+
+		assert.Equal(t, http.StatusBadRequest, responseRecorder.Code)
+		assert.JSONEq(t, `{"error": "provided parameter should be of type int", "code": "id_not_integer"}`, responseRecorder.Body.String())
+	})
+}
+
+func TestModerationQueueHandler(test *testing.T) {
+	defer setupTest(test)()
+
+	test.Run("Unauthorized", func(t *testing.T) {
+		request, err := http.NewRequest("GET", "/admin/moderation-queue", nil)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		request.Header.Set("Authorization", "Bearer wrong-token")
+
+		responseRecorder := httptest.NewRecorder()
+
+		router.ServeHTTP(responseRecorder, request)
+
+		assert.Equal(t, http.StatusUnauthorized, responseRecorder.Code)
+	})
+
+	test.Run("SuccessfulRequest", func(t *testing.T) {
+		request, err := http.NewRequest("GET", "/admin/moderation-queue", nil)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		request.Header.Set("Authorization", "Bearer "+adminToken)
+
+		responseRecorder := httptest.NewRecorder()
+
+		router.ServeHTTP(responseRecorder, request)
+
+		assert.Equal(t, http.StatusOK, responseRecorder.Code)
+	})
+}
+
+func TestSingleRentalHandlerShouldHideRentalsWhichAreNotPublished(test *testing.T) {
+	defer setupTest(test)()
+
+	draft, failedValidation, err := internal.CreateDraftRental(1, internal.RentalInput{
+		Name:     "Hidden draft van",
+		Type:     "camper-van",
+		Sleeps:   2,
+		Price:    internal.Price{Day: 9900},
+		Location: internal.Location{City: "Denver", State: "CO", Country: "US", Lat: 39.67, Lng: -104.92},
+	})
+	if err != nil || failedValidation {
+		test.Fatalf("Error on creating draft rental - %v", err)
+	}
+	defer func() {
+		_ = database.Exec("DELETE FROM rentals WHERE rentals.id = $1;", draft.IdRental)
+	}()
+	path := "/v1/rentals/" + strconv.Itoa(draft.IdRental)
+
+	for _, authorization := range []string{"", "Bearer wrong-token"} {
+		request := httptest.NewRequest(http.MethodGet, path, nil)
+		if authorization != "" {
+			request.Header.Set("Authorization", authorization)
+		}
+		responseRecorder := httptest.NewRecorder()
+
+		router.ServeHTTP(responseRecorder, request)
+
+		assert.Equal(test, http.StatusNoContent, responseRecorder.Code, "Expected the draft not to be found without the admin token")
+	}
+
+	request := httptest.NewRequest(http.MethodGet, path, nil)
+	request.Header.Set("Authorization", "Bearer "+adminToken)
+	responseRecorder := httptest.NewRecorder()
+
+	router.ServeHTTP(responseRecorder, request)
+
+	assert.Equal(test, http.StatusOK, responseRecorder.Code, "Expected admins to get the draft")
+}
+
+func TestOpenAPIDocumentsEveryRoute(test *testing.T) {
+	engine := gin.New()
+	registerRoutes(engine, Options{})
+
+	registered := make(map[string]bool)
+	for _, route := range engine.Routes() {
+		path := openapi.SpecificationPath(route.Path)
+		registered[route.Method+" "+path] = true
+
+		pathItem := openapi.Document().Paths.Find(path)
+		if assert.NotNil(test, pathItem, "Expected %s to be documented", path) {
+			assert.NotNil(test, pathItem.GetOperation(route.Method), "Expected %s %s to be documented", route.Method, path)
+		}
+	}
+
+	for path, pathItem := range openapi.Document().Paths {
+		for method := range pathItem.Operations() {
+			assert.True(test, registered[method+" "+path], "Expected %s %s to be registered", method, path)
+		}
+	}
+}
+
+func TestUnpublishedStatusesShouldRequireTheAdminToken(test *testing.T) {
+	engine := gin.New()
+	registerRoutes(engine, Options{AdminToken: adminToken})
+
+	for _, path := range []string{"/v1/rentals?status=draft", "/v1/rentals?status=published,unlisted&format=csv", "/v1/rentals/events?status=pending_review"} {
+		request := httptest.NewRequest(http.MethodGet, path, nil)
+		request.Header.Set("Authorization", "Bearer wrong-token")
+		responseRecorder := httptest.NewRecorder()
+
+		engine.ServeHTTP(responseRecorder, request)
+
+		assert.Equal(test, http.StatusUnauthorized, responseRecorder.Code, "Expected %s to require the admin token", path)
+		assert.JSONEq(test, `{"error": "admin authorization is required to list rentals which are not published", "code": "statuses_unauthorized"}`, responseRecorder.Body.String(), "Expected the error of %s", path)
+	}
+}
+
+func TestRentalChangesShouldRequireTheAdminToken(test *testing.T) {
+	engine := gin.New()
+	registerRoutes(engine, Options{AdminToken: adminToken})
+
+	request := httptest.NewRequest(http.MethodGet, "/v1/rentals/changes", nil)
+	responseRecorder := httptest.NewRecorder()
+
+	engine.ServeHTTP(responseRecorder, request)
+
+	assert.Equal(test, http.StatusUnauthorized, responseRecorder.Code, "Expected the change feed to require the admin token")
+	assert.JSONEq(test, `{"error": "admin authorization is required", "code": "admin_unauthorized"}`, responseRecorder.Body.String(), "Expected the admin error")
+}
+
+func TestUnversionedRoutesShouldBeDeprecatedAliasesOfV1(test *testing.T) {
+	engine := gin.New()
+	registerRoutes(engine, Options{UnversionedSunset: sunset})
+
+	for _, path := range []string{"/v1/rentals/0.5", "/rentals/0.5?currency=EUR"} {
+		request := httptest.NewRequest(http.MethodGet, path, nil)
+		responseRecorder := httptest.NewRecorder()
+
+		engine.ServeHTTP(responseRecorder, request)
+
+		assert.Equal(test, http.StatusBadRequest, responseRecorder.Code, "Expected %s to be validated alike", path)
+		assert.JSONEq(test, `{"error": "provided parameter should be of type int", "code": "id_not_integer"}`, responseRecorder.Body.String(), "Expected the error of %s", path)
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "/v1/rentals/0.5", nil)
+	responseRecorder := httptest.NewRecorder()
+	engine.ServeHTTP(responseRecorder, request)
+	assert.Empty(test, responseRecorder.Header().Get("Deprecation"), "Expected v1 not to be deprecated")
+
+	request = httptest.NewRequest(http.MethodGet, "/rentals/0.5?currency=EUR", nil)
+	responseRecorder = httptest.NewRecorder()
+	engine.ServeHTTP(responseRecorder, request)
+	assert.Equal(test, "@1792368000", responseRecorder.Header().Get("Deprecation"), "Expected the deprecation date")
+	assert.Equal(test, "Mon, 19 Apr 2027 00:00:00 GMT", responseRecorder.Header().Get("Sunset"), "Expected the sunset date")
+	assert.Equal(test, `</v1/rentals/0.5?currency=EUR>; rel="successor-version"`, responseRecorder.Header().Get("Link"), "Expected the v1 route as the successor")
+
+	request = httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	responseRecorder = httptest.NewRecorder()
+	engine.ServeHTTP(responseRecorder, request)
+	calls := `api_deprecated_calls_total{method="GET",route="/rentals/:id",version="unversioned"} 2`
+	assert.True(test, strings.Contains(responseRecorder.Body.String(), calls), "Expected the deprecated calls to be counted")
+}
@@ -0,0 +1,33 @@
+package v1
+
+import (
+	"outdoorsy-api/internal"
+	"time"
+)
+
+type RentalChange struct {
+	Id      int       `json:"id"`
+	Updated time.Time `json:"updated"`
+	Deleted bool      `json:"deleted"`
+	Rental  *Rental   `json:"rental"`
+}
+
+type RentalChanges struct {
+	Changes []RentalChange `json:"changes"`
+	Next    string         `json:"next"`
+	HasMore bool           `json:"has_more"`
+}
+
+// NewRentalChanges presents a page of the change feed. Deleted rentals have a null rental.
+func NewRentalChanges(changes internal.RentalChanges) RentalChanges {
+	response := RentalChanges{Changes: make([]RentalChange, 0, len(changes.Changes)), Next: changes.Next, HasMore: changes.HasMore}
+	for _, change := range changes.Changes {
+		presented := RentalChange{Id: change.RentalId, Updated: change.Updated, Deleted: change.Deleted}
+		if change.Rental != nil {
+			rental := NewRental(*change.Rental)
+			presented.Rental = &rental
+		}
+		response.Changes = append(response.Changes, presented)
+	}
+	return response
+}
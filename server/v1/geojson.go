@@ -0,0 +1,70 @@
+package v1
+
+import "outdoorsy-api/internal"
+
+const (
+	geoJSONFeature           = "Feature"
+	geoJSONFeatureCollection = "FeatureCollection"
+	geoJSONPoint             = "Point"
+)
+
+type GeoJSONPoint struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+// RentalProperties are the properties of a rental feature - every field of the rental, the location without the
+// coordinates, which are the geometry of the feature.
+type RentalProperties struct {
+	Rental
+	Location FeatureLocation `json:"location"`
+}
+
+type FeatureLocation struct {
+	City    string `json:"city"`
+	State   string `json:"state"`
+	Zip     string `json:"zip"`
+	Country string `json:"country"`
+}
+
+type RentalFeature struct {
+	Type       string           `json:"type"`
+	Id         int              `json:"id"`
+	Geometry   GeoJSONPoint     `json:"geometry"`
+	Properties RentalProperties `json:"properties"`
+}
+
+type RentalFeatureCollection struct {
+	Type     string          `json:"type"`
+	Features []RentalFeature `json:"features"`
+}
+
+// NewRentalFeature returns the rental as a GeoJSON Point feature. GeoJSON coordinates are ordered longitude first.
+func NewRentalFeature(rental internal.Rental) RentalFeature {
+	return RentalFeature{
+		Type: geoJSONFeature,
+		Id:   rental.IdRental,
+		Geometry: GeoJSONPoint{
+			Type:        geoJSONPoint,
+			Coordinates: [2]float64{rental.Location.Lng, rental.Location.Lat},
+		},
+		Properties: RentalProperties{
+			Rental: NewRental(rental),
+			Location: FeatureLocation{
+				City:    rental.Location.City,
+				State:   rental.Location.State,
+				Zip:     rental.Location.Zip,
+				Country: rental.Location.Country,
+			},
+		},
+	}
+}
+
+// NewRentalFeatureCollection returns the rentals as a GeoJSON FeatureCollection, in the same order.
+func NewRentalFeatureCollection(rentals []internal.Rental) RentalFeatureCollection {
+	features := make([]RentalFeature, 0, len(rentals))
+	for _, rental := range rentals {
+		features = append(features, NewRentalFeature(rental))
+	}
+	return RentalFeatureCollection{Type: geoJSONFeatureCollection, Features: features}
+}
@@ -0,0 +1,35 @@
+package v1
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"outdoorsy-api/internal"
+	"testing"
+)
+
+func TestNewRentalFeatureCollectionShouldPlaceRentalsAsPoints(test *testing.T) {
+	rentals := []internal.Rental{{
+		IdRental: 4,
+		Name:     "Van",
+		Location: internal.Location{City: "Boulder", State: "CO", Lat: 40.01, Lng: -105.27},
+	}}
+
+	body, err := json.Marshal(NewRentalFeatureCollection(rentals))
+	assert.NoError(test, err, "Expected the collection to be encoded")
+
+	var collection map[string]interface{}
+	assert.NoError(test, json.Unmarshal(body, &collection), "Expected valid JSON")
+	assert.Equal(test, "FeatureCollection", collection["type"], "Expected a feature collection")
+
+	feature := collection["features"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(test, "Feature", feature["type"], "Expected a feature per rental")
+	assert.Equal(test, float64(4), feature["id"], "Expected the rental id as the feature id")
+	assert.Equal(test, map[string]interface{}{"type": "Point", "coordinates": []interface{}{-105.27, 40.01}}, feature["geometry"], "Expected a point ordered longitude first")
+
+	properties := feature["properties"].(map[string]interface{})
+	assert.Equal(test, "Van", properties["name"], "Expected the rental fields as properties")
+	assert.Equal(test, map[string]interface{}{"city": "Boulder", "state": "CO", "zip": "", "country": ""}, properties["location"], "Expected the location without coordinates")
+
+	body, _ = json.Marshal(NewRentalFeatureCollection(nil))
+	assert.JSONEq(test, `{"type": "FeatureCollection", "features": []}`, string(body), "Expected an empty collection without rentals")
+}
@@ -0,0 +1,189 @@
+// Package v1 holds the response bodies of version 1 of the REST API. They are built from the internal structs, so
+// the columns and the fields of internal.Rental can change without changing what v1 clients receive.
+package v1
+
+import (
+	"outdoorsy-api/internal"
+	"time"
+)
+
+type Price struct {
+	Day      int    `json:"day"`
+	Currency string `json:"currency"`
+}
+
+type Location struct {
+	City    string  `json:"city"`
+	State   string  `json:"state"`
+	Zip     string  `json:"zip"`
+	Country string  `json:"country"`
+	Lat     float64 `json:"lat"`
+	Lng     float64 `json:"lng"`
+}
+
+type User struct {
+	Id        int    `json:"id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+}
+
+type Delivery struct {
+	Radius     float64  `json:"radius"`
+	FeePerMile int      `json:"fee_per_mile"`
+	MinimumFee int      `json:"minimum_fee"`
+	Distance   *float64 `json:"distance,omitempty"`
+	Fee        *int     `json:"fee,omitempty"`
+}
+
+type BookingRules struct {
+	MinNights        *int     `json:"min_nights"`
+	MaxNights        *int     `json:"max_nights"`
+	CheckInDays      []string `json:"check_in_days"`
+	NoticeHours      int      `json:"notice_hours"`
+	MaxAdvanceMonths *int     `json:"max_advance_months"`
+}
+
+type Amenity struct {
+	Id   int    `json:"id"`
+	Key  string `json:"key"`
+	Name string `json:"name"`
+}
+
+type Addon struct {
+	Id          int    `json:"id"`
+	RentalId    int    `json:"rental_id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	PricingMode string `json:"pricing_mode"`
+	Price       int    `json:"price"`
+	MaxQuantity *int   `json:"max_quantity"`
+	Available   bool   `json:"available"`
+}
+
+type Rental struct {
+	Id              int          `json:"id"`
+	Name            string       `json:"name"`
+	Description     string       `json:"description"`
+	Type            string       `json:"type"`
+	Make            string       `json:"make"`
+	Model           string       `json:"model"`
+	Year            int          `json:"year"`
+	Length          float64      `json:"length"`
+	Sleeps          int          `json:"sleeps"`
+	PrimaryImageURL string       `json:"primary_image_url"`
+	Status          string       `json:"status"`
+	Created         time.Time    `json:"created"`
+	Updated         time.Time    `json:"updated"`
+	Price           Price        `json:"price"`
+	Location        Location     `json:"location"`
+	User            User         `json:"user"`
+	Delivery        Delivery     `json:"delivery"`
+	BookingRules    BookingRules `json:"booking_rules"`
+	Amenities       []Amenity    `json:"amenities"`
+	Addons          []Addon      `json:"addons,omitempty"`
+	Distance        *float64     `json:"distance,omitempty"`
+	Units           string       `json:"units"`
+}
+
+type TrendingRental struct {
+	Rental Rental  `json:"rental"`
+	Views  int     `json:"views"`
+	Score  float64 `json:"score"`
+}
+
+func NewRental(rental internal.Rental) Rental {
+	return Rental{
+		Id:              rental.IdRental,
+		Name:            rental.Name,
+		Description:     rental.Description,
+		Type:            rental.Type,
+		Make:            rental.Make,
+		Model:           rental.Model,
+		Year:            rental.Year,
+		Length:          rental.Length,
+		Sleeps:          rental.Sleeps,
+		PrimaryImageURL: rental.PrimaryImageURL,
+		Status:          rental.Status,
+		Created:         rental.Created,
+		Updated:         rental.Updated,
+		Price:           Price{Day: rental.Price.Day, Currency: rental.Price.Currency},
+		Location: Location{
+			City:    rental.Location.City,
+			State:   rental.Location.State,
+			Zip:     rental.Location.Zip,
+			Country: rental.Location.Country,
+			Lat:     rental.Location.Lat,
+			Lng:     rental.Location.Lng,
+		},
+		User: User{Id: rental.User.Id, FirstName: rental.User.FirstName, LastName: rental.User.LastName},
+		Delivery: Delivery{
+			Radius:     rental.Delivery.Radius,
+			FeePerMile: rental.Delivery.FeePerMile,
+			MinimumFee: rental.Delivery.MinimumFee,
+			Distance:   rental.Delivery.Distance,
+			Fee:        rental.Delivery.Fee,
+		},
+		BookingRules: BookingRules{
+			MinNights:        rental.BookingRules.MinNights,
+			MaxNights:        rental.BookingRules.MaxNights,
+			CheckInDays:      rental.BookingRules.CheckInDays,
+			NoticeHours:      rental.BookingRules.NoticeHours,
+			MaxAdvanceMonths: rental.BookingRules.MaxAdvanceMonths,
+		},
+		Amenities: newAmenities(rental.Amenities),
+		Addons:    newAddons(rental.Addons),
+		Distance:  rental.Distance,
+		Units:     rental.Units,
+	}
+}
+
+// NewRentals returns the rentals in the same order. Without rentals it returns an empty list.
+func NewRentals(rentals []internal.Rental) []Rental {
+	response := make([]Rental, 0, len(rentals))
+	for _, rental := range rentals {
+		response = append(response, NewRental(rental))
+	}
+	return response
+}
+
+func NewTrendingRentals(trending []internal.TrendingRental) []TrendingRental {
+	response := make([]TrendingRental, 0, len(trending))
+	for _, rental := range trending {
+		response = append(response, TrendingRental{Rental: NewRental(rental.Rental), Views: rental.Views, Score: rental.Score})
+	}
+	return response
+}
+
+// newAmenities keeps a missing list missing, so rentals whose amenities weren't loaded still have null amenities.
+func newAmenities(amenities []internal.Amenity) []Amenity {
+	if amenities == nil {
+		return nil
+	}
+
+	response := make([]Amenity, 0, len(amenities))
+	for _, amenity := range amenities {
+		response = append(response, Amenity{Id: amenity.Id, Key: amenity.Key, Name: amenity.Name})
+	}
+	return response
+}
+
+func newAddons(addons []internal.Addon) []Addon {
+	if addons == nil {
+		return nil
+	}
+
+	response := make([]Addon, 0, len(addons))
+	for _, addon := range addons {
+		response = append(response, Addon{
+			Id:          addon.Id,
+			RentalId:    addon.RentalId,
+			Name:        addon.Name,
+			Description: addon.Description,
+			PricingMode: addon.PricingMode,
+			Price:       addon.Price,
+			MaxQuantity: addon.MaxQuantity,
+			Available:   addon.Available,
+		})
+	}
+	return response
+}
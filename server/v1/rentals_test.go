@@ -0,0 +1,37 @@
+package v1
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"outdoorsy-api/internal"
+	"testing"
+)
+
+// Version 1 was introduced with the fields of internal.Rental, so until they diverge both encode alike.
+func TestNewRentalShouldEncodeLikeTheRentalsBeforeVersioning(test *testing.T) {
+	distance, fee, maxNights := 12.5, 4000, 14
+	rental := internal.Rental{
+		IdRental:     7,
+		Name:         "Airstream",
+		Status:       "published",
+		Price:        internal.Price{Day: 15000, Currency: "USD"},
+		Location:     internal.Location{City: "Moab", State: "UT", Lat: 38.57, Lng: -109.55},
+		User:         internal.User{Id: 2, FirstName: "Ann"},
+		Delivery:     internal.Delivery{Radius: 50, Distance: &distance, Fee: &fee},
+		BookingRules: internal.BookingRules{MaxNights: &maxNights, CheckInDays: []string{"friday"}},
+		Amenities:    []internal.Amenity{{Id: 1, Key: "ac", Name: "Air conditioning"}},
+		Addons:       []internal.Addon{{Id: 3, RentalId: 7, Name: "Linens", PricingMode: "per_trip", Available: true}},
+		Distance:     &distance,
+		Units:        "imperial",
+	}
+
+	for _, rental := range []internal.Rental{rental, {IdRental: 8}} {
+		expected, _ := json.Marshal(rental)
+		actual, err := json.Marshal(NewRental(rental))
+		assert.NoError(test, err, "Expected the rental to be encoded")
+		assert.JSONEq(test, string(expected), string(actual), "Expected the fields of the rental")
+	}
+
+	body, _ := json.Marshal(NewRentals(nil))
+	assert.Equal(test, "[]", string(body), "Expected an empty list without rentals")
+}
@@ -67,4 +67,418 @@ VALUES
     (2, E'Coya | Van-gelina Jolie',E'camper-van',E'lacus cras molestie nam dapibus ullamcorper massa ultricies bibendum lectus auctor nisi ridiculus ultricies tristique curabitur diam feugiat erat inceptos sapien vivamus parturient sem nibh',2,20000,E'Seattle',E'WA',E'98116',E'US',E'Ford',E'Transit',2019,20,E'2021-11-29 22:42:06.478595+00',E'2021-11-29 22:42:06.478595+00',47.56,-122.39,E'https://res.cloudinary.com/outdoorsy/image/upload/v1582091293/p/rentals/153401/images/kaqt2b6n6sm1xnmvbi5w.jpg'),
     (3, E'sCAMPer X',E'camper-van',E'ac tellus phasellus ultrices nostra eros aenean metus ridiculus adipiscing habitant nulla cubilia tortor rhoncus quisque sem ultrices varius massa mollis congue praesent nam ante',4,17500,E'Atlanta',E'GA',E'30310',E'US',E'Ram',E'Promaster',2020,19,E'2021-11-29 22:42:06.478595+00',E'2021-11-29 22:42:06.478595+00',33.73,-84.41,E'https://res.cloudinary.com/outdoorsy/image/upload/v1589910541/p/rentals/156152/images/jvyvtqoeljadoizjjzag.jpg'),
     (4, E'2015 Dodge Sprinter Van',E'camper-van',E'pretium non litora lobortis pharetra elit sociosqu platea nostra interdum odio vestibulum tincidunt mi blandit convallis pellentesque tempor viverra fermentum ultricies nunc egestas id arcu',2,17000,E'Silverthorne',E'CO',E'80498',E'US',E'Dodge',E'Sprinter Van',2015,20,E'2021-11-29 22:42:06.478595+00',E'2021-11-29 22:42:06.478595+00',39.62,-106.09,E'https://res.cloudinary.com/outdoorsy/image/upload/v1588550855/p/rentals/162781/images/az0xp8wbdto4pjzlkyh3.jpg'),
-    (5, E'The New Adventures of Pearl - 2014 Nissan NV2500 High Top',E'camper-van',E'malesuada eget conubia porta sollicitudin urna ad aenean lacus vulputate parturient vulputate suspendisse sit parturient ante mauris maecenas dignissim donec eget adipiscing dui luctus eget',2,18900,E'Denver',E'CO',E'80222',E'US',E'Nissan',E'NV2500',2014,20,E'2021-11-29 22:42:06.478595+00',E'2021-11-29 22:42:06.478595+00',39.67,-104.92,E'https://res.cloudinary.com/outdoorsy/image/upload/v1590500837/undefined/rentals/164961/images/t3nkxdl0ua8g6gp1idcm.jpg');
\ No newline at end of file
+    (5, E'The New Adventures of Pearl - 2014 Nissan NV2500 High Top',E'camper-van',E'malesuada eget conubia porta sollicitudin urna ad aenean lacus vulputate parturient vulputate suspendisse sit parturient ante mauris maecenas dignissim donec eget adipiscing dui luctus eget',2,18900,E'Denver',E'CO',E'80222',E'US',E'Nissan',E'NV2500',2014,20,E'2021-11-29 22:42:06.478595+00',E'2021-11-29 22:42:06.478595+00',39.67,-104.92,E'https://res.cloudinary.com/outdoorsy/image/upload/v1590500837/undefined/rentals/164961/images/t3nkxdl0ua8g6gp1idcm.jpg');
+
+CREATE TABLE IF NOT EXISTS amenities (
+                                         id SERIAL PRIMARY KEY,
+                                         key text UNIQUE NOT NULL,
+                                         name text NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS rental_amenities (
+                                                rental_id integer REFERENCES rentals (id) ON DELETE CASCADE,
+                                                amenity_id integer REFERENCES amenities (id) ON DELETE CASCADE,
+                                                PRIMARY KEY (rental_id, amenity_id)
+);
+
+INSERT INTO "amenities"("key", "name")
+VALUES
+    ('generator', 'Generator'),
+    ('pet_friendly', 'Pet friendly'),
+    ('bike_rack', 'Bike rack'),
+    ('solar', 'Solar panels'),
+    ('kitchen', 'Kitchen'),
+    ('shower', 'Shower'),
+    ('toilet', 'Toilet'),
+    ('air_conditioning', 'Air conditioning')
+;
+
+INSERT INTO "rental_amenities"("rental_id", "amenity_id")
+VALUES
+    (1, 2), (1, 5),
+    (2, 2), (2, 3), (2, 5),
+    (3, 1), (3, 5),
+    (4, 3), (4, 4), (4, 8),
+    (5, 1), (5, 2), (5, 5),
+    (6, 5),
+    (7, 2), (7, 5),
+    (8, 3), (8, 4), (8, 5), (8, 6), (8, 7),
+    (9, 8),
+    (10, 1), (10, 2), (10, 5),
+    (11, 2), (11, 4), (11, 5),
+    (12, 3),
+    (13, 4), (13, 5), (13, 6),
+    (14, 1), (14, 2), (14, 3), (14, 4), (14, 5), (14, 6), (14, 7), (14, 8),
+    (15, 5),
+    (16, 2), (16, 3),
+    (17, 3), (17, 4),
+    (18, 2),
+    (19, 1), (19, 5), (19, 8),
+    (20, 2), (20, 3), (20, 4), (20, 5),
+    (21, 1), (21, 2), (21, 4), (21, 5), (21, 6),
+    (22, 5),
+    (23, 2), (23, 5),
+    (24, 3),
+    (25, 4), (25, 5),
+    (26, 1), (26, 5), (26, 8),
+    (27, 2), (27, 5),
+    (28, 3), (28, 4), (28, 5), (28, 6), (28, 7), (28, 8),
+    (29, 5),
+    (30, 1), (30, 2), (30, 5)
+;
+
+CREATE TABLE IF NOT EXISTS rental_blocked_ranges (
+                                                     id SERIAL PRIMARY KEY,
+                                                     rental_id integer NOT NULL REFERENCES rentals (id) ON DELETE CASCADE,
+                                                     uid text NOT NULL,
+                                                     summary text NOT NULL DEFAULT '',
+                                                     starts_on date NOT NULL,
+                                                     ends_on date NOT NULL,
+                                                     source text NOT NULL DEFAULT 'owner',
+    created timestamp with time zone NOT NULL DEFAULT now(),
+    updated timestamp with time zone NOT NULL DEFAULT now(),
+    UNIQUE (rental_id, uid),
+    CHECK (ends_on > starts_on)
+    );
+
+ALTER TABLE rentals ADD COLUMN IF NOT EXISTS status text NOT NULL DEFAULT 'published'
+    CHECK (status IN ('draft', 'pending_review', 'published', 'unlisted'));
+
+CREATE TABLE IF NOT EXISTS rental_status_transitions (
+                                                         id SERIAL PRIMARY KEY,
+                                                         rental_id integer NOT NULL REFERENCES rentals (id) ON DELETE CASCADE,
+                                                         from_status text,
+                                                         to_status text NOT NULL,
+                                                         actor text NOT NULL,
+                                                         actor_id integer,
+                                                         reason text NOT NULL DEFAULT '',
+    created timestamp with time zone NOT NULL DEFAULT now()
+    );
+
+CREATE TABLE IF NOT EXISTS promo_codes (
+                                           id SERIAL PRIMARY KEY,
+                                           code text UNIQUE NOT NULL,
+                                           discount_type text NOT NULL CHECK (discount_type IN ('percentage', 'fixed')),
+                                           discount_value integer NOT NULL CHECK (discount_value > 0),
+                                           min_nights integer NOT NULL DEFAULT 0,
+                                           valid_from timestamp with time zone,
+                                           valid_until timestamp with time zone,
+                                           max_redemptions integer,
+                                           max_redemptions_per_user integer,
+                                           rental_types text[] NOT NULL DEFAULT '{}',
+                                           rental_states text[] NOT NULL DEFAULT '{}',
+                                           active boolean NOT NULL DEFAULT true,
+    created timestamp with time zone NOT NULL DEFAULT now(),
+    updated timestamp with time zone NOT NULL DEFAULT now()
+    );
+
+CREATE TABLE IF NOT EXISTS bookings (
+                                        id SERIAL PRIMARY KEY,
+                                        rental_id integer NOT NULL REFERENCES rentals (id) ON DELETE CASCADE,
+                                        user_id integer NOT NULL REFERENCES users (id),
+                                        starts_on date NOT NULL,
+                                        ends_on date NOT NULL,
+                                        status text NOT NULL DEFAULT 'confirmed' CHECK (status IN ('confirmed', 'cancelled')),
+                                        nightly_price bigint NOT NULL,
+                                        discount bigint NOT NULL DEFAULT 0,
+                                        total bigint NOT NULL,
+                                        promo_code_id integer REFERENCES promo_codes (id),
+    created timestamp with time zone NOT NULL DEFAULT now(),
+    updated timestamp with time zone NOT NULL DEFAULT now(),
+    CHECK (ends_on > starts_on)
+    );
+
+CREATE INDEX IF NOT EXISTS bookings_rental_dates_idx ON bookings (rental_id, starts_on, ends_on);
+
+CREATE TABLE IF NOT EXISTS promo_code_redemptions (
+                                                      id SERIAL PRIMARY KEY,
+                                                      promo_code_id integer NOT NULL REFERENCES promo_codes (id),
+                                                      booking_id integer NOT NULL UNIQUE REFERENCES bookings (id) ON DELETE CASCADE,
+                                                      user_id integer NOT NULL REFERENCES users (id),
+                                                      discount bigint NOT NULL,
+    created timestamp with time zone NOT NULL DEFAULT now()
+    );
+
+ALTER TABLE rentals ADD COLUMN IF NOT EXISTS included_miles_per_day integer NOT NULL DEFAULT 100;
+ALTER TABLE rentals ADD COLUMN IF NOT EXISTS mileage_overage_fee integer NOT NULL DEFAULT 35;
+ALTER TABLE rentals ADD COLUMN IF NOT EXISTS mpg numeric(4,1) NOT NULL DEFAULT 15 CHECK (mpg > 0);
+
+ALTER TABLE rentals ADD COLUMN IF NOT EXISTS delivery_radius numeric(6,2) NOT NULL DEFAULT 0 CHECK (delivery_radius >= 0);
+ALTER TABLE rentals ADD COLUMN IF NOT EXISTS delivery_fee_per_mile integer NOT NULL DEFAULT 0 CHECK (delivery_fee_per_mile >= 0);
+ALTER TABLE rentals ADD COLUMN IF NOT EXISTS delivery_minimum_fee integer NOT NULL DEFAULT 0 CHECK (delivery_minimum_fee >= 0);
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS delivery_fee bigint NOT NULL DEFAULT 0;
+
+UPDATE rentals
+SET delivery_radius       = 50,
+    delivery_fee_per_mile = 250,
+    delivery_minimum_fee  = 5000
+WHERE id IN (1, 3, 5, 8, 12, 17, 24);
+
+CREATE TABLE IF NOT EXISTS rental_addons (
+                                             id SERIAL PRIMARY KEY,
+                                             rental_id integer NOT NULL REFERENCES rentals (id) ON DELETE CASCADE,
+                                             name text NOT NULL,
+                                             description text NOT NULL DEFAULT '',
+                                             pricing_mode text NOT NULL CHECK (pricing_mode IN ('per_day', 'per_trip', 'per_unit')),
+                                             price integer NOT NULL CHECK (price > 0),
+                                             max_quantity integer CHECK (max_quantity > 0),
+                                             available boolean NOT NULL DEFAULT true,
+    created timestamp with time zone NOT NULL DEFAULT now(),
+    updated timestamp with time zone NOT NULL DEFAULT now(),
+    CHECK ((pricing_mode = 'per_unit') = (max_quantity IS NOT NULL))
+    );
+
+CREATE TABLE IF NOT EXISTS booking_addons (
+                                              id SERIAL PRIMARY KEY,
+                                              booking_id integer NOT NULL REFERENCES bookings (id) ON DELETE CASCADE,
+                                              addon_id integer REFERENCES rental_addons (id) ON DELETE SET NULL,
+                                              name text NOT NULL,
+                                              quantity integer NOT NULL,
+                                              amount bigint NOT NULL
+);
+
+INSERT INTO rental_addons (rental_id, name, description, pricing_mode, price, max_quantity)
+VALUES (1, 'Linens', 'Bedding and towels for every guest', 'per_trip', 4500, NULL),
+       (1, 'Bike rack', 'Rack for up to four bikes', 'per_day', 1000, NULL),
+       (1, 'Generator hours', 'Prepaid generator run time', 'per_unit', 300, 50),
+       (1, 'Pet fee', 'Cleaning after pets', 'per_trip', 7500, NULL),
+       (3, 'Linens', 'Bedding and towels for every guest', 'per_trip', 4000, NULL),
+       (3, 'Generator hours', 'Prepaid generator run time', 'per_unit', 350, 40);
+
+ALTER TABLE rentals ADD COLUMN IF NOT EXISTS min_nights integer CHECK (min_nights > 0);
+ALTER TABLE rentals ADD COLUMN IF NOT EXISTS max_nights integer CHECK (max_nights > 0);
+ALTER TABLE rentals ADD COLUMN IF NOT EXISTS check_in_days text[] NOT NULL DEFAULT '{}';
+ALTER TABLE rentals ADD COLUMN IF NOT EXISTS notice_hours integer NOT NULL DEFAULT 0 CHECK (notice_hours >= 0);
+ALTER TABLE rentals ADD COLUMN IF NOT EXISTS max_advance_months integer CHECK (max_advance_months > 0);
+
+UPDATE rentals
+SET min_nights         = 3,
+    check_in_days      = '{monday,tuesday,wednesday,thursday,friday,saturday}',
+    notice_hours       = 48,
+    max_advance_months = 9
+WHERE id IN (2, 4);
+
+UPDATE rentals
+SET min_nights = 2,
+    max_nights = 14
+WHERE id IN (6, 9);
+
+CREATE TABLE IF NOT EXISTS rental_view_counts (
+                                                  rental_id integer NOT NULL REFERENCES rentals (id) ON DELETE CASCADE,
+                                                  day date NOT NULL,
+                                                  kind text NOT NULL CHECK (kind IN ('view', 'impression')),
+                                                  count bigint NOT NULL DEFAULT 0,
+                                                  PRIMARY KEY (rental_id, day, kind)
+);
+
+CREATE INDEX IF NOT EXISTS rental_view_counts_day_idx ON rental_view_counts (day, kind);
+
+CREATE TABLE IF NOT EXISTS rental_events (
+                                             id bigserial PRIMARY KEY,
+                                             rental_id integer NOT NULL,
+                                             kind text NOT NULL CHECK (kind IN ('created', 'updated', 'deleted')),
+                                             created timestamp with time zone NOT NULL DEFAULT now()
+);
+
+-- Every change of a rental is logged and notified on the rental_events channel once committed. The log keeps the
+-- last 10000 events, from which interrupted event streams resume. The ids are taken under a lock held until the
+-- transaction ends, so the events are committed in the order of their ids and a stream resuming after an id can't
+-- miss an event committed later with a lower id.
+CREATE OR REPLACE FUNCTION record_rental_event() RETURNS trigger AS
+$$
+DECLARE
+    event rental_events;
+BEGIN
+    IF TG_OP = 'UPDATE' AND NEW IS NOT DISTINCT FROM OLD THEN
+        RETURN NULL;
+    END IF;
+
+    PERFORM pg_advisory_xact_lock(hashtext('rental_events'));
+    INSERT INTO rental_events (rental_id, kind)
+    VALUES (CASE WHEN TG_OP = 'DELETE' THEN OLD.id ELSE NEW.id END,
+            CASE TG_OP WHEN 'INSERT' THEN 'created' WHEN 'UPDATE' THEN 'updated' ELSE 'deleted' END)
+    RETURNING * INTO event;
+
+    DELETE FROM rental_events WHERE id <= event.id - 10000;
+    PERFORM pg_notify('rental_events', row_to_json(event)::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS rental_events_trigger ON rentals;
+CREATE TRIGGER rental_events_trigger
+    AFTER INSERT OR UPDATE OR DELETE
+    ON rentals
+    FOR EACH ROW
+EXECUTE PROCEDURE record_rental_event();
+
+CREATE TABLE IF NOT EXISTS webhooks (
+                                        id SERIAL PRIMARY KEY,
+                                        url text NOT NULL,
+                                        event_types text[] NOT NULL,
+                                        secret text NOT NULL,
+                                        active boolean NOT NULL DEFAULT true,
+    created timestamp with time zone NOT NULL DEFAULT now(),
+    updated timestamp with time zone NOT NULL DEFAULT now()
+    );
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+                                                  id bigserial PRIMARY KEY,
+                                                  webhook_id integer NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+                                                  event_type text NOT NULL,
+                                                  payload jsonb NOT NULL,
+                                                  status text NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'succeeded', 'dead')),
+                                                  attempts integer NOT NULL DEFAULT 0,
+                                                  next_attempt timestamp with time zone NOT NULL DEFAULT now(),
+                                                  response_status integer,
+                                                  error text NOT NULL DEFAULT '',
+    created timestamp with time zone NOT NULL DEFAULT now(),
+    updated timestamp with time zone NOT NULL DEFAULT now()
+    );
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_due_idx ON webhook_deliveries (next_attempt) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_idx ON webhook_deliveries (webhook_id, id);
+
+-- Deliveries are queued in the transaction of the change, so no event is lost if the API stops before sending them.
+-- The payload carries the id of the resource, receivers read its current state from the API.
+CREATE OR REPLACE FUNCTION enqueue_webhook_deliveries(event_type text, data jsonb) RETURNS void AS
+$$
+BEGIN
+    INSERT INTO webhook_deliveries (webhook_id, event_type, payload)
+    SELECT webhooks.id,
+           event_type,
+           jsonb_build_object('type', event_type, 'created', now(), 'data', data)
+    FROM webhooks
+    WHERE webhooks.active
+      AND event_type = ANY (webhooks.event_types);
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION record_rental_webhook_event() RETURNS trigger AS
+$$
+BEGIN
+    IF TG_OP = 'UPDATE' AND NEW IS NOT DISTINCT FROM OLD THEN
+        RETURN NULL;
+    END IF;
+
+    IF TG_OP = 'DELETE' THEN
+        PERFORM enqueue_webhook_deliveries('rental.deleted', jsonb_build_object('id', OLD.id));
+    ELSIF TG_OP = 'INSERT' THEN
+        PERFORM enqueue_webhook_deliveries('rental.created', jsonb_build_object('id', NEW.id));
+    ELSE
+        PERFORM enqueue_webhook_deliveries('rental.updated', jsonb_build_object('id', NEW.id));
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS rental_webhooks_trigger ON rentals;
+CREATE TRIGGER rental_webhooks_trigger
+    AFTER INSERT OR UPDATE OR DELETE
+    ON rentals
+    FOR EACH ROW
+EXECUTE PROCEDURE record_rental_webhook_event();
+
+CREATE OR REPLACE FUNCTION record_booking_webhook_event() RETURNS trigger AS
+$$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        PERFORM enqueue_webhook_deliveries('booking.created', jsonb_build_object('id', NEW.id, 'rental_id', NEW.rental_id));
+    ELSIF NEW.status = 'cancelled' AND OLD.status <> 'cancelled' THEN
+        PERFORM enqueue_webhook_deliveries('booking.cancelled', jsonb_build_object('id', NEW.id, 'rental_id', NEW.rental_id));
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS booking_webhooks_trigger ON bookings;
+CREATE TRIGGER booking_webhooks_trigger
+    AFTER INSERT OR UPDATE
+    ON bookings
+    FOR EACH ROW
+EXECUTE PROCEDURE record_booking_webhook_event();
+
+-- rentals.created is the time the rental was added and never changes. rentals.updated is the time of its last
+-- change, which the change feed pages by. Updates changing nothing keep it, unless they set it to touch the rental.
+CREATE OR REPLACE FUNCTION touch_rental() RETURNS trigger AS
+$$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        NEW.created := COALESCE(NEW.created, now());
+    ELSE
+        NEW.created := OLD.created;
+        IF NEW IS NOT DISTINCT FROM OLD THEN
+            RETURN NEW;
+        END IF;
+    END IF;
+
+    NEW.updated := now();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS rentals_touch_trigger ON rentals;
+
+UPDATE rentals
+SET created = COALESCE(created, updated, now()),
+    updated = COALESCE(updated, created, now())
+WHERE created IS NULL
+   OR updated IS NULL;
+
+ALTER TABLE rentals ALTER COLUMN created SET DEFAULT now();
+ALTER TABLE rentals ALTER COLUMN created SET NOT NULL;
+ALTER TABLE rentals ALTER COLUMN updated SET DEFAULT now();
+ALTER TABLE rentals ALTER COLUMN updated SET NOT NULL;
+
+CREATE TRIGGER rentals_touch_trigger
+    BEFORE INSERT OR UPDATE
+    ON rentals
+    FOR EACH ROW
+EXECUTE PROCEDURE touch_rental();
+
+CREATE INDEX IF NOT EXISTS rentals_updated_idx ON rentals (updated, id);
+
+-- Deleted rentals are kept as tombstones, so the change feed tells its consumers to remove them.
+CREATE TABLE IF NOT EXISTS rental_tombstones (
+                                                 rental_id integer PRIMARY KEY,
+                                                 deleted timestamp with time zone NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS rental_tombstones_deleted_idx ON rental_tombstones (deleted, rental_id);
+
+CREATE OR REPLACE FUNCTION record_rental_tombstone() RETURNS trigger AS
+$$
+BEGIN
+    INSERT INTO rental_tombstones (rental_id)
+    VALUES (OLD.id)
+    ON CONFLICT (rental_id) DO UPDATE SET deleted = now();
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS rental_tombstones_trigger ON rentals;
+CREATE TRIGGER rental_tombstones_trigger
+    AFTER DELETE
+    ON rentals
+    FOR EACH ROW
+EXECUTE PROCEDURE record_rental_tombstone();
+
+CREATE INDEX IF NOT EXISTS rentals_created_idx ON rentals (created, id);
+
+-- The add-ons are part of the rental, changing them touches it.
+CREATE OR REPLACE FUNCTION touch_addon_rental() RETURNS trigger AS
+$$
+BEGIN
+    UPDATE rentals
+    SET updated = now()
+    WHERE rentals.id = CASE WHEN TG_OP = 'DELETE' THEN OLD.rental_id ELSE NEW.rental_id END;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS rental_addons_touch_trigger ON rental_addons;
+CREATE TRIGGER rental_addons_touch_trigger
+    AFTER INSERT OR UPDATE OR DELETE
+    ON rental_addons
+    FOR EACH ROW
+EXECUTE PROCEDURE touch_addon_rental();
@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	UnitsImperial = "imperial"
+	UnitsMetric   = "metric"
+)
+
+// imperialRegions are the regions which still use imperial units for lengths and distances.
+var imperialRegions = map[string]struct{}{"US": {}, "LR": {}, "MM": {}}
+
+// ParseAcceptLanguage returns the language tags of an Accept-Language header ordered by their quality, the most
+// preferred first. Tags with zero quality are left out.
+func ParseAcceptLanguage(header string) (tags []string) {
+	type weightedTag struct {
+		tag     string
+		quality float64
+	}
+
+	var weighted []weightedTag
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" {
+			continue
+		}
+
+		quality := 1.0
+		if value, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+
+		if quality > 0 {
+			weighted = append(weighted, weightedTag{tag: tag, quality: quality})
+		}
+	}
+
+	sort.SliceStable(weighted, func(i, j int) bool {
+		return weighted[i].quality > weighted[j].quality
+	})
+
+	for _, value := range weighted {
+		tags = append(tags, value.tag)
+	}
+	return
+}
+
+// UnitsForLanguage derives the default unit system from an Accept-Language header. A missing header, a wildcard
+// or English without a region keep the imperial units the API always used.
+func UnitsForLanguage(header string) string {
+	tags := ParseAcceptLanguage(header)
+	if len(tags) == 0 || tags[0] == "*" {
+		return UnitsImperial
+	}
+
+	var (
+		subtags = strings.Split(tags[0], "-")
+		region  string
+	)
+	for _, subtag := range subtags[1:] {
+		if len(subtag) == 2 {
+			region = strings.ToUpper(subtag)
+			break
+		}
+	}
+
+	if region == "" {
+		if strings.EqualFold(subtags[0], "en") {
+			return UnitsImperial
+		}
+		return UnitsMetric
+	}
+
+	if _, found := imperialRegions[region]; found {
+		return UnitsImperial
+	}
+	return UnitsMetric
+}
@@ -0,0 +1,23 @@
+package utils
+
+import "math"
+
+const (
+	EarthRadiusKilometers = 6371.0088
+	EarthRadiusMiles      = 3958.7613
+)
+
+// GreatCircleDistance returns the haversine distance between two points. The result is in the unit of the
+// provided earth radius.
+func GreatCircleDistance(fromLat float64, fromLng float64, toLat float64, toLng float64, earthRadius float64) float64 {
+	var (
+		fromLatRadians = fromLat * math.Pi / 180
+		toLatRadians   = toLat * math.Pi / 180
+		deltaLat       = (toLat - fromLat) * math.Pi / 180
+		deltaLng       = (toLng - fromLng) * math.Pi / 180
+	)
+
+	haversine := math.Pow(math.Sin(deltaLat/2), 2) +
+		math.Cos(fromLatRadians)*math.Cos(toLatRadians)*math.Pow(math.Sin(deltaLng/2), 2)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(haversine)))
+}
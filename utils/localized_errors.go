@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultLanguage is used when none of the languages accepted by the client has a message catalog.
+const DefaultLanguage = "en"
+
+// LocalizedError is an error identified by a stable code, so clients can either show the message localized by
+// the API or translate the code themselves. Error returns the English message.
+type LocalizedError struct {
+	Code string
+	Args []interface{}
+}
+
+func NewLocalizedError(code string, args ...interface{}) LocalizedError {
+	return LocalizedError{Code: code, Args: args}
+}
+
+func (err LocalizedError) Error() string {
+	return err.Localize(DefaultLanguage)
+}
+
+// Localize returns the message of the error in the language, falling back to English if the language or the
+// code is missing from the catalogs.
+func (err LocalizedError) Localize(language string) string {
+	template, found := messageCatalogs[language][err.Code]
+	if !found {
+		template, found = messageCatalogs[DefaultLanguage][err.Code]
+	}
+	if !found {
+		return err.Code
+	}
+
+	if len(err.Args) == 0 {
+		return template
+	}
+	return fmt.Sprintf(template, err.Args...)
+}
+
+// NegotiateLanguage picks the most preferred language of an Accept-Language header which has a message catalog.
+func NegotiateLanguage(header string) string {
+	for _, tag := range ParseAcceptLanguage(header) {
+		language, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		if _, found := messageCatalogs[language]; found {
+			return language
+		}
+	}
+	return DefaultLanguage
+}
@@ -0,0 +1,366 @@
+package utils
+
+// messageCatalogs holds the error messages of every supported language by error code. The codes are part of
+// the API and must not be changed, new messages get new codes.
+var messageCatalogs = map[string]map[string]string{
+	"en": {
+		"id_not_integer":                 "provided parameter should be of type int",
+		"rental_not_found":               "rental not found",
+		"price_not_positive":             "price must be a positive number",
+		"price_min_not_less_than_max":    "price_min must be less than price_max",
+		"price_max_not_more_than_min":    "price_max must be more than price_min",
+		"pagination_not_positive":        "limit and offset must be a positive integer",
+		"ids_not_integers":               "ids should be int values",
+		"ids_not_positive":               "ids should be positive numbers greater than 0",
+		"near_missing_separator":         "there should be comma separator for the near parameter",
+		"near_not_pair":                  "near values should be a comma separated pair",
+		"near_not_numeric":               "near values should be numeric",
+		"amenities_invalid_list":         "amenities should be a comma separated list of amenity keys",
+		"amenity_key_invalid_characters": "amenity keys may contain only lowercase letters and underscores",
+		"amenity_unknown":                "unknown amenity: %s",
+		"currency_invalid_code":          "currency must be a three letter ISO 4217 code",
+		"currency_unsupported":           "unsupported currency: %s",
+		"units_invalid":                  "units must be either metric or imperial",
+		"radius_without_near":            "radius can be used only together with near",
+		"number_not_positive":            "%s must be a positive number",
+		"dates_required":                 "from and to dates are required",
+		"date_invalid_format":            "dates must be in the YYYY-MM-DD format",
+		"date_range_invalid":             "to must be after from",
+		"calendar_file_missing":          "calendar should be uploaded as the file form field",
+		"calendar_invalid":               "calendar is invalid: %s",
+		"calendar_too_large":             "calendar can be at most %d MB",
+		"user_not_found":                 "user not found",
+		"request_body_invalid":           "request body is not valid JSON",
+		"rental_field_required":          "%s is required",
+		"rental_field_invalid":           "%s is invalid",
+		"statuses_invalid":               "status must be a comma separated list of draft, pending_review, published or unlisted",
+		"rental_action_unknown":          "unknown rental action: %s",
+		"reason_required":                "a reason is required to reject a rental",
+		"rental_transition_invalid":      "can not %s a rental in %s status",
+		"admin_unauthorized":             "admin authorization is required",
+		"field_required":                 "%s is required",
+		"field_invalid":                  "%s is invalid",
+		"promo_code_exists":              "promo code %s already exists",
+		"promo_code_unknown":             "promo code %s does not exist",
+		"promo_code_inactive":            "promo code %s is no longer active",
+		"promo_code_not_started":         "promo code %s is valid from %s",
+		"promo_code_expired":             "promo code %s expired on %s",
+		"promo_code_min_nights":          "promo code %s requires a trip of at least %d nights",
+		"promo_code_rental_type":         "promo code %s does not apply to %s rentals",
+		"promo_code_rental_state":        "promo code %s does not apply to rentals in %s",
+		"promo_code_exhausted":           "promo code %s has reached its redemption limit",
+		"promo_code_user_required":       "promo code %s can be applied only for a known user_id",
+		"promo_code_user_exhausted":      "promo code %s was already redeemed the maximum number of times by this user",
+		"date_in_past":                   "from must not be in the past",
+		"rental_not_bookable":            "rental is not published and can not be booked",
+		"rental_unavailable":             "rental is not available between %s and %s",
+		"booking_not_found":              "booking not found",
+		"booking_not_cancellable":        "a booking in %s status can not be cancelled",
+		"coordinates_invalid":            "%s must be a comma separated latitude and longitude pair",
+		"delivery_not_offered":           "rental does not offer delivery",
+		"delivery_out_of_range":          "delivery point is %.2f miles away, the rental delivers within %g miles",
+		"addons_invalid_list":            "addons should be a comma separated list of add-on ids, each optionally followed by :quantity",
+		"addon_unknown":                  "add-on %d is not offered by the rental",
+		"addon_duplicate":                "add-on %s is selected more than once",
+		"addon_unavailable":              "add-on %s is not available",
+		"addon_quantity_invalid":         "add-on %s can be selected in a quantity from 1 to %d",
+		"addon_not_found":                "add-on not found",
+		"booking_notice_required":        "the rental requires %d hours notice before check-in",
+		"booking_too_far_ahead":          "the rental can be booked at most %d months ahead",
+		"booking_check_in_day":           "check-in is not allowed on %s, the rental allows check-in on %s",
+		"booking_min_nights":             "the rental requires a minimum stay of %d nights",
+		"booking_max_nights":             "the rental allows a maximum stay of %d nights",
+		"blackout_conflicts_booking":     "rental has a confirmed booking between %s and %s",
+		"bulk_price_not_positive":        "adjusted price %d is not positive",
+		"rental_status_change_invalid":   "a rental in %s status can not be changed to %s",
+		"bulk_too_many":                  "%s can have at most %d entries",
+		"blackout_not_found":             "blackout not found",
+		"stats_range_too_long":           "from and to can be at most %d days apart",
+		"granularity_invalid":            "granularity should be day, week or month",
+		"window_invalid":                 "window should be a number of days from 1d to %dd",
+		"format_invalid":                 "format should be json, csv, ndjson or geojson",
+		"graphql_query_too_deep":         "query depth %d exceeds the maximum of %d",
+		"graphql_query_too_complex":      "query complexity %d exceeds the maximum of %d",
+		"graphql_first_invalid":          "first should be a number from 1 to %d",
+		"graphql_cursor_invalid":         "after is not a valid cursor",
+		"parameter_invalid":              "%s is not valid",
+		"webhook_not_found":              "webhook not found",
+		"delivery_status_invalid":        "status must be one of pending, succeeded or dead",
+		"since_invalid":                  "since must be the next token of a previous page of changes",
+		"timestamp_invalid":              "%s must be an RFC 3339 timestamp or a YYYY-MM-DD date",
+		"statuses_unauthorized":          "admin authorization is required to list rentals which are not published",
+		"sort_invalid":                   "sort should be id, name, year, length, sleeps, price or newest",
+		"rental_events_unavailable":      "rental events are not available at the moment, retry later",
+		"delivery_out_of_range_metric":   "delivery point is %.2f km away, the rental delivers within %g km",
+	},
+	"es": {
+		"id_not_integer":                 "el parámetro proporcionado debe ser de tipo entero",
+		"rental_not_found":               "alquiler no encontrado",
+		"price_not_positive":             "el precio debe ser un número positivo",
+		"price_min_not_less_than_max":    "price_min debe ser menor que price_max",
+		"price_max_not_more_than_min":    "price_max debe ser mayor que price_min",
+		"pagination_not_positive":        "limit y offset deben ser enteros positivos",
+		"ids_not_integers":               "ids deben ser valores enteros",
+		"ids_not_positive":               "ids deben ser números positivos mayores que 0",
+		"near_missing_separator":         "el parámetro near debe contener una coma como separador",
+		"near_not_pair":                  "near debe ser un par de valores separados por coma",
+		"near_not_numeric":               "los valores de near deben ser numéricos",
+		"amenities_invalid_list":         "amenities debe ser una lista de claves de comodidades separadas por comas",
+		"amenity_key_invalid_characters": "las claves de comodidades solo pueden contener letras minúsculas y guiones bajos",
+		"amenity_unknown":                "comodidad desconocida: %s",
+		"currency_invalid_code":          "la moneda debe ser un código ISO 4217 de tres letras",
+		"currency_unsupported":           "moneda no admitida: %s",
+		"units_invalid":                  "units debe ser metric o imperial",
+		"radius_without_near":            "radius solo puede usarse junto con near",
+		"number_not_positive":            "%s debe ser un número positivo",
+		"dates_required":                 "las fechas from y to son obligatorias",
+		"date_invalid_format":            "las fechas deben tener el formato AAAA-MM-DD",
+		"date_range_invalid":             "to debe ser posterior a from",
+		"calendar_file_missing":          "el calendario debe subirse en el campo de formulario file",
+		"calendar_invalid":               "el calendario no es válido: %s",
+		"calendar_too_large":             "el calendario puede ocupar como máximo %d MB",
+		"user_not_found":                 "usuario no encontrado",
+		"request_body_invalid":           "el cuerpo de la solicitud no es un JSON válido",
+		"rental_field_required":          "%s es obligatorio",
+		"rental_field_invalid":           "%s no es válido",
+		"statuses_invalid":               "status debe ser una lista separada por comas de draft, pending_review, published o unlisted",
+		"rental_action_unknown":          "acción de alquiler desconocida: %s",
+		"reason_required":                "se requiere un motivo para rechazar un alquiler",
+		"rental_transition_invalid":      "no se puede aplicar la acción %s a un alquiler en estado %s",
+		"admin_unauthorized":             "se requiere autorización de administrador",
+		"field_required":                 "%s es obligatorio",
+		"field_invalid":                  "%s no es válido",
+		"promo_code_exists":              "el código promocional %s ya existe",
+		"promo_code_unknown":             "el código promocional %s no existe",
+		"promo_code_inactive":            "el código promocional %s ya no está activo",
+		"promo_code_not_started":         "el código promocional %s es válido a partir del %s",
+		"promo_code_expired":             "el código promocional %s caducó el %s",
+		"promo_code_min_nights":          "el código promocional %s requiere un viaje de al menos %d noches",
+		"promo_code_rental_type":         "el código promocional %s no se aplica a alquileres de tipo %s",
+		"promo_code_rental_state":        "el código promocional %s no se aplica a alquileres en %s",
+		"promo_code_exhausted":           "el código promocional %s alcanzó su límite de canjes",
+		"promo_code_user_required":       "el código promocional %s solo puede aplicarse con un user_id conocido",
+		"promo_code_user_exhausted":      "este usuario ya canjeó el código promocional %s el número máximo de veces",
+		"date_in_past":                   "from no puede estar en el pasado",
+		"rental_not_bookable":            "el alquiler no está publicado y no puede reservarse",
+		"rental_unavailable":             "el alquiler no está disponible entre %s y %s",
+		"booking_not_found":              "reserva no encontrada",
+		"booking_not_cancellable":        "una reserva en estado %s no puede cancelarse",
+		"coordinates_invalid":            "%s debe ser un par de latitud y longitud separados por coma",
+		"delivery_not_offered":           "el alquiler no ofrece entrega",
+		"delivery_out_of_range":          "el punto de entrega está a %.2f millas, el alquiler entrega dentro de %g millas",
+		"addons_invalid_list":            "addons debe ser una lista de ids de extras separados por comas, cada uno opcionalmente seguido de :cantidad",
+		"addon_unknown":                  "el alquiler no ofrece el extra %d",
+		"addon_duplicate":                "el extra %s está seleccionado más de una vez",
+		"addon_unavailable":              "el extra %s no está disponible",
+		"addon_quantity_invalid":         "el extra %s puede seleccionarse en una cantidad de 1 a %d",
+		"addon_not_found":                "extra no encontrado",
+		"booking_notice_required":        "el alquiler requiere un aviso de %d horas antes de la entrada",
+		"booking_too_far_ahead":          "el alquiler se puede reservar como máximo con %d meses de antelación",
+		"booking_check_in_day":           "no se permite la entrada en %s, el alquiler permite la entrada en %s",
+		"booking_min_nights":             "el alquiler requiere una estancia mínima de %d noches",
+		"booking_max_nights":             "el alquiler permite una estancia máxima de %d noches",
+		"blackout_conflicts_booking":     "el alquiler tiene una reserva confirmada entre %s y %s",
+		"bulk_price_not_positive":        "el precio ajustado %d no es positivo",
+		"rental_status_change_invalid":   "un alquiler en estado %s no puede cambiar a %s",
+		"bulk_too_many":                  "%s puede tener como máximo %d elementos",
+		"blackout_not_found":             "bloqueo no encontrado",
+		"stats_range_too_long":           "from y to pueden estar separados como máximo %d días",
+		"granularity_invalid":            "granularity debe ser day, week o month",
+		"window_invalid":                 "window debe ser un número de días de 1d a %dd",
+		"format_invalid":                 "format debe ser json, csv, ndjson o geojson",
+		"graphql_query_too_deep":         "la profundidad de la consulta %d supera el máximo de %d",
+		"graphql_query_too_complex":      "la complejidad de la consulta %d supera el máximo de %d",
+		"graphql_first_invalid":          "first debe ser un número de 1 a %d",
+		"graphql_cursor_invalid":         "after no es un cursor válido",
+		"parameter_invalid":              "%s no es válido",
+		"webhook_not_found":              "webhook no encontrado",
+		"delivery_status_invalid":        "status debe ser pending, succeeded o dead",
+		"since_invalid":                  "since debe ser el token next de una página de cambios anterior",
+		"timestamp_invalid":              "%s debe ser una marca de tiempo RFC 3339 o una fecha AAAA-MM-DD",
+		"statuses_unauthorized":          "se requiere autorización de administrador para listar alquileres que no están publicados",
+		"sort_invalid":                   "sort debe ser id, name, year, length, sleeps, price o newest",
+		"rental_events_unavailable":      "los eventos de alquileres no están disponibles en este momento, inténtelo más tarde",
+		"delivery_out_of_range_metric":   "el punto de entrega está a %.2f km, el alquiler entrega dentro de %g km",
+	},
+	"de": {
+		"id_not_integer":                 "der angegebene Parameter muss eine ganze Zahl sein",
+		"rental_not_found":               "Mietobjekt nicht gefunden",
+		"price_not_positive":             "der Preis muss eine positive Zahl sein",
+		"price_min_not_less_than_max":    "price_min muss kleiner als price_max sein",
+		"price_max_not_more_than_min":    "price_max muss größer als price_min sein",
+		"pagination_not_positive":        "limit und offset müssen positive ganze Zahlen sein",
+		"ids_not_integers":               "ids müssen ganze Zahlen sein",
+		"ids_not_positive":               "ids müssen positive Zahlen größer als 0 sein",
+		"near_missing_separator":         "der Parameter near muss ein Komma als Trennzeichen enthalten",
+		"near_not_pair":                  "near muss ein durch Komma getrenntes Wertepaar sein",
+		"near_not_numeric":               "die Werte von near müssen numerisch sein",
+		"amenities_invalid_list":         "amenities muss eine durch Kommas getrennte Liste von Ausstattungsschlüsseln sein",
+		"amenity_key_invalid_characters": "Ausstattungsschlüssel dürfen nur Kleinbuchstaben und Unterstriche enthalten",
+		"amenity_unknown":                "unbekannte Ausstattung: %s",
+		"currency_invalid_code":          "die Währung muss ein dreibuchstabiger ISO-4217-Code sein",
+		"currency_unsupported":           "nicht unterstützte Währung: %s",
+		"units_invalid":                  "units muss entweder metric oder imperial sein",
+		"radius_without_near":            "radius kann nur zusammen mit near verwendet werden",
+		"number_not_positive":            "%s muss eine positive Zahl sein",
+		"dates_required":                 "die Daten from und to sind erforderlich",
+		"date_invalid_format":            "Daten müssen im Format JJJJ-MM-TT angegeben werden",
+		"date_range_invalid":             "to muss nach from liegen",
+		"calendar_file_missing":          "der Kalender muss im Formularfeld file hochgeladen werden",
+		"calendar_invalid":               "der Kalender ist ungültig: %s",
+		"calendar_too_large":             "der Kalender darf höchstens %d MB groß sein",
+		"user_not_found":                 "Benutzer nicht gefunden",
+		"request_body_invalid":           "der Anfragetext ist kein gültiges JSON",
+		"rental_field_required":          "%s ist erforderlich",
+		"rental_field_invalid":           "%s ist ungültig",
+		"statuses_invalid":               "status muss eine durch Kommas getrennte Liste aus draft, pending_review, published oder unlisted sein",
+		"rental_action_unknown":          "unbekannte Aktion für Mietobjekte: %s",
+		"reason_required":                "für die Ablehnung eines Mietobjekts ist eine Begründung erforderlich",
+		"rental_transition_invalid":      "die Aktion %s ist für ein Mietobjekt im Status %s nicht möglich",
+		"admin_unauthorized":             "Administratorberechtigung erforderlich",
+		"field_required":                 "%s ist erforderlich",
+		"field_invalid":                  "%s ist ungültig",
+		"promo_code_exists":              "der Aktionscode %s existiert bereits",
+		"promo_code_unknown":             "der Aktionscode %s existiert nicht",
+		"promo_code_inactive":            "der Aktionscode %s ist nicht mehr aktiv",
+		"promo_code_not_started":         "der Aktionscode %s ist ab dem %s gültig",
+		"promo_code_expired":             "der Aktionscode %s ist am %s abgelaufen",
+		"promo_code_min_nights":          "der Aktionscode %s erfordert eine Reise von mindestens %d Nächten",
+		"promo_code_rental_type":         "der Aktionscode %s gilt nicht für Mietobjekte vom Typ %s",
+		"promo_code_rental_state":        "der Aktionscode %s gilt nicht für Mietobjekte in %s",
+		"promo_code_exhausted":           "der Aktionscode %s hat sein Einlöselimit erreicht",
+		"promo_code_user_required":       "der Aktionscode %s kann nur mit einer bekannten user_id eingelöst werden",
+		"promo_code_user_exhausted":      "der Aktionscode %s wurde von diesem Benutzer bereits so oft wie erlaubt eingelöst",
+		"date_in_past":                   "from darf nicht in der Vergangenheit liegen",
+		"rental_not_bookable":            "das Mietobjekt ist nicht veröffentlicht und kann nicht gebucht werden",
+		"rental_unavailable":             "das Mietobjekt ist zwischen %s und %s nicht verfügbar",
+		"booking_not_found":              "Buchung nicht gefunden",
+		"booking_not_cancellable":        "eine Buchung im Status %s kann nicht storniert werden",
+		"coordinates_invalid":            "%s muss ein durch Komma getrenntes Paar aus Breiten- und Längengrad sein",
+		"delivery_not_offered":           "das Mietobjekt bietet keine Lieferung an",
+		"delivery_out_of_range":          "der Lieferort ist %.2f Meilen entfernt, das Mietobjekt liefert innerhalb von %g Meilen",
+		"addons_invalid_list":            "addons muss eine durch Kommas getrennte Liste von Zusatzleistungs-IDs sein, jeweils optional gefolgt von :Menge",
+		"addon_unknown":                  "die Zusatzleistung %d wird für dieses Mietobjekt nicht angeboten",
+		"addon_duplicate":                "die Zusatzleistung %s ist mehrfach ausgewählt",
+		"addon_unavailable":              "die Zusatzleistung %s ist nicht verfügbar",
+		"addon_quantity_invalid":         "die Zusatzleistung %s kann in einer Menge von 1 bis %d gewählt werden",
+		"addon_not_found":                "Zusatzleistung nicht gefunden",
+		"booking_notice_required":        "das Mietobjekt erfordert eine Vorlaufzeit von %d Stunden vor dem Check-in",
+		"booking_too_far_ahead":          "das Mietobjekt kann höchstens %d Monate im Voraus gebucht werden",
+		"booking_check_in_day":           "Check-in ist am %s nicht erlaubt, das Mietobjekt erlaubt Check-in am %s",
+		"booking_min_nights":             "das Mietobjekt erfordert einen Mindestaufenthalt von %d Nächten",
+		"booking_max_nights":             "das Mietobjekt erlaubt einen Aufenthalt von höchstens %d Nächten",
+		"blackout_conflicts_booking":     "das Mietobjekt hat zwischen %s und %s eine bestätigte Buchung",
+		"bulk_price_not_positive":        "der angepasste Preis %d ist nicht positiv",
+		"rental_status_change_invalid":   "ein Mietobjekt im Status %s kann nicht auf %s geändert werden",
+		"bulk_too_many":                  "%s darf höchstens %d Einträge haben",
+		"blackout_not_found":             "Sperrzeitraum nicht gefunden",
+		"stats_range_too_long":           "from und to dürfen höchstens %d Tage auseinander liegen",
+		"granularity_invalid":            "granularity muss day, week oder month sein",
+		"window_invalid":                 "window muss eine Anzahl von Tagen von 1d bis %dd sein",
+		"format_invalid":                 "format muss json, csv, ndjson oder geojson sein",
+		"graphql_query_too_deep":         "die Abfragetiefe %d überschreitet das Maximum von %d",
+		"graphql_query_too_complex":      "die Abfragekomplexität %d überschreitet das Maximum von %d",
+		"graphql_first_invalid":          "first muss eine Zahl von 1 bis %d sein",
+		"graphql_cursor_invalid":         "after ist kein gültiger Cursor",
+		"parameter_invalid":              "%s ist ungültig",
+		"webhook_not_found":              "Webhook nicht gefunden",
+		"delivery_status_invalid":        "status muss pending, succeeded oder dead sein",
+		"since_invalid":                  "since muss das next-Token einer vorherigen Seite von Änderungen sein",
+		"timestamp_invalid":              "%s muss ein RFC-3339-Zeitstempel oder ein Datum im Format JJJJ-MM-TT sein",
+		"statuses_unauthorized":          "Administratorberechtigung ist erforderlich, um nicht veröffentlichte Mietobjekte aufzulisten",
+		"sort_invalid":                   "sort muss id, name, year, length, sleeps, price oder newest sein",
+		"rental_events_unavailable":      "Mietobjekt-Ereignisse sind derzeit nicht verfügbar, bitte später erneut versuchen",
+		"delivery_out_of_range_metric":   "der Lieferort ist %.2f km entfernt, das Mietobjekt liefert innerhalb von %g km",
+	},
+	"fr": {
+		"id_not_integer":                 "le paramètre fourni doit être un entier",
+		"rental_not_found":               "location introuvable",
+		"price_not_positive":             "le prix doit être un nombre positif",
+		"price_min_not_less_than_max":    "price_min doit être inférieur à price_max",
+		"price_max_not_more_than_min":    "price_max doit être supérieur à price_min",
+		"pagination_not_positive":        "limit et offset doivent être des entiers positifs",
+		"ids_not_integers":               "ids doivent être des entiers",
+		"ids_not_positive":               "ids doivent être des nombres positifs supérieurs à 0",
+		"near_missing_separator":         "le paramètre near doit contenir une virgule comme séparateur",
+		"near_not_pair":                  "near doit être une paire de valeurs séparées par une virgule",
+		"near_not_numeric":               "les valeurs de near doivent être numériques",
+		"amenities_invalid_list":         "amenities doit être une liste de clés d'équipements séparées par des virgules",
+		"amenity_key_invalid_characters": "les clés d'équipements ne peuvent contenir que des lettres minuscules et des tirets bas",
+		"amenity_unknown":                "équipement inconnu : %s",
+		"currency_invalid_code":          "la devise doit être un code ISO 4217 à trois lettres",
+		"currency_unsupported":           "devise non prise en charge : %s",
+		"units_invalid":                  "units doit être metric ou imperial",
+		"radius_without_near":            "radius ne peut être utilisé qu'avec near",
+		"number_not_positive":            "%s doit être un nombre positif",
+		"dates_required":                 "les dates from et to sont obligatoires",
+		"date_invalid_format":            "les dates doivent être au format AAAA-MM-JJ",
+		"date_range_invalid":             "to doit être postérieur à from",
+		"calendar_file_missing":          "le calendrier doit être envoyé dans le champ de formulaire file",
+		"calendar_invalid":               "le calendrier est invalide : %s",
+		"calendar_too_large":             "le calendrier ne peut pas dépasser %d Mo",
+		"user_not_found":                 "utilisateur introuvable",
+		"request_body_invalid":           "le corps de la requête n'est pas un JSON valide",
+		"rental_field_required":          "%s est obligatoire",
+		"rental_field_invalid":           "%s est invalide",
+		"statuses_invalid":               "status doit être une liste séparée par des virgules de draft, pending_review, published ou unlisted",
+		"rental_action_unknown":          "action de location inconnue : %s",
+		"reason_required":                "un motif est requis pour refuser une location",
+		"rental_transition_invalid":      "impossible d'appliquer l'action %s à une location au statut %s",
+		"admin_unauthorized":             "une autorisation administrateur est requise",
+		"field_required":                 "%s est obligatoire",
+		"field_invalid":                  "%s est invalide",
+		"promo_code_exists":              "le code promo %s existe déjà",
+		"promo_code_unknown":             "le code promo %s n'existe pas",
+		"promo_code_inactive":            "le code promo %s n'est plus actif",
+		"promo_code_not_started":         "le code promo %s est valable à partir du %s",
+		"promo_code_expired":             "le code promo %s a expiré le %s",
+		"promo_code_min_nights":          "le code promo %s exige un séjour d'au moins %d nuits",
+		"promo_code_rental_type":         "le code promo %s ne s'applique pas aux locations de type %s",
+		"promo_code_rental_state":        "le code promo %s ne s'applique pas aux locations situées en %s",
+		"promo_code_exhausted":           "le code promo %s a atteint sa limite d'utilisations",
+		"promo_code_user_required":       "le code promo %s ne peut être appliqué qu'avec un user_id connu",
+		"promo_code_user_exhausted":      "cet utilisateur a déjà utilisé le code promo %s le nombre maximal de fois",
+		"date_in_past":                   "from ne doit pas être dans le passé",
+		"rental_not_bookable":            "la location n'est pas publiée et ne peut pas être réservée",
+		"rental_unavailable":             "la location n'est pas disponible entre le %s et le %s",
+		"booking_not_found":              "réservation introuvable",
+		"booking_not_cancellable":        "une réservation au statut %s ne peut pas être annulée",
+		"coordinates_invalid":            "%s doit être une paire latitude et longitude séparées par une virgule",
+		"delivery_not_offered":           "la location ne propose pas de livraison",
+		"delivery_out_of_range":          "le point de livraison est à %.2f miles, la location livre dans un rayon de %g miles",
+		"addons_invalid_list":            "addons doit être une liste d'identifiants d'options séparés par des virgules, chacun éventuellement suivi de :quantité",
+		"addon_unknown":                  "l'option %d n'est pas proposée par la location",
+		"addon_duplicate":                "l'option %s est sélectionnée plusieurs fois",
+		"addon_unavailable":              "l'option %s n'est pas disponible",
+		"addon_quantity_invalid":         "l'option %s peut être choisie en quantité de 1 à %d",
+		"addon_not_found":                "option introuvable",
+		"booking_notice_required":        "la location exige un préavis de %d heures avant l'arrivée",
+		"booking_too_far_ahead":          "la location peut être réservée au plus %d mois à l'avance",
+		"booking_check_in_day":           "l'arrivée n'est pas autorisée le %s, la location autorise l'arrivée le %s",
+		"booking_min_nights":             "la location exige un séjour minimum de %d nuits",
+		"booking_max_nights":             "la location autorise un séjour maximum de %d nuits",
+		"blackout_conflicts_booking":     "la location a une réservation confirmée entre le %s et le %s",
+		"bulk_price_not_positive":        "le prix ajusté %d n'est pas positif",
+		"rental_status_change_invalid":   "une location au statut %s ne peut pas passer à %s",
+		"bulk_too_many":                  "%s peut contenir au plus %d éléments",
+		"blackout_not_found":             "période de blocage introuvable",
+		"stats_range_too_long":           "from et to peuvent être espacés d'au plus %d jours",
+		"granularity_invalid":            "granularity doit être day, week ou month",
+		"window_invalid":                 "window doit être un nombre de jours de 1d à %dd",
+		"format_invalid":                 "format doit être json, csv, ndjson ou geojson",
+		"graphql_query_too_deep":         "la profondeur de la requête %d dépasse le maximum de %d",
+		"graphql_query_too_complex":      "la complexité de la requête %d dépasse le maximum de %d",
+		"graphql_first_invalid":          "first doit être un nombre de 1 à %d",
+		"graphql_cursor_invalid":         "after n'est pas un curseur valide",
+		"parameter_invalid":              "%s n'est pas valide",
+		"webhook_not_found":              "webhook introuvable",
+		"delivery_status_invalid":        "status doit être pending, succeeded ou dead",
+		"since_invalid":                  "since doit être le jeton next d'une page de changements précédente",
+		"timestamp_invalid":              "%s doit être un horodatage RFC 3339 ou une date AAAA-MM-JJ",
+		"statuses_unauthorized":          "une autorisation administrateur est requise pour lister les locations non publiées",
+		"sort_invalid":                   "sort doit être id, name, year, length, sleeps, price ou newest",
+		"rental_events_unavailable":      "les événements des locations ne sont pas disponibles pour le moment, réessayez plus tard",
+		"delivery_out_of_range_metric":   "le point de livraison est à %.2f km, la location livre dans un rayon de %g km",
+	},
+}
@@ -1,22 +1,48 @@
 package utils
 
 import (
-	"errors"
+	"math"
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
+)
+
+// DateFormat is the format of all dates accepted as parameters.
+const DateFormat = "2006-01-02"
+
+const (
+	GranularityDay   = "day"
+	GranularityWeek  = "week"
+	GranularityMonth = "month"
+
+	// MaximumStatsDays limits the range of the statistics to roughly two years.
+	MaximumStatsDays = 731
+	// MaximumTrendingWindowDays limits the window of the trending rentals.
+	MaximumTrendingWindowDays = 90
 )
 
 func ValidateParameters(params url.Values) (err error) {
 	var (
-		priceMin = params.Get("price_min")
-		priceMax = params.Get("price_max")
-		limit    = params.Get("limit")
-		offset   = params.Get("offset")
-		ids      = params.Get("ids")
-		near     = params.Get("near")
-		minPrice float64
-		maxPrice float64
+		priceMin  = params.Get("price_min")
+		priceMax  = params.Get("price_max")
+		limit     = params.Get("limit")
+		offset    = params.Get("offset")
+		ids       = params.Get("ids")
+		near      = params.Get("near")
+		amenities = params.Get("amenities")
+		currency  = params.Get("currency")
+		units     = params.Get("units")
+		lengthMin = params.Get("length_min")
+		radius    = params.Get("radius")
+		status    = params.Get("status")
+		delivery  = params.Get("delivery_to")
+		from      = params.Get("from")
+		to        = params.Get("to")
+		created   = params.Get("created_after")
+		updated   = params.Get("updated_after")
+		minPrice  float64
+		maxPrice  float64
 	)
 
 	if priceMin != "" {
@@ -61,13 +87,316 @@ func ValidateParameters(params url.Values) (err error) {
 			return
 		}
 	}
+	if amenities != "" {
+		err = validateAmenityKeys(amenities)
+		if err != nil {
+			return
+		}
+	}
+	if currency != "" {
+		err = validateCurrency(currency)
+		if err != nil {
+			return
+		}
+	}
+	if units != "" && units != UnitsMetric && units != UnitsImperial {
+		return NewLocalizedError("units_invalid")
+	}
+	if lengthMin != "" {
+		err = validatePositiveNumber("length_min", lengthMin)
+		if err != nil {
+			return
+		}
+	}
+	if status != "" {
+		err = validateStatuses(status)
+		if err != nil {
+			return
+		}
+	}
+	if delivery != "" {
+		err = validateCoordinates("delivery_to", delivery)
+		if err != nil {
+			return
+		}
+	}
+	if from != "" || to != "" {
+		if from == "" || to == "" {
+			return NewLocalizedError("dates_required")
+		}
+		err = ValidateDateRange(from, to)
+		if err != nil {
+			return
+		}
+	}
+	if radius != "" {
+		if near == "" {
+			return NewLocalizedError("radius_without_near")
+		}
+		err = validatePositiveNumber("radius", radius)
+		if err != nil {
+			return
+		}
+	}
+	if created != "" {
+		if _, err = ParseTimestamp("created_after", created); err != nil {
+			return
+		}
+	}
+	if updated != "" {
+		if _, err = ParseTimestamp("updated_after", updated); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// ParseTimestamp parses the named parameter as an RFC 3339 timestamp, or as a date meaning its midnight in UTC.
+func ParseTimestamp(name string, value string) (timestamp time.Time, err error) {
+	if timestamp, err = time.Parse(time.RFC3339Nano, value); err == nil {
+		return
+	}
+	if timestamp, err = time.Parse(DateFormat, value); err == nil {
+		return
+	}
+	return timestamp, NewLocalizedError("timestamp_invalid", name)
+}
+
+// ValidateQuoteParameters validates the parameters of a price quote - the trip dates, the optional currency and
+// units, the optional user the promo code is redeemed by, the optional delivery point and the optional add-on
+// selection.
+func ValidateQuoteParameters(params url.Values) (err error) {
+	var (
+		from       = params.Get("from")
+		to         = params.Get("to")
+		currency   = params.Get("currency")
+		units      = params.Get("units")
+		userId     = params.Get("user_id")
+		deliveryTo = params.Get("delivery_to")
+		addons     = params.Get("addons")
+	)
+
+	if from == "" || to == "" {
+		return NewLocalizedError("dates_required")
+	}
+	err = ValidateDateRange(from, to)
+	if err != nil {
+		return
+	}
+	if currency != "" {
+		err = validateCurrency(currency)
+		if err != nil {
+			return
+		}
+	}
+	if units != "" && units != UnitsMetric && units != UnitsImperial {
+		return NewLocalizedError("units_invalid")
+	}
+	if userId != "" {
+		err = validatePositiveInteger("user_id", userId)
+		if err != nil {
+			return
+		}
+	}
+	if deliveryTo != "" {
+		err = validateCoordinates("delivery_to", deliveryTo)
+		if err != nil {
+			return
+		}
+	}
+	if addons != "" {
+		err = validateAddonSelections(addons)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// ValidatePriceSuggestionParameters validates the parameters of a price suggestion - the optional radius of the
+// comparables, currency and units.
+func ValidatePriceSuggestionParameters(params url.Values) (err error) {
+	var (
+		radius   = params.Get("radius")
+		currency = params.Get("currency")
+		units    = params.Get("units")
+	)
+
+	if radius != "" {
+		err = validatePositiveNumber("radius", radius)
+		if err != nil {
+			return
+		}
+	}
+	if currency != "" {
+		err = validateCurrency(currency)
+		if err != nil {
+			return
+		}
+	}
+	if units != "" && units != UnitsMetric && units != UnitsImperial {
+		return NewLocalizedError("units_invalid")
+	}
+	return
+}
+
+// ValidateTripEstimateParameters validates the parameters of a trip estimate - the required destination and
+// number of days and the optional currency.
+func ValidateTripEstimateParameters(params url.Values) (err error) {
+	var (
+		to       = params.Get("to")
+		days     = params.Get("days")
+		currency = params.Get("currency")
+	)
+
+	if to == "" {
+		return NewLocalizedError("field_required", "to")
+	}
+	err = validateCoordinates("to", to)
+	if err != nil {
+		return
+	}
+	if days == "" {
+		return NewLocalizedError("field_required", "days")
+	}
+	err = validatePositiveInteger("days", days)
+	if err != nil {
+		return
+	}
+	if currency != "" {
+		err = validateCurrency(currency)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// ValidateStatsParameters validates the parameters of owner statistics - the required from and to dates, at most
+// MaximumStatsDays apart, the optional granularity of the buckets and the optional currency.
+func ValidateStatsParameters(params url.Values) (err error) {
+	var (
+		from        = params.Get("from")
+		to          = params.Get("to")
+		granularity = params.Get("granularity")
+		currency    = params.Get("currency")
+	)
+
+	if from == "" || to == "" {
+		return NewLocalizedError("dates_required")
+	}
+	err = ValidateDateRange(from, to)
+	if err != nil {
+		return
+	}
+	start, _ := time.Parse(DateFormat, from)
+	end, _ := time.Parse(DateFormat, to)
+	if end.Sub(start).Hours()/24 > MaximumStatsDays {
+		return NewLocalizedError("stats_range_too_long", MaximumStatsDays)
+	}
+	if granularity != "" && granularity != GranularityDay && granularity != GranularityWeek && granularity != GranularityMonth {
+		return NewLocalizedError("granularity_invalid")
+	}
+	if currency != "" {
+		err = validateCurrency(currency)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// ValidateTrendingParameters validates the parameters of the trending rentals - the optional near point with its
+// radius, window of days, limit, currency and units.
+func ValidateTrendingParameters(params url.Values) (err error) {
+	var (
+		near     = params.Get("near")
+		radius   = params.Get("radius")
+		window   = params.Get("window")
+		limit    = params.Get("limit")
+		currency = params.Get("currency")
+		units    = params.Get("units")
+	)
+
+	if near != "" {
+		err = validateNear(near)
+		if err != nil {
+			return
+		}
+	}
+	if radius != "" {
+		if near == "" {
+			return NewLocalizedError("radius_without_near")
+		}
+		err = validatePositiveNumber("radius", radius)
+		if err != nil {
+			return
+		}
+	}
+	if window != "" {
+		days, err := strconv.Atoi(strings.TrimSuffix(window, "d"))
+		if !strings.HasSuffix(window, "d") || err != nil || days <= 0 || days > MaximumTrendingWindowDays {
+			return NewLocalizedError("window_invalid", MaximumTrendingWindowDays)
+		}
+	}
+	if limit != "" {
+		err = validateIntegerValues(limit)
+		if err != nil {
+			return
+		}
+	}
+	if currency != "" {
+		err = validateCurrency(currency)
+		if err != nil {
+			return
+		}
+	}
+	if units != "" && units != UnitsMetric && units != UnitsImperial {
+		return NewLocalizedError("units_invalid")
+	}
+	return
+}
+
+// ValidateRentalChangesParameters validates the limit of the rental change feed, its since token is read by the feed.
+func ValidateRentalChangesParameters(params url.Values) (err error) {
+	if limit := params.Get("limit"); limit != "" {
+		err = validateIntegerValues(limit)
+	}
+	return
+}
+
+// ValidateWebhookDeliveriesParameters validates the parameters of the webhook deliveries - status, limit and offset.
+func ValidateWebhookDeliveriesParameters(params url.Values) (err error) {
+	var (
+		status = params.Get("status")
+		limit  = params.Get("limit")
+		offset = params.Get("offset")
+	)
+
+	switch status {
+	case "", "pending", "succeeded", "dead":
+	default:
+		return NewLocalizedError("delivery_status_invalid")
+	}
+	if limit != "" {
+		err = validateIntegerValues(limit)
+		if err != nil {
+			return
+		}
+	}
+	if offset != "" {
+		err = validateIntegerValues(offset)
+		if err != nil {
+			return
+		}
+	}
 	return
 }
 
 func validatePrice(price string) (priceAsNumber float64, err error) {
 	priceAsNumber, err = strconv.ParseFloat(price, 64)
 	if err != nil || priceAsNumber < 0 {
-		err = errors.New("price must be a positive number")
+		err = NewLocalizedError("price_not_positive")
 		return
 	}
 	return
@@ -75,9 +404,9 @@ func validatePrice(price string) (priceAsNumber float64, err error) {
 
 func validateMinAndMaxPrice(min float64, max float64) error {
 	if min >= max {
-		return errors.New("price_min must be less than price_max")
+		return NewLocalizedError("price_min_not_less_than_max")
 	} else if max <= min {
-		return errors.New("price_max must be more than price_min")
+		return NewLocalizedError("price_max_not_more_than_min")
 	}
 	return nil
 }
@@ -85,7 +414,7 @@ func validateMinAndMaxPrice(min float64, max float64) error {
 func validateIntegerValues(limit string) error {
 	num, err := strconv.Atoi(limit)
 	if err != nil || num <= 0 {
-		return errors.New("limit and offset must be a positive integer")
+		return NewLocalizedError("pagination_not_positive")
 	}
 	return nil
 }
@@ -95,10 +424,10 @@ func validateArray(ids string) error {
 	for _, value := range stringArr {
 		num, err := strconv.Atoi(value)
 		if err != nil {
-			return errors.New("ids should be int values")
+			return NewLocalizedError("ids_not_integers")
 		}
 		if num <= 0 {
-			return errors.New("ids should be positive numbers greater than 0")
+			return NewLocalizedError("ids_not_positive")
 		}
 	}
 	return nil
@@ -106,20 +435,124 @@ func validateArray(ids string) error {
 
 func validateNear(nearContent string) error {
 	if !strings.Contains(nearContent, ",") {
-		return errors.New("there should be comma separator for the near parameter")
+		return NewLocalizedError("near_missing_separator")
 	}
 
 	nearValues := strings.Split(nearContent, ",")
 	if len(nearValues) != 2 {
-		return errors.New("near values should be a comma separated pair")
+		return NewLocalizedError("near_not_pair")
 	}
 
 	for _, value := range nearValues {
 		_, err := strconv.ParseFloat(value, 64)
 		if err != nil {
-			return errors.New("near values should be numeric")
+			return NewLocalizedError("near_not_numeric")
+		}
+	}
+
+	return nil
+}
+
+func validateAmenityKeys(amenities string) error {
+	for _, key := range strings.Split(amenities, ",") {
+		if key == "" {
+			return NewLocalizedError("amenities_invalid_list")
+		}
+		for _, character := range key {
+			if (character < 'a' || character > 'z') && character != '_' {
+				return NewLocalizedError("amenity_key_invalid_characters")
+			}
+		}
+	}
+	return nil
+}
+
+func validateCurrency(currency string) error {
+	if len(currency) != 3 {
+		return NewLocalizedError("currency_invalid_code")
+	}
+	for _, character := range strings.ToUpper(currency) {
+		if character < 'A' || character > 'Z' {
+			return NewLocalizedError("currency_invalid_code")
+		}
+	}
+	return nil
+}
+
+// ValidateDateRange validates that both dates are in DateFormat and that the range ends after it starts.
+func ValidateDateRange(from string, to string) error {
+	start, err := time.Parse(DateFormat, from)
+	if err != nil {
+		return NewLocalizedError("date_invalid_format")
+	}
+	end, err := time.Parse(DateFormat, to)
+	if err != nil {
+		return NewLocalizedError("date_invalid_format")
+	}
+	if !end.After(start) {
+		return NewLocalizedError("date_range_invalid")
+	}
+	return nil
+}
+
+func validatePositiveNumber(name string, value string) error {
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil || number <= 0 || math.IsNaN(number) || math.IsInf(number, 0) {
+		return NewLocalizedError("number_not_positive", name)
+	}
+	return nil
+}
+
+func validatePositiveInteger(name string, value string) error {
+	number, err := strconv.Atoi(value)
+	if err != nil || number <= 0 {
+		return NewLocalizedError("number_not_positive", name)
+	}
+	return nil
+}
+
+func validateCoordinates(name string, value string) error {
+	coordinates := strings.Split(value, ",")
+	if len(coordinates) != 2 {
+		return NewLocalizedError("coordinates_invalid", name)
+	}
+
+	lat, latErr := strconv.ParseFloat(coordinates[0], 64)
+	lng, lngErr := strconv.ParseFloat(coordinates[1], 64)
+	if latErr != nil || lngErr != nil || lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+		return NewLocalizedError("coordinates_invalid", name)
+	}
+	return nil
+}
+
+func validateAddonSelections(addons string) error {
+	for _, selection := range strings.Split(addons, ",") {
+		id, quantity, hasQuantity := strings.Cut(selection, ":")
+		if validatePositiveInteger("addons", id) != nil || hasQuantity && validatePositiveInteger("addons", quantity) != nil {
+			return NewLocalizedError("addons_invalid_list")
+		}
+	}
+	return nil
+}
+
+// ValidatePublicStatuses allows only the published status, the other statuses of the rentals are listed to the
+// admins only.
+func ValidatePublicStatuses(statuses string) error {
+	for _, status := range strings.Split(statuses, ",") {
+		if status != "" && status != "published" {
+			return NewLocalizedError("statuses_unauthorized")
 		}
 	}
+	return nil
+}
 
+func validateStatuses(statuses string) error {
+	for _, status := range strings.Split(statuses, ",") {
+		switch status {
+		case "draft", "pending_review", "published", "unlisted":
+		default:
+			return NewLocalizedError("statuses_invalid")
+		}
+	}
 	return nil
 }